@@ -1,13 +1,28 @@
 package main
 
 import (
-	"log"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 
 	"finbro-backend-go/internal/api"
 	"finbro-backend-go/internal/api/handlers"
+	"finbro-backend-go/internal/app"
 	"finbro-backend-go/internal/config"
 	"finbro-backend-go/internal/db"
-	"finbro-backend-go/internal/services"
+	"finbro-backend-go/internal/health"
+	"finbro-backend-go/internal/jobs"
+	"finbro-backend-go/internal/logging"
+	"finbro-backend-go/internal/metrics"
+	"finbro-backend-go/internal/publicid"
+	"finbro-backend-go/internal/tracing"
 
 	"github.com/gin-gonic/gin"
 )
@@ -16,45 +31,126 @@ func main() {
 
 	cfg, err := config.Load()
 	if err != nil {
-		log.Fatalf("Failed to load configuration: %v", err)
+		fatal("Failed to load configuration", err)
 	}
 
+	logger, err := logging.Setup(cfg.Log.Level, cfg.Log.Format)
+	if err != nil {
+		fatal("Failed to configure logging", err)
+	}
+	for _, warning := range cfg.Warnings {
+		logger.Warn(warning)
+	}
+	logger.Info("Config loaded", "file", cfg.File, "environment", cfg.Environment)
+
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Config{
+		ServiceName: cfg.Tracing.ServiceName,
+		Environment: cfg.Environment,
+		Exporter:    cfg.Tracing.Exporter,
+		Endpoint:    cfg.Tracing.Endpoint,
+		Insecure:    cfg.Tracing.Insecure,
+		SampleRatio: cfg.Tracing.SampleRatio,
+	})
+	if err != nil {
+		fatal("Failed to configure tracing", err)
+	}
+	defer func() {
+		flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(flushCtx); err != nil {
+			logger.Error("Failed to flush traces", "error", err)
+		}
+	}()
+
 	database, err := db.Initialize(cfg.Database.URL)
 	if err != nil {
-		log.Fatalf("Failed to connect to database: %v", err)
+		fatal("Failed to connect to database", err)
 	}
 	defer func() {
 		if closeErr := database.Close(); closeErr != nil {
-			log.Printf("Error closing database: %v", closeErr)
+			logger.Error("Error closing database", "error", closeErr)
 		}
 	}()
 
 	if err := db.Migrate(database); err != nil {
-		log.Fatalf("Failed to run migrations: %v", err)
+		fatal("Failed to run migrations", err)
 	}
 
 	if cfg.Environment == "production" {
 		gin.SetMode(gin.ReleaseMode)
 	} else {
 		gin.SetMode(gin.DebugMode)
+	}
+	gin.DebugPrintFunc = func(format string, values ...interface{}) {
+		logger.Debug(strings.TrimSpace(fmt.Sprintf(format, values...)))
+	}
+
+	if sqlDB, err := database.DB.DB(); err == nil {
+		metrics.RegisterDB(sqlDB)
+	}
 
+	svc, err := app.NewServices(cfg, database)
+	if err != nil {
+		fatal("Failed to initialize services", err)
 	}
 
-	userService := services.NewUserService(database)
+	workerCtx, stopWorker := context.WithCancel(context.Background())
+	defer stopWorker()
 
-	authHandler := handlers.NewAuthHandler(database, cfg, userService)
+	var worker *jobs.Worker
+	if cfg.Jobs.Embedded {
+		if worker, err = svc.NewWorker(cfg); err != nil {
+			fatal("Failed to configure job worker", err)
+		}
+		worker.Start(workerCtx)
+	}
+
+	readiness := health.NewState()
+	healthChecks := health.NewRegistry(cfg.Health.CheckTimeout, cfg.Health.CacheTTL)
+	healthChecks.Register("database", health.DatabaseCheck(database))
+	healthChecks.Register("migrations", health.MigrationCheck(database))
+	healthChecks.Register("job_queue", health.JobQueueCheck(svc.Queue, cfg.Health.MaxJobLag), health.NonCritical())
+	if cfg.Redis.URL != "" {
+		healthChecks.Register("redis", health.RedisCheck(cfg.Redis.URL))
+	}
+
+	healthHandler := handlers.NewHealthHandler(healthChecks, readiness)
+	authHandler := handlers.NewAuthHandler(database, cfg, svc.User)
 
 	userHandler := handlers.NewUserHandler(database)
-	accountHandler := handlers.NewAccountHandler(database)
-	transactionHandler := handlers.NewTransactionHandler(database)
+	accountHandler := handlers.NewAccountHandler(database, svc.OutboundWebhooks)
+	transactionHandler := handlers.NewTransactionHandler(database, svc.Transaction, svc.Category, svc.Rule, svc.Categorizer, svc.Budget, svc.OutboundWebhooks, svc.Attachment)
+	attachmentHandler := handlers.NewAttachmentHandler(database, svc.Attachment)
+	categoryHandler := handlers.NewCategoryHandler(database, svc.Category)
+	tagHandler := handlers.NewTagHandler(database, svc.Tag, svc.OutboundWebhooks)
+	ruleHandler := handlers.NewRuleHandler(database, svc.Rule)
+	assistantHandler := handlers.NewAssistantHandler(database, svc.Assistant)
+	bankLinkHandler := handlers.NewBankLinkHandler(database, svc.BankLink)
+	webhookHandler := handlers.NewWebhookHandler(database, svc.InboundWebhooks)
+	webhookSubscriptionHandler := handlers.NewWebhookSubscriptionHandler(database, svc.OutboundWebhooks)
+
+	if err := api.RegisterValidators(); err != nil {
+		fatal("Failed to register request validators", err)
+	}
+	publicid.SetAcceptLegacy(cfg.PublicIDs.AcceptLegacy)
 
 	router := api.SetupRouter(
 		database,
 		cfg,
+		svc.Idempotency,
+		healthHandler,
 		authHandler,
 		userHandler,
 		accountHandler,
 		transactionHandler,
+		categoryHandler,
+		tagHandler,
+		attachmentHandler,
+		ruleHandler,
+		assistantHandler,
+		bankLinkHandler,
+		webhookHandler,
+		webhookSubscriptionHandler,
 	)
 
 	address := cfg.Server.Address
@@ -62,8 +158,88 @@ func main() {
 		address = ":8081"
 	}
 
-	log.Printf("Server starting on %s", address)
-	if err := router.Run(address); err != nil {
-		log.Fatalf("Failed to start server: %v", err)
+	server := &http.Server{
+		Addr:              address,
+		Handler:           router,
+		ReadTimeout:       cfg.Server.ReadTimeout,
+		ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout,
+		WriteTimeout:      cfg.Server.WriteTimeout,
+		IdleTimeout:       cfg.Server.IdleTimeout,
+		MaxHeaderBytes:    cfg.Server.MaxHeaderBytes,
+		ErrorLog:          slog.NewLogLogger(logger.Handler(), slog.LevelWarn),
 	}
+
+	serverErr := make(chan error, 2)
+	go func() {
+		logger.Info("Server starting", "address", address)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serverErr <- err
+		}
+	}()
+
+	var metricsServer *http.Server
+	if cfg.Metrics.Address != "" {
+		metricsServer = &http.Server{
+			Addr:              cfg.Metrics.Address,
+			Handler:           api.SetupMetricsRouter(cfg),
+			ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout,
+		}
+		go func() {
+			logger.Info("Metrics listening", "address", cfg.Metrics.Address)
+			if err := metricsServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				serverErr <- err
+			}
+		}()
+	}
+
+	signalCtx, stopSignals := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stopSignals()
+
+	select {
+	case err := <-serverErr:
+		logger.Error("Server failed", "error", err)
+	case <-signalCtx.Done():
+		logger.Info("Shutdown signal received")
+	}
+	stopSignals()
+
+	// Fail readiness first, then give load balancers time to notice before
+	// the listener closes.
+	readiness.SetDraining()
+	if cfg.Server.ShutdownDelay > 0 {
+		time.Sleep(cfg.Server.ShutdownDelay)
+	}
+
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer cancelShutdown()
+
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		logger.Error("HTTP server did not drain cleanly", "error", err)
+	}
+	if metricsServer != nil {
+		if err := metricsServer.Shutdown(shutdownCtx); err != nil {
+			logger.Error("Metrics server did not shut down cleanly", "error", err)
+		}
+	}
+
+	stopWorker()
+	if worker != nil {
+		drained := make(chan struct{})
+		go func() {
+			worker.Wait()
+			close(drained)
+		}()
+		select {
+		case <-drained:
+		case <-shutdownCtx.Done():
+			logger.Warn("Timed out waiting for background jobs to drain")
+		}
+	}
+
+	logger.Info("Server stopped")
+}
+
+func fatal(message string, err error) {
+	slog.Error(message, "error", err)
+	os.Exit(1)
 }
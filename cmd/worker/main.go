@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"finbro-backend-go/internal/app"
+	"finbro-backend-go/internal/config"
+	"finbro-backend-go/internal/db"
+	"finbro-backend-go/internal/logging"
+	"finbro-backend-go/internal/tracing"
+)
+
+// The worker runs background jobs without serving HTTP. Schema migrations
+// are left to the API server.
+func main() {
+	cfg, err := config.Load()
+	if err != nil {
+		fatal("Failed to load configuration", err)
+	}
+
+	logger, err := logging.Setup(cfg.Log.Level, cfg.Log.Format)
+	if err != nil {
+		fatal("Failed to configure logging", err)
+	}
+	for _, warning := range cfg.Warnings {
+		logger.Warn(warning)
+	}
+
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Config{
+		ServiceName: cfg.Tracing.ServiceName + "-worker",
+		Environment: cfg.Environment,
+		Exporter:    cfg.Tracing.Exporter,
+		Endpoint:    cfg.Tracing.Endpoint,
+		Insecure:    cfg.Tracing.Insecure,
+		SampleRatio: cfg.Tracing.SampleRatio,
+	})
+	if err != nil {
+		fatal("Failed to configure tracing", err)
+	}
+	defer func() {
+		flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(flushCtx); err != nil {
+			logger.Error("Failed to flush traces", "error", err)
+		}
+	}()
+
+	database, err := db.Initialize(cfg.Database.URL)
+	if err != nil {
+		fatal("Failed to connect to database", err)
+	}
+	defer func() {
+		if closeErr := database.Close(); closeErr != nil {
+			logger.Error("Error closing database", "error", closeErr)
+		}
+	}()
+
+	svc, err := app.NewServices(cfg, database)
+	if err != nil {
+		fatal("Failed to initialize services", err)
+	}
+
+	worker, err := svc.NewWorker(cfg)
+	if err != nil {
+		fatal("Failed to configure job worker", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	logger.Info("Worker started", "concurrency", cfg.Jobs.Concurrency, "queues", cfg.Jobs.Queues)
+	worker.Start(ctx)
+
+	<-ctx.Done()
+	logger.Info("Shutting down worker, draining running jobs")
+	worker.Wait()
+	logger.Info("Worker stopped")
+}
+
+func fatal(message string, err error) {
+	slog.Error(message, "error", err)
+	os.Exit(1)
+}
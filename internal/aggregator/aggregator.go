@@ -0,0 +1,86 @@
+// internal/aggregator/aggregator.go
+package aggregator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"finbro-backend-go/internal/config"
+)
+
+var (
+	ErrItemLoginRequired = errors.New("item login required")
+	ErrInvalidToken      = errors.New("invalid or expired token")
+)
+
+// Aggregator is a bank data provider modeled on Plaid: a client-side link
+// flow yields a public token, which is exchanged for a long-lived access
+// token used to read accounts and sync transactions incrementally.
+type Aggregator interface {
+	Name() string
+	CreateLinkToken(ctx context.Context, clientUserID string) (*LinkToken, error)
+	ExchangePublicToken(ctx context.Context, publicToken string) (*Item, error)
+	GetAccounts(ctx context.Context, accessToken string) ([]Account, error)
+	SyncTransactions(ctx context.Context, accessToken, cursor string) (*SyncPage, error)
+	RemoveItem(ctx context.Context, accessToken string) error
+}
+
+type LinkToken struct {
+	Token      string    `json:"link_token"`
+	Expiration time.Time `json:"expiration"`
+}
+
+type Item struct {
+	ItemID          string
+	AccessToken     string
+	InstitutionName string
+}
+
+type Account struct {
+	ExternalID       string
+	Name             string
+	Mask             string
+	Type             string
+	Subtype          string
+	CurrentBalance   float64
+	AvailableBalance *float64
+	Currency         string
+}
+
+// Transaction uses the provider sign convention: positive amounts are money
+// leaving the account.
+type Transaction struct {
+	ExternalID        string
+	AccountExternalID string
+	Amount            float64
+	Name              string
+	MerchantName      string
+	Date              time.Time
+	Pending           bool
+	Currency          string
+}
+
+type SyncPage struct {
+	Added      []Transaction
+	Modified   []Transaction
+	Removed    []string
+	NextCursor string
+	HasMore    bool
+}
+
+// New builds the aggregator selected in config, or nil when bank linking is
+// not configured.
+func New(cfg *config.Config) (Aggregator, error) {
+	switch cfg.BankLink.Provider {
+	case "fake":
+		return NewFake(), nil
+	case "plaid":
+		return NewPlaidClient(cfg.Plaid.BaseURL, cfg.Plaid.Environment, cfg.Plaid.ClientID, cfg.Plaid.Secret), nil
+	case "":
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("unknown bank link provider %q", cfg.BankLink.Provider)
+	}
+}
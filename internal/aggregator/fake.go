@@ -0,0 +1,228 @@
+// internal/aggregator/fake.go
+package aggregator
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const fakeSyncPageSize = 100
+
+// Fake is an in-memory Aggregator for local development and tests. Every
+// exchanged public token yields an item with a checking and a credit card
+// account and a few seeded transactions. Changes made through AddTransaction,
+// ModifyTransaction and RemoveTransaction are replayed by SyncTransactions;
+// AddAccount opens another account.
+type Fake struct {
+	mu    sync.Mutex
+	items map[string]*fakeItem // by access token
+}
+
+type fakeItem struct {
+	itemID   string
+	accounts []Account
+	current  map[string]Transaction
+	events   []fakeEvent
+}
+
+type fakeEvent struct {
+	kind        string // added, modified, removed
+	transaction Transaction
+}
+
+func NewFake() *Fake {
+	return &Fake{items: make(map[string]*fakeItem)}
+}
+
+func (f *Fake) Name() string {
+	return "fake"
+}
+
+func (f *Fake) CreateLinkToken(ctx context.Context, clientUserID string) (*LinkToken, error) {
+	return &LinkToken{
+		Token:      "link-fake-" + randomID(),
+		Expiration: time.Now().Add(4 * time.Hour),
+	}, nil
+}
+
+// ExchangePublicToken accepts any token starting with "public-fake-".
+func (f *Fake) ExchangePublicToken(ctx context.Context, publicToken string) (*Item, error) {
+	if !strings.HasPrefix(publicToken, "public-fake-") {
+		return nil, ErrInvalidToken
+	}
+
+	itemID := "item-fake-" + randomID()
+	accessToken := "access-fake-" + randomID()
+	checking := "acc-" + randomID()
+	credit := "acc-" + randomID()
+
+	item := &fakeItem{
+		itemID: itemID,
+		accounts: []Account{
+			{ExternalID: checking, Name: "Fake Checking", Mask: "0000", Type: "depository", Subtype: "checking", CurrentBalance: 1250.75, Currency: "USD"},
+			{ExternalID: credit, Name: "Fake Credit Card", Mask: "3333", Type: "credit", Subtype: "credit card", CurrentBalance: 410.20, Currency: "USD"},
+		},
+		current: make(map[string]Transaction),
+	}
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	seed := []Transaction{
+		{AccountExternalID: checking, Amount: -2500, Name: "ACME CORP PAYROLL", Date: today.AddDate(0, 0, -14)},
+		{AccountExternalID: checking, Amount: 1200, Name: "RENT PAYMENT", Date: today.AddDate(0, 0, -10)},
+		{AccountExternalID: credit, Amount: 54.23, Name: "AMZN Mktp US*2K3LT", MerchantName: "Amazon", Date: today.AddDate(0, 0, -3)},
+		{AccountExternalID: credit, Amount: 4.75, Name: "SQ *BLUE BOTTLE COFFEE", MerchantName: "Blue Bottle Coffee", Date: today.AddDate(0, 0, -1)},
+	}
+	for _, t := range seed {
+		t.ExternalID = "txn-" + randomID()
+		t.Currency = "USD"
+		item.current[t.ExternalID] = t
+		item.events = append(item.events, fakeEvent{kind: "added", transaction: t})
+	}
+
+	f.mu.Lock()
+	f.items[accessToken] = item
+	f.mu.Unlock()
+
+	return &Item{ItemID: itemID, AccessToken: accessToken, InstitutionName: "Fake Bank"}, nil
+}
+
+func (f *Fake) GetAccounts(ctx context.Context, accessToken string) ([]Account, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	item, ok := f.items[accessToken]
+	if !ok {
+		return nil, ErrInvalidToken
+	}
+	return append([]Account(nil), item.accounts...), nil
+}
+
+// SyncTransactions uses the index into the item's event log as cursor.
+func (f *Fake) SyncTransactions(ctx context.Context, accessToken, cursor string) (*SyncPage, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	item, ok := f.items[accessToken]
+	if !ok {
+		return nil, ErrInvalidToken
+	}
+
+	start := 0
+	if cursor != "" {
+		n, err := strconv.Atoi(cursor)
+		if err != nil || n < 0 || n > len(item.events) {
+			return nil, fmt.Errorf("invalid cursor %q", cursor)
+		}
+		start = n
+	}
+	end := start + fakeSyncPageSize
+	if end > len(item.events) {
+		end = len(item.events)
+	}
+
+	page := &SyncPage{NextCursor: strconv.Itoa(end), HasMore: end < len(item.events)}
+	for _, event := range item.events[start:end] {
+		switch event.kind {
+		case "added":
+			page.Added = append(page.Added, event.transaction)
+		case "modified":
+			page.Modified = append(page.Modified, event.transaction)
+		case "removed":
+			page.Removed = append(page.Removed, event.transaction.ExternalID)
+		}
+	}
+	return page, nil
+}
+
+func (f *Fake) RemoveItem(ctx context.Context, accessToken string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, ok := f.items[accessToken]; !ok {
+		return ErrInvalidToken
+	}
+	delete(f.items, accessToken)
+	return nil
+}
+
+// AddAccount opens a new account on the item and returns its external ID.
+func (f *Fake) AddAccount(accessToken string, a Account) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	item, ok := f.items[accessToken]
+	if !ok {
+		return "", ErrInvalidToken
+	}
+	if a.ExternalID == "" {
+		a.ExternalID = "acc-" + randomID()
+	}
+	item.accounts = append(item.accounts, a)
+	return a.ExternalID, nil
+}
+
+// AddTransaction records a new transaction on the item's first account and
+// returns its external ID.
+func (f *Fake) AddTransaction(accessToken string, t Transaction) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	item, ok := f.items[accessToken]
+	if !ok {
+		return "", ErrInvalidToken
+	}
+	if t.ExternalID == "" {
+		t.ExternalID = "txn-" + randomID()
+	}
+	if t.AccountExternalID == "" {
+		t.AccountExternalID = item.accounts[0].ExternalID
+	}
+	item.current[t.ExternalID] = t
+	item.events = append(item.events, fakeEvent{kind: "added", transaction: t})
+	return t.ExternalID, nil
+}
+
+func (f *Fake) ModifyTransaction(accessToken string, t Transaction) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	item, ok := f.items[accessToken]
+	if !ok {
+		return ErrInvalidToken
+	}
+	if _, ok := item.current[t.ExternalID]; !ok {
+		return fmt.Errorf("unknown transaction %q", t.ExternalID)
+	}
+	item.current[t.ExternalID] = t
+	item.events = append(item.events, fakeEvent{kind: "modified", transaction: t})
+	return nil
+}
+
+func (f *Fake) RemoveTransaction(accessToken, externalID string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	item, ok := f.items[accessToken]
+	if !ok {
+		return ErrInvalidToken
+	}
+	t, ok := item.current[externalID]
+	if !ok {
+		return fmt.Errorf("unknown transaction %q", externalID)
+	}
+	delete(item.current, externalID)
+	item.events = append(item.events, fakeEvent{kind: "removed", transaction: t})
+	return nil
+}
+
+func randomID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
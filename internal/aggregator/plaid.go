@@ -0,0 +1,292 @@
+// internal/aggregator/plaid.go
+package aggregator
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+
+	"finbro-backend-go/internal/tracing"
+)
+
+const plaidSyncPageSize = 250
+
+var plaidEnvironments = map[string]string{
+	"sandbox":     "https://sandbox.plaid.com",
+	"development": "https://development.plaid.com",
+	"production":  "https://production.plaid.com",
+}
+
+// PlaidClient implements Aggregator against the Plaid HTTP API. BaseURL can
+// point at a local fake server.
+type PlaidClient struct {
+	baseURL    string
+	clientID   string
+	secret     string
+	httpClient *http.Client
+}
+
+func NewPlaidClient(baseURL, environment, clientID, secret string) *PlaidClient {
+	if baseURL == "" {
+		baseURL = plaidEnvironments[environment]
+	}
+	if baseURL == "" {
+		baseURL = plaidEnvironments["sandbox"]
+	}
+	return &PlaidClient{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		clientID:   clientID,
+		secret:     secret,
+		httpClient: &http.Client{Timeout: 30 * time.Second, Transport: tracing.Transport(nil)},
+	}
+}
+
+// PlaidError is the error body Plaid returns with non-2xx responses.
+type PlaidError struct {
+	StatusCode   int    `json:"-"`
+	ErrorType    string `json:"error_type"`
+	ErrorCode    string `json:"error_code"`
+	ErrorMessage string `json:"error_message"`
+	RequestID    string `json:"request_id"`
+}
+
+func (e *PlaidError) Error() string {
+	return fmt.Sprintf("plaid %s/%s: %s", e.ErrorType, e.ErrorCode, e.ErrorMessage)
+}
+
+func (e *PlaidError) Unwrap() error {
+	switch e.ErrorCode {
+	case "ITEM_LOGIN_REQUIRED":
+		return ErrItemLoginRequired
+	case "INVALID_PUBLIC_TOKEN", "INVALID_ACCESS_TOKEN":
+		return ErrInvalidToken
+	}
+	return nil
+}
+
+func (p *PlaidClient) Name() string {
+	return "plaid"
+}
+
+func (p *PlaidClient) post(ctx context.Context, path string, payload map[string]interface{}, out interface{}) error {
+	payload["client_id"] = p.clientID
+	payload["secret"] = p.secret
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.baseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("plaid request %s failed: %w", path, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 16<<20))
+	if err != nil {
+		return fmt.Errorf("failed to read plaid response: %w", err)
+	}
+
+	if resp.StatusCode >= 300 {
+		plaidErr := &PlaidError{StatusCode: resp.StatusCode}
+		if err := json.Unmarshal(data, plaidErr); err != nil || plaidErr.ErrorCode == "" {
+			return fmt.Errorf("plaid request %s failed with status %d", path, resp.StatusCode)
+		}
+		return plaidErr
+	}
+
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("failed to parse plaid response: %w", err)
+	}
+	return nil
+}
+
+func (p *PlaidClient) CreateLinkToken(ctx context.Context, clientUserID string) (*LinkToken, error) {
+	var resp struct {
+		LinkToken  string    `json:"link_token"`
+		Expiration time.Time `json:"expiration"`
+	}
+	err := p.post(ctx, "/link/token/create", map[string]interface{}{
+		"client_name":   "Finbro",
+		"language":      "en",
+		"country_codes": []string{"US"},
+		"products":      []string{"transactions"},
+		"user":          map[string]string{"client_user_id": clientUserID},
+	}, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &LinkToken{Token: resp.LinkToken, Expiration: resp.Expiration}, nil
+}
+
+func (p *PlaidClient) ExchangePublicToken(ctx context.Context, publicToken string) (*Item, error) {
+	var resp struct {
+		AccessToken string `json:"access_token"`
+		ItemID      string `json:"item_id"`
+	}
+	err := p.post(ctx, "/item/public_token/exchange", map[string]interface{}{
+		"public_token": publicToken,
+	}, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &Item{ItemID: resp.ItemID, AccessToken: resp.AccessToken}, nil
+}
+
+type plaidAccount struct {
+	AccountID string `json:"account_id"`
+	Name      string `json:"name"`
+	Mask      string `json:"mask"`
+	Type      string `json:"type"`
+	Subtype   string `json:"subtype"`
+	Balances  struct {
+		Current         *float64 `json:"current"`
+		Available       *float64 `json:"available"`
+		IsoCurrencyCode string   `json:"iso_currency_code"`
+	} `json:"balances"`
+}
+
+func (p *PlaidClient) GetAccounts(ctx context.Context, accessToken string) ([]Account, error) {
+	var resp struct {
+		Accounts []plaidAccount `json:"accounts"`
+	}
+	err := p.post(ctx, "/accounts/get", map[string]interface{}{
+		"access_token": accessToken,
+	}, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	accounts := make([]Account, 0, len(resp.Accounts))
+	for _, a := range resp.Accounts {
+		account := Account{
+			ExternalID:       a.AccountID,
+			Name:             a.Name,
+			Mask:             a.Mask,
+			Type:             a.Type,
+			Subtype:          a.Subtype,
+			AvailableBalance: a.Balances.Available,
+			Currency:         a.Balances.IsoCurrencyCode,
+		}
+		if a.Balances.Current != nil {
+			account.CurrentBalance = *a.Balances.Current
+		}
+		accounts = append(accounts, account)
+	}
+	return accounts, nil
+}
+
+type plaidTransaction struct {
+	TransactionID   string  `json:"transaction_id"`
+	AccountID       string  `json:"account_id"`
+	Amount          float64 `json:"amount"`
+	Name            string  `json:"name"`
+	MerchantName    string  `json:"merchant_name"`
+	Date            string  `json:"date"`
+	Pending         bool    `json:"pending"`
+	IsoCurrencyCode string  `json:"iso_currency_code"`
+}
+
+func (t plaidTransaction) toTransaction() Transaction {
+	date, _ := time.Parse("2006-01-02", t.Date)
+	return Transaction{
+		ExternalID:        t.TransactionID,
+		AccountExternalID: t.AccountID,
+		Amount:            t.Amount,
+		Name:              t.Name,
+		MerchantName:      t.MerchantName,
+		Date:              date,
+		Pending:           t.Pending,
+		Currency:          t.IsoCurrencyCode,
+	}
+}
+
+func (p *PlaidClient) SyncTransactions(ctx context.Context, accessToken, cursor string) (*SyncPage, error) {
+	var resp struct {
+		Added    []plaidTransaction `json:"added"`
+		Modified []plaidTransaction `json:"modified"`
+		Removed  []struct {
+			TransactionID string `json:"transaction_id"`
+		} `json:"removed"`
+		NextCursor string `json:"next_cursor"`
+		HasMore    bool   `json:"has_more"`
+	}
+	payload := map[string]interface{}{
+		"access_token": accessToken,
+		"count":        plaidSyncPageSize,
+	}
+	if cursor != "" {
+		payload["cursor"] = cursor
+	}
+	if err := p.post(ctx, "/transactions/sync", payload, &resp); err != nil {
+		return nil, err
+	}
+
+	page := &SyncPage{NextCursor: resp.NextCursor, HasMore: resp.HasMore}
+	for _, t := range resp.Added {
+		page.Added = append(page.Added, t.toTransaction())
+	}
+	for _, t := range resp.Modified {
+		page.Modified = append(page.Modified, t.toTransaction())
+	}
+	for _, r := range resp.Removed {
+		page.Removed = append(page.Removed, r.TransactionID)
+	}
+	return page, nil
+}
+
+func (p *PlaidClient) RemoveItem(ctx context.Context, accessToken string) error {
+	var resp struct{}
+	return p.post(ctx, "/item/remove", map[string]interface{}{
+		"access_token": accessToken,
+	}, &resp)
+}
+
+// GetWebhookVerificationKey fetches the JWK Plaid signs webhooks with.
+func (p *PlaidClient) GetWebhookVerificationKey(ctx context.Context, keyID string) (*ecdsa.PublicKey, error) {
+	var resp struct {
+		Key struct {
+			Kty string `json:"kty"`
+			Crv string `json:"crv"`
+			X   string `json:"x"`
+			Y   string `json:"y"`
+		} `json:"key"`
+	}
+	err := p.post(ctx, "/webhook_verification_key/get", map[string]interface{}{
+		"key_id": keyID,
+	}, &resp)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Key.Kty != "EC" || resp.Key.Crv != "P-256" {
+		return nil, fmt.Errorf("unsupported webhook key type %s/%s", resp.Key.Kty, resp.Key.Crv)
+	}
+
+	x, errX := base64.RawURLEncoding.DecodeString(resp.Key.X)
+	y, errY := base64.RawURLEncoding.DecodeString(resp.Key.Y)
+	if errX != nil || errY != nil {
+		return nil, fmt.Errorf("malformed webhook key")
+	}
+
+	return &ecdsa.PublicKey{
+		Curve: elliptic.P256(),
+		X:     new(big.Int).SetBytes(x),
+		Y:     new(big.Int).SetBytes(y),
+	}, nil
+}
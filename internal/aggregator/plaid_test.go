@@ -0,0 +1,222 @@
+// internal/aggregator/plaid_test.go
+package aggregator
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// fakePlaid serves the given handlers and checks that every request carries
+// the client credentials.
+func fakePlaid(t *testing.T, handlers map[string]func(req map[string]interface{}) (int, interface{})) *PlaidClient {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("%s %s: got content type %q", r.Method, r.URL.Path, r.Header.Get("Content-Type"))
+		}
+		var req map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("%s: malformed request body: %v", r.URL.Path, err)
+		}
+		if req["client_id"] != "client-1" || req["secret"] != "secret-1" {
+			t.Errorf("%s: got credentials %v/%v", r.URL.Path, req["client_id"], req["secret"])
+		}
+
+		handler, ok := handlers[r.URL.Path]
+		if !ok {
+			t.Errorf("unexpected request to %s", r.URL.Path)
+			http.NotFound(w, r)
+			return
+		}
+		status, body := handler(req)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		if s, ok := body.(string); ok {
+			w.Write([]byte(s))
+			return
+		}
+		json.NewEncoder(w).Encode(body)
+	}))
+	t.Cleanup(server.Close)
+	return NewPlaidClient(server.URL+"/", "sandbox", "client-1", "secret-1")
+}
+
+func TestPlaidSyncTransactionsPagesWithCursor(t *testing.T) {
+	var cursors []interface{}
+	client := fakePlaid(t, map[string]func(map[string]interface{}) (int, interface{}){
+		"/transactions/sync": func(req map[string]interface{}) (int, interface{}) {
+			if req["access_token"] != "access-1" || req["count"] != float64(plaidSyncPageSize) {
+				t.Errorf("sync request: %v", req)
+			}
+			cursors = append(cursors, req["cursor"])
+			if req["cursor"] == nil {
+				return http.StatusOK, map[string]interface{}{
+					"added": []map[string]interface{}{{
+						"transaction_id":    "txn-1",
+						"account_id":        "acc-1",
+						"amount":            12.5,
+						"name":              "CORNER DELI",
+						"merchant_name":     "Corner Deli",
+						"date":              "2024-03-05",
+						"pending":           true,
+						"iso_currency_code": "USD",
+					}},
+					"next_cursor": "cursor-1",
+					"has_more":    true,
+				}
+			}
+			return http.StatusOK, map[string]interface{}{
+				"modified":    []map[string]interface{}{{"transaction_id": "txn-2", "account_id": "acc-1", "amount": -40, "date": "2024-03-06"}},
+				"removed":     []map[string]interface{}{{"transaction_id": "txn-3"}},
+				"next_cursor": "cursor-2",
+				"has_more":    false,
+			}
+		},
+	})
+	ctx := context.Background()
+
+	first, err := client.SyncTransactions(ctx, "access-1", "")
+	if err != nil {
+		t.Fatalf("first page: %v", err)
+	}
+	if !first.HasMore || first.NextCursor != "cursor-1" || len(first.Added) != 1 {
+		t.Fatalf("first page: got %+v", first)
+	}
+	want := Transaction{
+		ExternalID:        "txn-1",
+		AccountExternalID: "acc-1",
+		Amount:            12.5,
+		Name:              "CORNER DELI",
+		MerchantName:      "Corner Deli",
+		Date:              time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC),
+		Pending:           true,
+		Currency:          "USD",
+	}
+	if first.Added[0] != want {
+		t.Errorf("added: got %+v, want %+v", first.Added[0], want)
+	}
+
+	second, err := client.SyncTransactions(ctx, "access-1", first.NextCursor)
+	if err != nil {
+		t.Fatalf("second page: %v", err)
+	}
+	if second.HasMore || second.NextCursor != "cursor-2" {
+		t.Errorf("second page: got cursor %q, has_more %v", second.NextCursor, second.HasMore)
+	}
+	if len(second.Modified) != 1 || second.Modified[0].ExternalID != "txn-2" || second.Modified[0].Amount != -40 {
+		t.Errorf("modified: got %+v", second.Modified)
+	}
+	if len(second.Removed) != 1 || second.Removed[0] != "txn-3" {
+		t.Errorf("removed: got %v", second.Removed)
+	}
+
+	if len(cursors) != 2 || cursors[0] != nil || cursors[1] != "cursor-1" {
+		t.Errorf("cursors sent: got %v, want [<nil> cursor-1]", cursors)
+	}
+}
+
+func TestPlaidGetAccounts(t *testing.T) {
+	client := fakePlaid(t, map[string]func(map[string]interface{}) (int, interface{}){
+		"/accounts/get": func(req map[string]interface{}) (int, interface{}) {
+			return http.StatusOK, `{"accounts": [
+				{"account_id": "acc-1", "name": "Checking", "mask": "0000", "type": "depository", "subtype": "checking",
+				 "balances": {"current": 110.5, "available": 100, "iso_currency_code": "USD"}},
+				{"account_id": "acc-2", "name": "Card", "type": "credit", "subtype": "credit card",
+				 "balances": {"current": null, "available": null, "iso_currency_code": "EUR"}}
+			]}`
+		},
+	})
+
+	accounts, err := client.GetAccounts(context.Background(), "access-1")
+	if err != nil {
+		t.Fatalf("GetAccounts: %v", err)
+	}
+	if len(accounts) != 2 {
+		t.Fatalf("got %d accounts, want 2", len(accounts))
+	}
+	checking := accounts[0]
+	if checking.ExternalID != "acc-1" || checking.CurrentBalance != 110.5 || checking.AvailableBalance == nil || *checking.AvailableBalance != 100 || checking.Mask != "0000" {
+		t.Errorf("checking: got %+v", checking)
+	}
+	card := accounts[1]
+	if card.CurrentBalance != 0 || card.AvailableBalance != nil || card.Currency != "EUR" {
+		t.Errorf("card with null balances: got %+v", card)
+	}
+}
+
+func TestPlaidErrorMapping(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		want   error
+	}{
+		{"login required", http.StatusBadRequest, `{"error_type": "ITEM_ERROR", "error_code": "ITEM_LOGIN_REQUIRED", "error_message": "login"}`, ErrItemLoginRequired},
+		{"invalid access token", http.StatusBadRequest, `{"error_type": "INVALID_INPUT", "error_code": "INVALID_ACCESS_TOKEN", "error_message": "bad"}`, ErrInvalidToken},
+		{"invalid public token", http.StatusBadRequest, `{"error_type": "INVALID_INPUT", "error_code": "INVALID_PUBLIC_TOKEN", "error_message": "bad"}`, ErrInvalidToken},
+	}
+	for _, tt := range tests {
+		client := fakePlaid(t, map[string]func(map[string]interface{}) (int, interface{}){
+			"/accounts/get": func(map[string]interface{}) (int, interface{}) { return tt.status, tt.body },
+		})
+		_, err := client.GetAccounts(context.Background(), "access-1")
+		if !errors.Is(err, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, err, tt.want)
+		}
+		var plaidErr *PlaidError
+		if !errors.As(err, &plaidErr) || plaidErr.StatusCode != tt.status {
+			t.Errorf("%s: got %#v, want a *PlaidError with status %d", tt.name, err, tt.status)
+		}
+	}
+
+	// Other Plaid errors and non-Plaid failures are neither sentinel.
+	for _, body := range []string{
+		`{"error_type": "RATE_LIMIT_EXCEEDED", "error_code": "TRANSACTIONS_LIMIT", "error_message": "slow down"}`,
+		`<html>bad gateway</html>`,
+	} {
+		client := fakePlaid(t, map[string]func(map[string]interface{}) (int, interface{}){
+			"/accounts/get": func(map[string]interface{}) (int, interface{}) { return http.StatusBadGateway, body },
+		})
+		_, err := client.GetAccounts(context.Background(), "access-1")
+		if err == nil || errors.Is(err, ErrItemLoginRequired) || errors.Is(err, ErrInvalidToken) {
+			t.Errorf("%s: got %v", body, err)
+		}
+	}
+}
+
+func TestPlaidGetWebhookVerificationKey(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := fakePlaid(t, map[string]func(map[string]interface{}) (int, interface{}){
+		"/webhook_verification_key/get": func(req map[string]interface{}) (int, interface{}) {
+			if req["key_id"] != "kid-1" {
+				t.Errorf("key_id: got %v", req["key_id"])
+			}
+			return http.StatusOK, map[string]interface{}{"key": map[string]string{
+				"kty": "EC",
+				"crv": "P-256",
+				"x":   base64.RawURLEncoding.EncodeToString(key.X.Bytes()),
+				"y":   base64.RawURLEncoding.EncodeToString(key.Y.Bytes()),
+			}}
+		},
+	})
+
+	got, err := client.GetWebhookVerificationKey(context.Background(), "kid-1")
+	if err != nil {
+		t.Fatalf("GetWebhookVerificationKey: %v", err)
+	}
+	if !got.Equal(&key.PublicKey) {
+		t.Errorf("got a different key")
+	}
+}
@@ -2,28 +2,34 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
-	"strconv"
+	"strings"
 
+	"finbro-backend-go/internal/api/middleware"
+	"finbro-backend-go/internal/apierror"
 	"finbro-backend-go/internal/db"
 	"finbro-backend-go/internal/db/models"
+	"finbro-backend-go/internal/services"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 type AccountHandler struct {
-	db *db.DB
+	db       *db.DB
+	webhooks *services.OutboundWebhookService
 }
 
-func NewAccountHandler(db *db.DB) *AccountHandler {
-	return &AccountHandler{db: db}
+func NewAccountHandler(db *db.DB, webhooks *services.OutboundWebhookService) *AccountHandler {
+	return &AccountHandler{db: db, webhooks: webhooks}
 }
 
 type CreateAccountRequest struct {
-	AccountName   string  `json:"account_name" binding:"required"`
-	AccountType   string  `json:"account_type"`
+	AccountName   string  `json:"account_name" binding:"required,name"`
+	AccountType   string  `json:"account_type" binding:"omitempty,account_type"`
 	Balance       float64 `json:"balance"`
-	Currency      string  `json:"currency"`
+	Currency      string  `json:"currency" binding:"omitempty,currency"`
 	BankName      string  `json:"bank_name"`
 	AccountNumber string  `json:"account_number"`
 }
@@ -32,8 +38,8 @@ func (h *AccountHandler) GetAccounts(c *gin.Context) {
 	userID, _ := c.Get("user_id")
 
 	var accounts []models.Account
-	if err := h.db.Where("user_id = ?", userID).Find(&accounts).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch accounts"})
+	if err := h.db.WithContext(c.Request.Context()).Where("user_id = ?", userID).Find(&accounts).Error; err != nil {
+		apierror.Respond(c, apierror.Internal(err, "Failed to fetch accounts"))
 		return
 	}
 
@@ -45,76 +51,151 @@ func (h *AccountHandler) CreateAccount(c *gin.Context) {
 
 	var req CreateAccountRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.Respond(c, err)
 		return
 	}
 
 	account := &models.Account{
 		UserID:        userID.(uint),
 		AccountName:   req.AccountName,
-		AccountType:   req.AccountType,
+		AccountType:   strings.ToLower(req.AccountType),
 		Balance:       req.Balance,
-		Currency:      req.Currency,
+		Currency:      strings.ToUpper(req.Currency),
 		BankName:      req.BankName,
 		AccountNumber: req.AccountNumber,
 	}
 
-	if err := h.db.Create(account).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create account"})
+	err := h.db.WithContext(c.Request.Context()).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(account).Error; err != nil {
+			return err
+		}
+		return h.webhooks.Publish(tx, account.UserID, services.EventAccountCreated, account)
+	})
+	if err != nil {
+		apierror.Respond(c, apierror.Internal(err, "Failed to create account"))
 		return
 	}
 
+	c.Header("ETag", middleware.ETag(account.Version))
 	c.JSON(http.StatusCreated, account)
 }
 
 func (h *AccountHandler) GetAccount(c *gin.Context) {
-	userID, _ := c.Get("user_id")
-	accountID, _ := strconv.Atoi(c.Param("id"))
+	c.JSON(http.StatusOK, middleware.CurrentAccount(c))
+}
 
-	var account models.Account
-	if err := h.db.Where("id = ? AND user_id = ?", accountID, userID).First(&account).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Account not found"})
+func (h *AccountHandler) UpdateAccount(c *gin.Context) {
+	account := middleware.CurrentAccount(c)
+
+	var req CreateAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Respond(c, err)
 		return
 	}
 
+	account.AccountName = req.AccountName
+	account.AccountType = strings.ToLower(req.AccountType)
+	account.BankName = req.BankName
+	account.AccountNumber = req.AccountNumber
+
+	err := h.db.WithContext(c.Request.Context()).Transaction(func(tx *gorm.DB) error {
+		if err := db.UpdateVersioned(tx, account, &account.Version,
+			"account_name", "account_type", "bank_name", "account_number"); err != nil {
+			return err
+		}
+		return h.webhooks.Publish(tx, account.UserID, services.EventAccountUpdated, account)
+	})
+	if errors.Is(err, db.ErrVersionConflict) {
+		apierror.Respond(c, apierror.PreconditionFailed("Account has been modified"))
+		return
+	}
+	if err != nil {
+		apierror.Respond(c, apierror.Internal(err, "Failed to update account"))
+		return
+	}
+
+	c.Header("ETag", middleware.ETag(account.Version))
 	c.JSON(http.StatusOK, account)
 }
 
-func (h *AccountHandler) UpdateAccount(c *gin.Context) {
-	userID, _ := c.Get("user_id")
-	accountID, _ := strconv.Atoi(c.Param("id"))
+// PatchAccountRequest is a JSON Merge Patch. bank_name and account_number
+// may be set to null to clear them.
+type PatchAccountRequest struct {
+	AccountName   *string `json:"account_name" binding:"omitempty,name"`
+	AccountType   *string `json:"account_type" binding:"omitempty,account_type"`
+	BankName      *string `json:"bank_name"`
+	AccountNumber *string `json:"account_number"`
+}
 
-	var req CreateAccountRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+func (h *AccountHandler) PatchAccount(c *gin.Context) {
+	account := middleware.CurrentAccount(c)
+
+	var req PatchAccountRequest
+	patch, ok := bindMergePatch(c, &req, "account_name", "account_type")
+	if !ok {
 		return
 	}
 
-	var account models.Account
-	if err := h.db.Where("id = ? AND user_id = ?", accountID, userID).First(&account).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Account not found"})
-		return
+	columns := []string{}
+	if req.AccountName != nil {
+		account.AccountName = *req.AccountName
+		columns = append(columns, "account_name")
+	}
+	if req.AccountType != nil {
+		account.AccountType = strings.ToLower(*req.AccountType)
+		columns = append(columns, "account_type")
+	}
+	if req.BankName != nil || patch.isNull("bank_name") {
+		account.BankName = ""
+		if req.BankName != nil {
+			account.BankName = *req.BankName
+		}
+		columns = append(columns, "bank_name")
+	}
+	if req.AccountNumber != nil || patch.isNull("account_number") {
+		account.AccountNumber = ""
+		if req.AccountNumber != nil {
+			account.AccountNumber = *req.AccountNumber
+		}
+		columns = append(columns, "account_number")
 	}
 
-	account.AccountName = req.AccountName
-	account.AccountType = req.AccountType
-	account.BankName = req.BankName
-	account.AccountNumber = req.AccountNumber
+	if len(columns) == 0 {
+		c.Header("ETag", middleware.ETag(account.Version))
+		c.JSON(http.StatusOK, account)
+		return
+	}
 
-	if err := h.db.Save(&account).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update account"})
+	err := h.db.WithContext(c.Request.Context()).Transaction(func(tx *gorm.DB) error {
+		if err := db.UpdateVersioned(tx, account, &account.Version, columns...); err != nil {
+			return err
+		}
+		return h.webhooks.Publish(tx, account.UserID, services.EventAccountUpdated, account)
+	})
+	if errors.Is(err, db.ErrVersionConflict) {
+		apierror.Respond(c, apierror.PreconditionFailed("Account has been modified"))
+		return
+	}
+	if err != nil {
+		apierror.Respond(c, apierror.Internal(err, "Failed to update account"))
 		return
 	}
 
+	c.Header("ETag", middleware.ETag(account.Version))
 	c.JSON(http.StatusOK, account)
 }
 
 func (h *AccountHandler) DeleteAccount(c *gin.Context) {
-	userID, _ := c.Get("user_id")
-	accountID, _ := strconv.Atoi(c.Param("id"))
-
-	if err := h.db.Where("id = ? AND user_id = ?", accountID, userID).Delete(&models.Account{}).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete account"})
+	account := middleware.CurrentAccount(c)
+
+	err := h.db.WithContext(c.Request.Context()).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(account).Error; err != nil {
+			return err
+		}
+		return h.webhooks.Publish(tx, account.UserID, services.EventAccountDeleted, account)
+	})
+	if err != nil {
+		apierror.Respond(c, apierror.Internal(err, "Failed to delete account"))
 		return
 	}
 
@@ -0,0 +1,64 @@
+// internal/api/handlers/assistant.go
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"finbro-backend-go/internal/apierror"
+	"finbro-backend-go/internal/db"
+	"finbro-backend-go/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AssistantHandler struct {
+	db               *db.DB
+	assistantService *services.AssistantService
+}
+
+func NewAssistantHandler(db *db.DB, assistantService *services.AssistantService) *AssistantHandler {
+	return &AssistantHandler{
+		db:               db,
+		assistantService: assistantService,
+	}
+}
+
+type AskRequest struct {
+	Question string `json:"question" binding:"required,max=500"`
+}
+
+func (h *AssistantHandler) Ask(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	var req AskRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	question := strings.TrimSpace(req.Question)
+	if question == "" {
+		apierror.Respond(c, apierror.Validation("The request contains invalid fields", apierror.FieldError{
+			Field:   "question",
+			Code:    "required",
+			Message: "is required",
+		}))
+		return
+	}
+
+	answer, err := h.assistantService.Ask(c.Request.Context(), userID.(uint), question)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrAssistantDisabled):
+			apierror.Respond(c, apierror.Unavailable(err.Error()))
+		case errors.Is(err, services.ErrAssistantNoAnswer):
+			apierror.Respond(c, apierror.Unprocessable(err.Error()))
+		default:
+			apierror.Respond(c, apierror.Upstream(err, "Assistant provider request failed"))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, answer)
+}
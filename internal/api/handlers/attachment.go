@@ -0,0 +1,180 @@
+// internal/api/handlers/attachment.go
+package handlers
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"finbro-backend-go/internal/api/middleware"
+	"finbro-backend-go/internal/apierror"
+	"finbro-backend-go/internal/db"
+	"finbro-backend-go/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// attachmentFormField is the multipart form field carrying the upload.
+const attachmentFormField = "file"
+
+type AttachmentHandler struct {
+	db                *db.DB
+	attachmentService *services.AttachmentService
+}
+
+func NewAttachmentHandler(db *db.DB, attachmentService *services.AttachmentService) *AttachmentHandler {
+	return &AttachmentHandler{
+		db:                db,
+		attachmentService: attachmentService,
+	}
+}
+
+func (h *AttachmentHandler) GetAttachments(c *gin.Context) {
+	transaction := middleware.CurrentTransaction(c)
+
+	attachments, err := h.attachmentService.List(transaction.ID)
+	if err != nil {
+		apierror.Respond(c, apierror.Internal(err, "Failed to fetch attachments"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": attachments})
+}
+
+// UploadAttachment takes a multipart/form-data body whose "file" part is
+// the attachment. The part is read without buffering the rest of the form
+// to disk, and is cut off one byte past the size limit.
+func (h *AttachmentHandler) UploadAttachment(c *gin.Context) {
+	transaction := middleware.CurrentTransaction(c)
+
+	reader, err := c.Request.MultipartReader()
+	if err != nil {
+		apierror.Respond(c, apierror.UnsupportedMediaType("Request body must be multipart/form-data"))
+		return
+	}
+
+	maxBytes := h.attachmentService.MaxBytes()
+	for {
+		part, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			apierror.Respond(c, apierror.Validation("The request contains invalid fields", apierror.FieldError{
+				Field:   attachmentFormField,
+				Code:    "required",
+				Message: "is required",
+			}))
+			return
+		}
+		if err != nil {
+			apierror.Respond(c, multipartError(err))
+			return
+		}
+		if part.FormName() != attachmentFormField {
+			part.Close()
+			continue
+		}
+
+		data, err := io.ReadAll(io.LimitReader(part, maxBytes+1))
+		part.Close()
+		if err != nil {
+			apierror.Respond(c, multipartError(err))
+			return
+		}
+		if int64(len(data)) > maxBytes {
+			apierror.Respond(c, apierror.PayloadTooLarge("Attachment must be at most "+strconv.FormatInt(maxBytes, 10)+" bytes"))
+			return
+		}
+
+		attachment, err := h.attachmentService.Create(c.Request.Context(), transaction, part.FileName(), data)
+		if err != nil {
+			respondAttachmentError(c, err)
+			return
+		}
+		c.JSON(http.StatusCreated, attachment)
+		return
+	}
+}
+
+func (h *AttachmentHandler) DownloadAttachment(c *gin.Context) {
+	h.download(c, false)
+}
+
+// DownloadThumbnail serves the JPEG thumbnail of an image attachment.
+func (h *AttachmentHandler) DownloadThumbnail(c *gin.Context) {
+	h.download(c, true)
+}
+
+// download streams an attachment from the blob store. It is always served
+// with the sniffed content type and nosniff, so an upload cannot turn into
+// a page the browser runs.
+func (h *AttachmentHandler) download(c *gin.Context, thumbnail bool) {
+	transaction := middleware.CurrentTransaction(c)
+
+	attachment, err := h.attachmentService.Get(transaction.ID, c.Param("attachment_id"))
+	if err != nil {
+		respondAttachmentError(c, err)
+		return
+	}
+
+	body, err := h.attachmentService.Open(c.Request.Context(), attachment, thumbnail)
+	if err != nil {
+		respondAttachmentError(c, err)
+		return
+	}
+	defer body.Close()
+
+	contentType, size, disposition := attachment.ContentType, attachment.Size, "attachment"
+	etag := `"` + attachment.SHA256 + `"`
+	if thumbnail {
+		contentType, size, disposition = "image/jpeg", -1, "inline"
+		etag = `"` + attachment.SHA256 + `-thumb"`
+	}
+
+	c.DataFromReader(http.StatusOK, size, contentType, body, map[string]string{
+		"Content-Disposition":    mime.FormatMediaType(disposition, map[string]string{"filename": attachment.Filename}),
+		"X-Content-Type-Options": "nosniff",
+		"Cache-Control":          "private, max-age=3600",
+		"ETag":                   etag,
+	})
+}
+
+func (h *AttachmentHandler) DeleteAttachment(c *gin.Context) {
+	transaction := middleware.CurrentTransaction(c)
+
+	if err := h.attachmentService.Delete(c.Request.Context(), transaction.ID, c.Param("attachment_id")); err != nil {
+		respondAttachmentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Attachment deleted successfully"})
+}
+
+// multipartError maps a failure reading the upload. Hitting the server's
+// body limit is a 413 like on every other route.
+func multipartError(err error) *apierror.Error {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return apierror.PayloadTooLarge("Request body too large")
+	}
+	return apierror.BadRequest("Request body is not valid multipart/form-data").Wrap(err)
+}
+
+func respondAttachmentError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrAttachmentNotFound):
+		apierror.Respond(c, apierror.NotFound("Attachment not found"))
+	case errors.Is(err, services.ErrThumbnailNotFound):
+		apierror.Respond(c, apierror.NotFound("Attachment has no thumbnail"))
+	case errors.Is(err, services.ErrAttachmentTooLarge):
+		apierror.Respond(c, apierror.PayloadTooLarge(err.Error()))
+	case errors.Is(err, services.ErrAttachmentType):
+		apierror.Respond(c, apierror.UnsupportedMediaType(err.Error()))
+	case errors.Is(err, services.ErrAttachmentEmpty):
+		apierror.Respond(c, apierror.BadRequest(err.Error()))
+	case errors.Is(err, services.ErrTooManyAttachments):
+		apierror.Respond(c, apierror.Conflict(err.Error()))
+	default:
+		apierror.Respond(c, apierror.Internal(err, "Failed to process attachment"))
+	}
+}
@@ -7,10 +7,12 @@ import (
 	"net/http"
 	"time"
 
+	"finbro-backend-go/internal/apierror"
 	"finbro-backend-go/internal/auth"
 	"finbro-backend-go/internal/config"
 	"finbro-backend-go/internal/db"
 	"finbro-backend-go/internal/db/models"
+	"finbro-backend-go/internal/metrics"
 	"finbro-backend-go/internal/services"
 
 	"github.com/gin-gonic/gin"
@@ -42,8 +44,8 @@ func NewAuthHandler(db *db.DB, cfg *config.Config, userService *services.UserSer
 type RegisterRequest struct {
 	Email     string `json:"email" binding:"required,email"`
 	Password  string `json:"password" binding:"required,min=6"`
-	FirstName string `json:"first_name"`
-	LastName  string `json:"last_name"`
+	FirstName string `json:"first_name" binding:"omitempty,name"`
+	LastName  string `json:"last_name" binding:"omitempty,name"`
 	UserType  string `json:"user_type"`
 }
 
@@ -60,7 +62,7 @@ type AuthResponse struct {
 func (h *AuthHandler) Register(c *gin.Context) {
 	var req RegisterRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.Respond(c, err)
 		return
 	}
 
@@ -72,18 +74,18 @@ func (h *AuthHandler) Register(c *gin.Context) {
 	}
 
 	if err := user.SetPassword(req.Password); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash password"})
+		apierror.Respond(c, apierror.Internal(err, "Failed to hash password"))
 		return
 	}
 
-	if err := h.db.Create(user).Error; err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Email already exists"})
+	if err := h.db.WithContext(c.Request.Context()).Create(user).Error; err != nil {
+		apierror.Respond(c, apierror.BadRequest("Email already exists"))
 		return
 	}
 
 	token, err := h.jwtAuth.GenerateToken(user.ID, user.Email)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		apierror.Respond(c, apierror.Internal(err, "Failed to generate token"))
 		return
 	}
 
@@ -94,27 +96,30 @@ func (h *AuthHandler) Register(c *gin.Context) {
 func (h *AuthHandler) Login(c *gin.Context) {
 	var req LoginRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.Respond(c, err)
 		return
 	}
 
 	var user models.User
-	if err := h.db.Where("email = ?", req.Email).First(&user).Error; err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
+	if err := h.db.WithContext(c.Request.Context()).Where("email = ?", req.Email).First(&user).Error; err != nil {
+		metrics.RecordAuth("password", false)
+		apierror.Respond(c, apierror.Unauthorized("Invalid credentials"))
 		return
 	}
 
 	if !user.CheckPassword(req.Password) {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
+		metrics.RecordAuth("password", false)
+		apierror.Respond(c, apierror.Unauthorized("Invalid credentials"))
 		return
 	}
 
 	token, err := h.jwtAuth.GenerateToken(user.ID, user.Email)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		apierror.Respond(c, apierror.Internal(err, "Failed to generate token"))
 		return
 	}
 
+	metrics.RecordAuth("password", true)
 	user.Password = ""
 	c.JSON(http.StatusOK, AuthResponse{Token: token, User: &user})
 }
@@ -122,29 +127,31 @@ func (h *AuthHandler) Login(c *gin.Context) {
 func (h *AuthHandler) RefreshToken(c *gin.Context) {
 	userID, exists := c.Get("user_id")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		apierror.Respond(c, apierror.Unauthorized("Invalid token"))
 		return
 	}
 
 	var user models.User
-	if err := h.db.First(&user, userID).Error; err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+	if err := h.db.WithContext(c.Request.Context()).First(&user, userID).Error; err != nil {
+		metrics.RecordAuth("refresh", false)
+		apierror.Respond(c, apierror.Unauthorized("User not found"))
 		return
 	}
 
 	token, err := h.jwtAuth.GenerateToken(user.ID, user.Email)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		apierror.Respond(c, apierror.Internal(err, "Failed to generate token"))
 		return
 	}
 
+	metrics.RecordAuth("refresh", true)
 	c.JSON(http.StatusOK, gin.H{"token": token})
 }
 
 func (h *AuthHandler) GoogleLogin(c *gin.Context) {
 	state, err := h.generateState()
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate state"})
+		apierror.Respond(c, apierror.Internal(err, "Failed to generate state"))
 		return
 	}
 
@@ -165,41 +172,45 @@ func (h *AuthHandler) GoogleCallback(c *gin.Context) {
 	state := c.Query("state")
 
 	if !h.validateState(state) {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired state parameter"})
+		metrics.RecordAuth("google", false)
+		apierror.Respond(c, apierror.BadRequest("Invalid or expired state parameter"))
 		return
 	}
 
 	delete(h.stateStore, state)
 
 	if code == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Authorization code not provided"})
+		apierror.Respond(c, apierror.BadRequest("Authorization code not provided"))
 		return
 	}
 
 	token, err := h.googleOAuth.ExchangeCode(c.Request.Context(), code)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to exchange code for token"})
+		metrics.RecordAuth("google", false)
+		apierror.Respond(c, apierror.BadRequest("Failed to exchange code for token"))
 		return
 	}
 
 	googleUser, err := h.googleOAuth.GetUserInfo(c.Request.Context(), token)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to get user info from Google"})
+		metrics.RecordAuth("google", false)
+		apierror.Respond(c, apierror.BadRequest("Failed to get user info from Google"))
 		return
 	}
 
 	user, err := h.userService.CreateOrUpdateOAuthUser(c, googleUser.Email, googleUser.Name)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create or update user"})
+		apierror.Respond(c, apierror.Internal(err, "Failed to create or update user"))
 		return
 	}
 
 	jwtToken, err := h.jwtAuth.GenerateToken(user.ID, user.Email)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		apierror.Respond(c, apierror.Internal(err, "Failed to generate token"))
 		return
 	}
 
+	metrics.RecordAuth("google", true)
 	c.JSON(http.StatusOK, AuthResponse{Token: jwtToken, User: user})
 }
 
@@ -0,0 +1,117 @@
+// internal/api/handlers/banklink.go
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"finbro-backend-go/internal/aggregator"
+	"finbro-backend-go/internal/apierror"
+	"finbro-backend-go/internal/db"
+	"finbro-backend-go/internal/publicid"
+	"finbro-backend-go/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type BankLinkHandler struct {
+	db              *db.DB
+	bankLinkService *services.BankLinkService
+}
+
+func NewBankLinkHandler(db *db.DB, bankLinkService *services.BankLinkService) *BankLinkHandler {
+	return &BankLinkHandler{
+		db:              db,
+		bankLinkService: bankLinkService,
+	}
+}
+
+type ExchangeTokenRequest struct {
+	PublicToken string `json:"public_token" binding:"required"`
+}
+
+func (h *BankLinkHandler) CreateLinkToken(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	token, err := h.bankLinkService.CreateLinkToken(c.Request.Context(), userID.(uint))
+	if err != nil {
+		respondBankLinkError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, token)
+}
+
+func (h *BankLinkHandler) ExchangePublicToken(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	var req ExchangeTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	item, err := h.bankLinkService.ExchangePublicToken(c.Request.Context(), userID.(uint), req.PublicToken)
+	if err != nil {
+		respondBankLinkError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, item)
+}
+
+func (h *BankLinkHandler) GetItems(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	items, err := h.bankLinkService.GetItems(userID.(uint))
+	if err != nil {
+		apierror.Respond(c, apierror.Internal(err, "Failed to fetch linked institutions"))
+		return
+	}
+
+	c.JSON(http.StatusOK, items)
+}
+
+func (h *BankLinkHandler) SyncItem(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	item, err := h.bankLinkService.GetItem(c.Param("id"), userID.(uint))
+	if err != nil {
+		respondBankLinkError(c, err)
+		return
+	}
+
+	summary, err := h.bankLinkService.SyncItem(c.Request.Context(), item)
+	if err != nil {
+		respondBankLinkError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, summary)
+}
+
+func (h *BankLinkHandler) RemoveItem(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	if err := h.bankLinkService.RemoveItem(c.Request.Context(), c.Param("id"), userID.(uint)); err != nil {
+		respondBankLinkError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Institution unlinked successfully"})
+}
+
+func respondBankLinkError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrBankLinkDisabled):
+		apierror.Respond(c, apierror.Unavailable(err.Error()))
+	case errors.Is(err, publicid.ErrInvalid):
+		apierror.Respond(c, apierror.BadRequest("Invalid id"))
+	case errors.Is(err, services.ErrBankItemNotFound):
+		apierror.Respond(c, apierror.NotFound("Linked institution not found"))
+	case errors.Is(err, aggregator.ErrInvalidToken):
+		apierror.Respond(c, apierror.BadRequest(err.Error()))
+	case errors.Is(err, aggregator.ErrItemLoginRequired):
+		apierror.Respond(c, apierror.Conflict("Institution requires the user to log in again"))
+	default:
+		apierror.Respond(c, apierror.Upstream(err, "Bank data provider request failed"))
+	}
+}
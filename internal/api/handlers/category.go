@@ -0,0 +1,156 @@
+// internal/api/handlers/category.go
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"finbro-backend-go/internal/api/middleware"
+	"finbro-backend-go/internal/apierror"
+	"finbro-backend-go/internal/db"
+	"finbro-backend-go/internal/db/models"
+	"finbro-backend-go/internal/services"
+	"finbro-backend-go/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CategoryHandler struct {
+	db              *db.DB
+	categoryService *services.CategoryService
+}
+
+func NewCategoryHandler(db *db.DB, categoryService *services.CategoryService) *CategoryHandler {
+	return &CategoryHandler{
+		db:              db,
+		categoryService: categoryService,
+	}
+}
+
+type CategoryRequest struct {
+	Name     string `json:"name" binding:"required,name"`
+	ParentID *uint  `json:"parent_id"`
+	Icon     string `json:"icon"`
+	Color    string `json:"color" binding:"omitempty,color"`
+}
+
+func (h *CategoryHandler) GetCategories(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	categories, err := h.categoryService.GetCategoryTree(userID.(uint))
+	if err != nil {
+		apierror.Respond(c, apierror.Internal(err, "Failed to fetch categories"))
+		return
+	}
+
+	c.JSON(http.StatusOK, categories)
+}
+
+func (h *CategoryHandler) GetCategory(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	categoryID, ok := middleware.PathID(c, "id")
+	if !ok {
+		return
+	}
+
+	category, err := h.categoryService.GetCategoryByID(h.db.DB, categoryID, userID.(uint))
+	if err != nil {
+		respondCategoryError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, category)
+}
+
+func (h *CategoryHandler) CreateCategory(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	var req CategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	category := &models.Category{
+		Name:     utils.SanitizeString(req.Name),
+		ParentID: req.ParentID,
+		Icon:     req.Icon,
+		Color:    req.Color,
+	}
+
+	if err := h.categoryService.CreateCategory(userID.(uint), category); err != nil {
+		respondCategoryError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, category)
+}
+
+func (h *CategoryHandler) UpdateCategory(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	categoryID, ok := middleware.PathID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req CategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	category, err := h.categoryService.GetCategoryByID(h.db.DB, categoryID, userID.(uint))
+	if err != nil {
+		respondCategoryError(c, err)
+		return
+	}
+
+	category.Name = utils.SanitizeString(req.Name)
+	category.ParentID = req.ParentID
+	category.Icon = req.Icon
+	category.Color = req.Color
+
+	if err := h.categoryService.UpdateCategory(userID.(uint), category); err != nil {
+		respondCategoryError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, category)
+}
+
+func (h *CategoryHandler) DeleteCategory(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	categoryID, ok := middleware.PathID(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.categoryService.DeleteCategory(categoryID, userID.(uint)); err != nil {
+		respondCategoryError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Category deleted successfully"})
+}
+
+func respondCategoryError(c *gin.Context, err error) {
+	apierror.Respond(c, categoryError(err))
+}
+
+// categoryError maps category service errors to API errors.
+func categoryError(err error) *apierror.Error {
+	switch {
+	case errors.Is(err, services.ErrCategoryNotFound):
+		return apierror.NotFound("Category not found")
+	case errors.Is(err, services.ErrSystemCategory):
+		return apierror.Forbidden(err.Error())
+	case errors.Is(err, services.ErrDuplicateCategory):
+		return apierror.Conflict(err.Error())
+	case errors.Is(err, services.ErrInvalidParent), errors.Is(err, services.ErrInvalidCategory):
+		return apierror.BadRequest(err.Error())
+	case errors.Is(err, services.ErrCategoryInUse):
+		return apierror.Conflict(err.Error())
+	default:
+		return apierror.Internal(err, "Failed to process category")
+	}
+}
@@ -0,0 +1,44 @@
+// internal/api/handlers/health.go
+package handlers
+
+import (
+	"net/http"
+
+	"finbro-backend-go/internal/health"
+
+	"github.com/gin-gonic/gin"
+)
+
+type HealthHandler struct {
+	registry  *health.Registry
+	readiness *health.State
+}
+
+func NewHealthHandler(registry *health.Registry, readiness *health.State) *HealthHandler {
+	return &HealthHandler{
+		registry:  registry,
+		readiness: readiness,
+	}
+}
+
+// Livez reports that the process is up and serving. It deliberately checks
+// no dependencies, so an outage elsewhere never gets the pod restarted.
+func (h *HealthHandler) Livez(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": health.StatusOK})
+}
+
+// Readyz runs the dependency checks. It answers 503 when a critical check
+// fails or the server is shutting down, and 200 when merely degraded.
+func (h *HealthHandler) Readyz(c *gin.Context) {
+	if h.readiness.Draining() {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "shutting_down"})
+		return
+	}
+
+	report := h.registry.Run(c.Request.Context())
+	status := http.StatusOK
+	if report.Status == health.StatusFail {
+		status = http.StatusServiceUnavailable
+	}
+	c.JSON(status, report)
+}
@@ -0,0 +1,65 @@
+// internal/api/handlers/patch.go
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+
+	"finbro-backend-go/internal/apierror"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+// mergePatch holds the top-level members of a JSON Merge Patch (RFC 7396)
+// document. Pointer fields in the request type tell absent members apart
+// from present ones; this map additionally tells explicit nulls, which mean
+// "clear this field", from absent members.
+type mergePatch map[string]json.RawMessage
+
+func (p mergePatch) isNull(field string) bool {
+	value, ok := p[field]
+	return ok && string(value) == "null"
+}
+
+// bindMergePatch decodes and validates a merge patch from the request body
+// into obj, responding with a problem document when it is invalid.
+func bindMergePatch(c *gin.Context, obj interface{}, required ...string) (mergePatch, bool) {
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		apierror.Respond(c, err)
+		return nil, false
+	}
+
+	patch, err := decodeMergePatch(body, obj, required...)
+	if err != nil {
+		apierror.Respond(c, err)
+		return nil, false
+	}
+	return patch, true
+}
+
+// decodeMergePatch decodes and validates a merge patch into obj. Fields
+// listed in required cannot be cleared, so a null for them is a validation
+// error.
+func decodeMergePatch(body []byte, obj interface{}, required ...string) (mergePatch, error) {
+	var patch mergePatch
+	if err := json.Unmarshal(body, &patch); err != nil || patch == nil {
+		return nil, apierror.BadRequest("Request body must be a JSON object").Wrap(err)
+	}
+
+	var fields []apierror.FieldError
+	for _, field := range required {
+		if patch.isNull(field) {
+			fields = append(fields, apierror.FieldError{Field: field, Code: "required", Message: "cannot be null"})
+		}
+	}
+	if len(fields) > 0 {
+		return nil, apierror.Validation("The request contains invalid fields", fields...)
+	}
+
+	if err := binding.JSON.BindBody(body, obj); err != nil {
+		return nil, err
+	}
+	return patch, nil
+}
@@ -0,0 +1,190 @@
+// internal/api/handlers/rule.go
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"finbro-backend-go/internal/api/middleware"
+	"finbro-backend-go/internal/apierror"
+	"finbro-backend-go/internal/db"
+	"finbro-backend-go/internal/db/models"
+	"finbro-backend-go/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RuleHandler struct {
+	db          *db.DB
+	ruleService *services.RuleService
+}
+
+func NewRuleHandler(db *db.DB, ruleService *services.RuleService) *RuleHandler {
+	return &RuleHandler{
+		db:          db,
+		ruleService: ruleService,
+	}
+}
+
+type RuleRequest struct {
+	Name           string                `json:"name" binding:"required"`
+	Priority       *int                  `json:"priority"`
+	IsActive       *bool                 `json:"is_active"`
+	StopProcessing bool                  `json:"stop_processing"`
+	Conditions     models.RuleConditions `json:"conditions"`
+	Actions        models.RuleActions    `json:"actions"`
+}
+
+func (r RuleRequest) applyTo(rule *models.Rule) {
+	rule.Name = r.Name
+	rule.StopProcessing = r.StopProcessing
+	rule.Conditions = r.Conditions
+	rule.Actions = r.Actions
+
+	rule.Priority = 100
+	if r.Priority != nil {
+		rule.Priority = *r.Priority
+	}
+	rule.IsActive = true
+	if r.IsActive != nil {
+		rule.IsActive = *r.IsActive
+	}
+}
+
+func (h *RuleHandler) GetRules(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	rules, err := h.ruleService.GetRules(userID.(uint))
+	if err != nil {
+		apierror.Respond(c, apierror.Internal(err, "Failed to fetch rules"))
+		return
+	}
+
+	c.JSON(http.StatusOK, rules)
+}
+
+func (h *RuleHandler) GetRule(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	ruleID, ok := middleware.PathID(c, "id")
+	if !ok {
+		return
+	}
+
+	rule, err := h.ruleService.GetRuleByID(ruleID, userID.(uint))
+	if err != nil {
+		respondRuleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, rule)
+}
+
+func (h *RuleHandler) CreateRule(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	var req RuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	rule := &models.Rule{UserID: userID.(uint)}
+	req.applyTo(rule)
+
+	if err := h.ruleService.CreateRule(rule); err != nil {
+		respondRuleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, rule)
+}
+
+func (h *RuleHandler) UpdateRule(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	ruleID, ok := middleware.PathID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req RuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	rule, err := h.ruleService.GetRuleByID(ruleID, userID.(uint))
+	if err != nil {
+		respondRuleError(c, err)
+		return
+	}
+	req.applyTo(rule)
+
+	if err := h.ruleService.UpdateRule(rule); err != nil {
+		respondRuleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, rule)
+}
+
+func (h *RuleHandler) DeleteRule(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	ruleID, ok := middleware.PathID(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.ruleService.DeleteRule(ruleID, userID.(uint)); err != nil {
+		respondRuleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Rule deleted successfully"})
+}
+
+// DryRunRule lists the transactions a rule would change without saving.
+func (h *RuleHandler) DryRunRule(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	ruleID, ok := middleware.PathID(c, "id")
+	if !ok {
+		return
+	}
+
+	result, err := h.ruleService.DryRun(ruleID, userID.(uint))
+	if err != nil {
+		respondRuleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// ApplyRule runs a rule against the user's existing transactions.
+func (h *RuleHandler) ApplyRule(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	ruleID, ok := middleware.PathID(c, "id")
+	if !ok {
+		return
+	}
+
+	updated, err := h.ruleService.ApplyRetroactively(ruleID, userID.(uint))
+	if err != nil {
+		respondRuleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"updated": updated})
+}
+
+func respondRuleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrRuleNotFound):
+		apierror.Respond(c, apierror.NotFound("Rule not found"))
+	case errors.Is(err, services.ErrInvalidRule):
+		apierror.Respond(c, apierror.BadRequest(err.Error()))
+	case errors.Is(err, db.ErrVersionConflict):
+		apierror.Respond(c, apierror.Conflict("Transactions changed while the rule was applied; nothing was changed, try again"))
+	default:
+		apierror.Respond(c, apierror.Internal(err, "Failed to process rule"))
+	}
+}
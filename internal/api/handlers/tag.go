@@ -0,0 +1,263 @@
+// internal/api/handlers/tag.go
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"finbro-backend-go/internal/api/middleware"
+	"finbro-backend-go/internal/apierror"
+	"finbro-backend-go/internal/db"
+	"finbro-backend-go/internal/db/models"
+	"finbro-backend-go/internal/publicid"
+	"finbro-backend-go/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type TagHandler struct {
+	db         *db.DB
+	tagService *services.TagService
+	webhooks   *services.OutboundWebhookService
+}
+
+func NewTagHandler(db *db.DB, tagService *services.TagService, webhooks *services.OutboundWebhookService) *TagHandler {
+	return &TagHandler{
+		db:         db,
+		tagService: tagService,
+		webhooks:   webhooks,
+	}
+}
+
+type TagRequest struct {
+	Name string `json:"name" binding:"required,max=50"`
+}
+
+// TagTransactionsRequest adds and removes tags by name on many transactions
+// at once. Tags named in add are created when they do not exist yet.
+type TagTransactionsRequest struct {
+	TransactionIDs []publicid.Ref `json:"transaction_ids" binding:"required,min=1,max=500"`
+	Add            []string       `json:"add" binding:"omitempty,max=20,dive,max=50"`
+	Remove         []string       `json:"remove" binding:"omitempty,max=20,dive,max=50"`
+}
+
+func (h *TagHandler) GetTags(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	tags, err := h.tagService.GetTags(userID.(uint))
+	if err != nil {
+		apierror.Respond(c, apierror.Internal(err, "Failed to fetch tags"))
+		return
+	}
+
+	c.JSON(http.StatusOK, tags)
+}
+
+func (h *TagHandler) GetTag(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	tagID, ok := middleware.PathID(c, "id")
+	if !ok {
+		return
+	}
+
+	tag, err := h.tagService.GetTagByID(tagID, userID.(uint))
+	if err != nil {
+		respondTagError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tag)
+}
+
+func (h *TagHandler) CreateTag(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	var req TagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	tag := &models.Tag{UserID: userID.(uint), Name: req.Name}
+	if err := h.tagService.CreateTag(tag); err != nil {
+		respondTagError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, tag)
+}
+
+// UpdateTag renames a tag. Every transaction carrying it shows the new name.
+func (h *TagHandler) UpdateTag(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	tagID, ok := middleware.PathID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req TagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	tag, err := h.tagService.GetTagByID(tagID, userID.(uint))
+	if err != nil {
+		respondTagError(c, err)
+		return
+	}
+
+	tag.Name = req.Name
+	if err := h.tagService.UpdateTag(tag); err != nil {
+		respondTagError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tag)
+}
+
+func (h *TagHandler) DeleteTag(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	tagID, ok := middleware.PathID(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.tagService.DeleteTag(tagID, userID.(uint)); err != nil {
+		respondTagError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Tag deleted successfully"})
+}
+
+// GetTagReport totals spending and income per tag, optionally limited to
+// start_date and end_date.
+func (h *TagHandler) GetTagReport(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	start, err := parseDateParam(c.Query("start_date"), false)
+	if err != nil {
+		apierror.Respond(c, apierror.BadRequest("invalid start_date"))
+		return
+	}
+	end, err := parseDateParam(c.Query("end_date"), true)
+	if err != nil {
+		apierror.Respond(c, apierror.BadRequest("invalid end_date"))
+		return
+	}
+
+	reports, err := h.tagService.Report(userID.(uint), start, end)
+	if err != nil {
+		apierror.Respond(c, apierror.Internal(err, "Failed to build tag report"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": reports})
+}
+
+// TagTransactions applies a TagTransactionsRequest to every listed
+// transaction in one database transaction. If any ID does not name one of
+// the user's transactions nothing is changed.
+func (h *TagHandler) TagTransactions(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	var req TagTransactionsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	if len(req.Add) == 0 && len(req.Remove) == 0 {
+		apierror.Respond(c, apierror.Validation("The request contains invalid fields", apierror.FieldError{
+			Field:   "add",
+			Code:    "required",
+			Message: "add or remove must list at least one tag",
+		}))
+		return
+	}
+
+	refs := make([]string, len(req.TransactionIDs))
+	for i, ref := range req.TransactionIDs {
+		refs[i] = ref.String()
+	}
+	scope, err := publicid.ScopeAll(refs)
+	if err != nil {
+		apierror.Respond(c, apierror.BadRequest("invalid transaction_ids"))
+		return
+	}
+
+	var transactions []models.Transaction
+	err = h.db.WithContext(c.Request.Context()).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Scopes(scope).Where("user_id = ?", userID).Find(&transactions).Error; err != nil {
+			return apierror.Internal(err, "Failed to load transactions")
+		}
+		if missing := missingRef(refs, transactions); missing != "" {
+			return apierror.NotFound("Transaction " + missing + " not found")
+		}
+
+		ids := make([]uint, len(transactions))
+		for i := range transactions {
+			ids[i] = transactions[i].ID
+		}
+		if err := h.tagService.TagTransactions(tx, userID, ids, req.Add, req.Remove); err != nil {
+			return apierror.Internal(err, "Failed to tag transactions")
+		}
+
+		if err := tx.Preload("Tags").Where("id IN ?", ids).Order("id").Find(&transactions).Error; err != nil {
+			return apierror.Internal(err, "Failed to load transactions")
+		}
+		for i := range transactions {
+			if err := h.webhooks.Publish(tx, userID, services.EventTransactionUpdated, &transactions[i]); err != nil {
+				return apierror.Internal(err, "Failed to tag transactions")
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": transactions})
+}
+
+// missingRef returns the first ref that matches none of transactions, or ""
+// when every ref was found.
+func missingRef(refs []string, transactions []models.Transaction) string {
+	publicIDs := make(map[string]bool, len(transactions))
+	ids := make(map[uint]bool, len(transactions))
+	for _, transaction := range transactions {
+		publicIDs[transaction.PublicID] = true
+		ids[transaction.ID] = true
+	}
+
+	for _, ref := range refs {
+		ref = strings.TrimSpace(ref)
+		if publicid.Valid(ref) {
+			if !publicIDs[strings.ToUpper(ref)] {
+				return ref
+			}
+			continue
+		}
+		if id, err := strconv.ParseUint(ref, 10, strconv.IntSize); err != nil || !ids[uint(id)] {
+			return ref
+		}
+	}
+	return ""
+}
+
+func respondTagError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrTagNotFound):
+		apierror.Respond(c, apierror.NotFound("Tag not found"))
+	case errors.Is(err, services.ErrDuplicateTag):
+		apierror.Respond(c, apierror.Conflict(err.Error()))
+	case errors.Is(err, services.ErrInvalidTag):
+		apierror.Respond(c, apierror.BadRequest(err.Error()))
+	default:
+		apierror.Respond(c, apierror.Internal(err, "Failed to process tag"))
+	}
+}
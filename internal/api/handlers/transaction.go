@@ -2,61 +2,326 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
+	"finbro-backend-go/internal/api/middleware"
+	"finbro-backend-go/internal/apierror"
 	"finbro-backend-go/internal/db"
 	"finbro-backend-go/internal/db/models"
+	"finbro-backend-go/internal/metrics"
+	"finbro-backend-go/internal/publicid"
+	"finbro-backend-go/internal/services"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 type TransactionHandler struct {
-	db *db.DB
+	db                 *db.DB
+	transactionService *services.TransactionService
+	categoryService    *services.CategoryService
+	ruleService        *services.RuleService
+	categorizer        *services.CategorizerService
+	budgetService      *services.BudgetService
+	webhooks           *services.OutboundWebhookService
+	attachments        *services.AttachmentService
 }
 
-func NewTransactionHandler(db *db.DB) *TransactionHandler {
-	return &TransactionHandler{db: db}
+func NewTransactionHandler(
+	db *db.DB,
+	transactionService *services.TransactionService,
+	categoryService *services.CategoryService,
+	ruleService *services.RuleService,
+	categorizer *services.CategorizerService,
+	budgetService *services.BudgetService,
+	webhooks *services.OutboundWebhookService,
+	attachments *services.AttachmentService,
+) *TransactionHandler {
+	return &TransactionHandler{
+		db:                 db,
+		transactionService: transactionService,
+		categoryService:    categoryService,
+		ruleService:        ruleService,
+		categorizer:        categorizer,
+		budgetService:      budgetService,
+		webhooks:           webhooks,
+		attachments:        attachments,
+	}
 }
 
 type CreateTransactionRequest struct {
-	AccountID       uint      `json:"account_id" binding:"required"`
-	Amount          float64   `json:"amount" binding:"required"`
-	Description     string    `json:"description"`
-	Category        string    `json:"category"`
-	Type            string    `json:"type" binding:"required,oneof=debit credit"`
-	TransactionDate time.Time `json:"transaction_date"`
+	AccountID       publicid.Ref   `json:"account_id" binding:"required"`
+	Amount          float64        `json:"amount" binding:"required,amount"`
+	Description     string         `json:"description"`
+	Notes           string         `json:"notes" binding:"max=2000"`
+	CategoryID      *uint          `json:"category_id"`
+	Category        string         `json:"category"` // legacy: resolved to a category by name
+	Type            string         `json:"type" binding:"required,oneof=debit credit"`
+	TransactionDate time.Time      `json:"transaction_date"`
+	Splits          []SplitRequest `json:"splits" binding:"omitempty,max=50,dive"` // optional; must add up to amount
+	Tags            []string       `json:"tags" binding:"omitempty,max=20,dive,max=50"`
 }
 
 func (h *TransactionHandler) GetTransactions(c *gin.Context) {
 	userID, _ := c.Get("user_id")
 
-	// Query parameters
-	accountID := c.Query("account_id")
-	category := c.Query("category")
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
-	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	filter, err := parseTransactionFilter(c)
+	if err != nil {
+		apierror.Respond(c, apierror.BadRequest(err.Error()))
+		return
+	}
+	filter.UserID = userID.(uint)
+
+	if ref := c.Query("account_id"); ref != "" {
+		account, ok := h.findAccount(c, ref)
+		if !ok {
+			return
+		}
+		filter.AccountID = account.ID
+	}
+
+	page, err := h.transactionService.GetTransactions(filter)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidCursor) || errors.Is(err, services.ErrInvalidSort) {
+			apierror.Respond(c, apierror.BadRequest(err.Error()))
+			return
+		}
+		apierror.Respond(c, apierror.Internal(err, "Failed to fetch transactions"))
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+func (h *TransactionHandler) SearchTransactions(c *gin.Context) {
+	userID, _ := c.Get("user_id")
 
-	query := h.db.Where("user_id = ?", userID)
+	opts := services.SearchOptions{}
+	if ref := c.Query("account_id"); ref != "" {
+		account, ok := h.findAccount(c, ref)
+		if !ok {
+			return
+		}
+		opts.AccountID = account.ID
+	}
+	if limit := c.Query("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 1 {
+			apierror.Respond(c, apierror.BadRequest("invalid limit"))
+			return
+		}
+		opts.Limit = n
+	}
 
-	if accountID != "" {
-		query = query.Where("account_id = ?", accountID)
+	results, err := h.transactionService.SearchTransactions(userID.(uint), c.Query("q"), opts)
+	if err != nil {
+		if errors.Is(err, services.ErrEmptySearch) {
+			apierror.Respond(c, apierror.BadRequest(err.Error()))
+			return
+		}
+		apierror.Respond(c, apierror.Internal(err, "Failed to search transactions"))
+		return
 	}
-	if category != "" {
-		query = query.Where("category = ?", category)
+
+	c.JSON(http.StatusOK, gin.H{"data": results})
+}
+
+// GetSuggestions returns the most likely categories for a transaction based
+// on how the user categorized similar transactions before.
+func (h *TransactionHandler) GetSuggestions(c *gin.Context) {
+	transaction := middleware.CurrentTransaction(c)
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "3"))
+	if err != nil || limit < 1 || limit > 10 {
+		apierror.Respond(c, apierror.BadRequest("limit must be between 1 and 10"))
+		return
 	}
 
-	var transactions []models.Transaction
-	if err := query.Preload("Account").
-		Order("transaction_date DESC").
-		Limit(limit).Offset(offset).
-		Find(&transactions).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch transactions"})
+	suggestions, err := h.categorizer.Suggest(transaction, limit)
+	if err != nil {
+		apierror.Respond(c, apierror.Internal(err, "Failed to compute suggestions"))
 		return
 	}
 
-	c.JSON(http.StatusOK, transactions)
+	c.JSON(http.StatusOK, gin.H{"data": suggestions})
+}
+
+// findAccount resolves an account reference from a query or body to one of
+// the user's accounts, responding with 400 or 404 when it cannot.
+func (h *TransactionHandler) findAccount(c *gin.Context, ref string) (*models.Account, bool) {
+	account, err := lookupAccount(h.db.WithContext(c.Request.Context()), c.GetUint("user_id"), ref)
+	if err != nil {
+		apierror.Respond(c, err)
+		return nil, false
+	}
+	return account, true
+}
+
+// lookupAccount resolves an account reference to one of the user's
+// accounts. Its errors are API errors.
+func lookupAccount(tx *gorm.DB, userID uint, ref string) (*models.Account, error) {
+	scope, err := publicid.Scope(ref)
+	if err != nil {
+		return nil, apierror.BadRequest("invalid account_id")
+	}
+
+	var account models.Account
+	err = tx.Scopes(scope).Where("user_id = ?", userID).First(&account).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, apierror.NotFound("Account not found")
+	}
+	if err != nil {
+		return nil, apierror.Internal(err, "Failed to load account")
+	}
+	return &account, nil
+}
+
+// applyCategory points the transaction at a category given either by ID or,
+// for older clients, by name.
+func (h *TransactionHandler) applyCategory(tx *gorm.DB, transaction *models.Transaction, categoryID *uint, name string) error {
+	category, err := h.resolveCategory(tx, transaction.UserID, categoryID, name)
+	if err != nil || category == nil {
+		return err
+	}
+
+	transaction.CategoryID = &category.ID
+	transaction.Category = category.Name
+	return nil
+}
+
+// resolveCategory looks a category up by ID or by name, returning nil when
+// neither is given. Both lookups read through tx, and a name that matches
+// nothing creates a category in it.
+func (h *TransactionHandler) resolveCategory(tx *gorm.DB, userID uint, categoryID *uint, name string) (*models.Category, error) {
+	switch {
+	case categoryID != nil:
+		return h.categoryService.GetCategoryByID(tx, *categoryID, userID)
+	case strings.TrimSpace(name) != "":
+		return h.categoryService.ResolveByName(tx, userID, name)
+	default:
+		return nil, nil
+	}
+}
+
+// parseTransactionFilter reads the listing query parameters. Categories and
+// tags may be repeated (?tag=a&tag=b) or comma separated.
+func parseTransactionFilter(c *gin.Context) (services.TransactionFilter, error) {
+	filter := services.TransactionFilter{
+		Type:         strings.ToLower(c.Query("type")),
+		Search:       strings.TrimSpace(c.Query("q")),
+		SortBy:       c.DefaultQuery("sort", services.SortByDate),
+		Cursor:       c.Query("cursor"),
+		IncludeTotal: c.Query("include_total") == "true",
+	}
+
+	for _, raw := range c.QueryArray("category") {
+		for _, category := range strings.Split(raw, ",") {
+			if category = strings.TrimSpace(category); category != "" {
+				filter.Categories = append(filter.Categories, category)
+			}
+		}
+	}
+
+	for _, raw := range c.QueryArray("category_id") {
+		for _, value := range strings.Split(raw, ",") {
+			id, err := strconv.ParseUint(strings.TrimSpace(value), 10, 64)
+			if err != nil {
+				return filter, fmt.Errorf("invalid category_id")
+			}
+			filter.CategoryIDs = append(filter.CategoryIDs, uint(id))
+		}
+	}
+
+	for _, raw := range c.QueryArray("tag") {
+		for _, tag := range strings.Split(raw, ",") {
+			if tag = services.NormalizeTagName(tag); tag != "" {
+				filter.Tags = append(filter.Tags, tag)
+			}
+		}
+	}
+
+	for _, raw := range c.QueryArray("tag_id") {
+		for _, value := range strings.Split(raw, ",") {
+			id, err := strconv.ParseUint(strings.TrimSpace(value), 10, 64)
+			if err != nil {
+				return filter, fmt.Errorf("invalid tag_id")
+			}
+			filter.TagIDs = append(filter.TagIDs, uint(id))
+		}
+	}
+
+	if filter.Type != "" && filter.Type != "debit" && filter.Type != "credit" {
+		return filter, fmt.Errorf("type must be debit or credit")
+	}
+
+	switch order := c.DefaultQuery("order", "desc"); order {
+	case "asc":
+		filter.Ascending = true
+	case "desc":
+	default:
+		return filter, fmt.Errorf("order must be asc or desc")
+	}
+
+	if limit := c.Query("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 1 {
+			return filter, fmt.Errorf("invalid limit")
+		}
+		filter.Limit = n
+	}
+
+	var err error
+	if filter.StartDate, err = parseDateParam(c.Query("start_date"), false); err != nil {
+		return filter, fmt.Errorf("invalid start_date")
+	}
+	if filter.EndDate, err = parseDateParam(c.Query("end_date"), true); err != nil {
+		return filter, fmt.Errorf("invalid end_date")
+	}
+
+	if filter.MinAmount, err = parseAmountParam(c.Query("min_amount")); err != nil {
+		return filter, fmt.Errorf("invalid min_amount")
+	}
+	if filter.MaxAmount, err = parseAmountParam(c.Query("max_amount")); err != nil {
+		return filter, fmt.Errorf("invalid max_amount")
+	}
+
+	return filter, nil
+}
+
+// parseDateParam accepts RFC 3339 timestamps or plain dates. A plain end date
+// covers the whole day.
+func parseDateParam(value string, endOfDay bool) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if endOfDay {
+		t = t.Add(24*time.Hour - time.Nanosecond)
+	}
+	return t, nil
+}
+
+func parseAmountParam(value string) (*float64, error) {
+	if value == "" {
+		return nil, nil
+	}
+	amount, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil, err
+	}
+	return &amount, nil
 }
 
 func (h *TransactionHandler) CreateTransaction(c *gin.Context) {
@@ -64,22 +329,53 @@ func (h *TransactionHandler) CreateTransaction(c *gin.Context) {
 
 	var req CreateTransactionRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.Respond(c, err)
 		return
 	}
 
-	// Verify account belongs to user
-	var account models.Account
-	if err := h.db.Where("id = ? AND user_id = ?", req.AccountID, userID).First(&account).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Account not found"})
+	var transaction *models.Transaction
+	err := h.db.WithContext(c.Request.Context()).Transaction(func(tx *gorm.DB) error {
+		rules, err := h.ruleService.LoadRules(tx, userID.(uint))
+		if err != nil {
+			return apierror.Internal(err, "Failed to apply rules")
+		}
+		if transaction, err = h.newTransaction(tx, userID.(uint), rules, &req); err != nil {
+			return err
+		}
+		if err := h.insertTransaction(tx, transaction); err != nil {
+			return err
+		}
+		if err := services.AdjustBalance(tx, transaction.AccountID, services.BalanceEffect(transaction.Amount, transaction.Type)); err != nil {
+			return apierror.Internal(err, "Failed to update balance")
+		}
+		return nil
+	})
+	if err != nil {
+		apierror.Respond(c, err)
 		return
 	}
+	metrics.TransactionsCreated.WithLabelValues("api").Inc()
+
+	c.Header("ETag", middleware.ETag(transaction.Version))
+	c.JSON(http.StatusCreated, transaction)
+}
+
+// newTransaction builds a transaction from a create request: it resolves the
+// account, then runs the user's rules, the requested category and the
+// categorizer, in that order. Its errors are API errors.
+func (h *TransactionHandler) newTransaction(tx *gorm.DB, userID uint, rules *services.RuleSet, req *CreateTransactionRequest) (*models.Transaction, error) {
+	account, err := lookupAccount(tx, userID, req.AccountID.String())
+	if err != nil {
+		return nil, err
+	}
 
 	transaction := &models.Transaction{
-		UserID:          userID.(uint),
-		AccountID:       req.AccountID,
+		UserID:          userID,
+		AccountID:       account.ID,
+		AccountPublicID: account.PublicID,
 		Amount:          req.Amount,
 		Description:     req.Description,
+		Notes:           req.Notes,
 		Category:        req.Category,
 		Type:            req.Type,
 		TransactionDate: req.TransactionDate,
@@ -89,80 +385,290 @@ func (h *TransactionHandler) CreateTransaction(c *gin.Context) {
 		transaction.TransactionDate = time.Now()
 	}
 
-	// Start transaction to update account balance
-	tx := h.db.Begin()
+	if err := rules.Apply(tx, transaction); err != nil {
+		return nil, apierror.Internal(err, "Failed to apply rules")
+	}
 
-	if err := tx.Create(transaction).Error; err != nil {
-		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create transaction"})
-		return
+	tags, err := services.EnsureTags(tx, userID, req.Tags)
+	if err != nil {
+		return nil, apierror.Internal(err, "Failed to save tags")
 	}
+	transaction.Tags = services.MergeTags(transaction.Tags, tags)
 
-	// Update account balance
-	balanceChange := req.Amount
-	if req.Type == "debit" {
-		balanceChange = -balanceChange
+	// An explicit category in the request wins over one set by a rule.
+	if err := h.applyCategory(tx, transaction, req.CategoryID, req.Category); err != nil {
+		return nil, categoryError(err)
 	}
 
-	if err := tx.Model(&account).Update("balance", account.Balance+balanceChange).Error; err != nil {
-		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update balance"})
-		return
+	// The categorizer only reads committed history, so it needs no tx.
+	if _, err := h.categorizer.AutoCategorize(transaction); err != nil {
+		return nil, apierror.Internal(err, "Failed to categorize transaction")
 	}
 
-	tx.Commit()
-	c.JSON(http.StatusCreated, transaction)
+	if len(req.Splits) > 0 {
+		splits, err := h.newSplits(tx, userID, transaction.Amount, req.Splits)
+		if err != nil {
+			return nil, err
+		}
+		transaction.Splits = splits
+	}
+	return transaction, nil
 }
 
-func (h *TransactionHandler) GetTransaction(c *gin.Context) {
-	userID, _ := c.Get("user_id")
-	transactionID, _ := strconv.Atoi(c.Param("id"))
+// insertTransaction saves a new transaction, records it against the user's
+// budgets and publishes the resulting events. The caller owns the balance
+// update. Its errors are API errors.
+func (h *TransactionHandler) insertTransaction(tx *gorm.DB, transaction *models.Transaction) error {
+	if err := tx.Create(transaction).Error; err != nil {
+		return apierror.Internal(err, "Failed to create transaction")
+	}
 
-	var transaction models.Transaction
-	if err := h.db.Preload("Account").
-		Where("id = ? AND user_id = ?", transactionID, userID).
-		First(&transaction).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Transaction not found"})
-		return
+	if err := h.webhooks.Publish(tx, transaction.UserID, services.EventTransactionCreated, transaction); err != nil {
+		return apierror.Internal(err, "Failed to create transaction")
+	}
+	if err := h.budgetService.RecordSpending(tx, transaction); err != nil {
+		return apierror.Internal(err, "Failed to update budgets")
 	}
+	return nil
+}
 
-	c.JSON(http.StatusOK, transaction)
+func (h *TransactionHandler) GetTransaction(c *gin.Context) {
+	c.JSON(http.StatusOK, middleware.CurrentTransaction(c))
+}
+
+// UpdateTransactionRequest replaces a transaction's fields. Splits and tags
+// have their own endpoints and are left as they are.
+type UpdateTransactionRequest struct {
+	AccountID       publicid.Ref `json:"account_id" binding:"required"`
+	Amount          float64      `json:"amount" binding:"required,amount"`
+	Description     string       `json:"description"`
+	Notes           string       `json:"notes" binding:"max=2000"`
+	CategoryID      *uint        `json:"category_id"`
+	Category        string       `json:"category"` // legacy: resolved to a category by name
+	Type            string       `json:"type" binding:"required,oneof=debit credit"`
+	TransactionDate time.Time    `json:"transaction_date"` // kept when omitted
 }
 
+// UpdateTransaction replaces a transaction. It is a merge patch that sets
+// every field, so the balance and budgets move as they do for PATCH.
 func (h *TransactionHandler) UpdateTransaction(c *gin.Context) {
-	userID, _ := c.Get("user_id")
-	transactionID, _ := strconv.Atoi(c.Param("id"))
+	transaction := middleware.CurrentTransaction(c)
 
-	var req CreateTransactionRequest
+	var req UpdateTransactionRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.Respond(c, err)
 		return
 	}
 
-	var transaction models.Transaction
-	if err := h.db.Where("id = ? AND user_id = ?", transactionID, userID).First(&transaction).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Transaction not found"})
-		return
+	patch := &PatchTransactionRequest{
+		AccountID:   &req.AccountID,
+		Amount:      &req.Amount,
+		Type:        &req.Type,
+		Description: &req.Description,
+		Notes:       &req.Notes,
+		CategoryID:  req.CategoryID,
+		Category:    &req.Category,
+	}
+	if !req.TransactionDate.IsZero() {
+		patch.TransactionDate = &req.TransactionDate
 	}
+	h.updateTransaction(c, transaction, patch, mergePatch{})
+}
+
+// PatchTransactionRequest is a JSON Merge Patch: absent fields are left
+// alone and category_id or category set to null clears the category.
+type PatchTransactionRequest struct {
+	AccountID       *publicid.Ref `json:"account_id"`
+	Amount          *float64      `json:"amount" binding:"omitempty,amount"`
+	Type            *string       `json:"type" binding:"omitempty,oneof=debit credit"`
+	Description     *string       `json:"description"`
+	Notes           *string       `json:"notes" binding:"omitempty,max=2000"`
+	CategoryID      *uint         `json:"category_id"`
+	Category        *string       `json:"category"`
+	TransactionDate *time.Time    `json:"transaction_date"`
+	Tags            *[]string     `json:"tags" binding:"omitempty,max=20,dive,max=50"` // replaces the tag set
+}
 
-	transaction.Description = req.Description
-	transaction.Category = req.Category
-	transaction.TransactionDate = req.TransactionDate
+// PatchTransaction applies a partial update. Changing the amount, type or
+// account moves the balance effect accordingly: a changed amount adjusts
+// the account by the difference, and a move takes the old effect off the
+// old account and applies the new one to the new account. Budgets move the
+// same way when the amount, type, category or date changes.
+func (h *TransactionHandler) PatchTransaction(c *gin.Context) {
+	transaction := middleware.CurrentTransaction(c)
+
+	var req PatchTransactionRequest
+	patch, ok := bindMergePatch(c, &req, "account_id", "amount", "type", "transaction_date")
+	if !ok {
+		return
+	}
+	h.updateTransaction(c, transaction, &req, patch)
+}
 
-	if err := h.db.Save(&transaction).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update transaction"})
+// updateTransaction applies a validated merge patch, writes the changed
+// columns and moves the balance and budgets, then responds with the
+// transaction.
+func (h *TransactionHandler) updateTransaction(c *gin.Context, transaction *models.Transaction, req *PatchTransactionRequest, patch mergePatch) {
+	previous := *transaction
+	changed := false
+	err := h.db.WithContext(c.Request.Context()).Transaction(func(tx *gorm.DB) error {
+		columns, err := h.applyPatch(tx, transaction, req, patch)
+		if err != nil || len(columns) == 0 {
+			return err
+		}
+		changed = true
+
+		if err := db.UpdateVersioned(tx, transaction, &transaction.Version, columns...); err != nil {
+			return err
+		}
+		if err := saveTags(tx, transaction, patch); err != nil {
+			return apierror.Internal(err, "Failed to save tags")
+		}
+
+		changes := services.BalanceChanges{}
+		changes.Remove(&previous)
+		changes.Add(transaction)
+		if err := changes.Apply(tx); err != nil {
+			return apierror.Internal(err, "Failed to update balance")
+		}
+		if err := h.budgetService.UpdateSpending(tx, &previous, transaction); err != nil {
+			return apierror.Internal(err, "Failed to update budgets")
+		}
+
+		if err := h.webhooks.Publish(tx, transaction.UserID, services.EventTransactionUpdated, transaction); err != nil {
+			return apierror.Internal(err, "Failed to update transaction")
+		}
+		return nil
+	})
+	if errors.Is(err, db.ErrVersionConflict) {
+		apierror.Respond(c, apierror.PreconditionFailed("Transaction has been modified"))
+		return
+	}
+	if err != nil {
+		apierror.Respond(c, err)
 		return
 	}
+	if changed {
+		h.categorizer.Invalidate(transaction.UserID)
+	}
 
+	c.Header("ETag", middleware.ETag(transaction.Version))
 	c.JSON(http.StatusOK, transaction)
 }
 
-func (h *TransactionHandler) DeleteTransaction(c *gin.Context) {
-	userID, _ := c.Get("user_id")
-	transactionID, _ := strconv.Atoi(c.Param("id"))
+// applyPatch applies a validated merge patch to transaction in memory and
+// returns the columns it changed. A new tag set is only saved by saveTags.
+// Its errors are API errors.
+func (h *TransactionHandler) applyPatch(tx *gorm.DB, transaction *models.Transaction, req *PatchTransactionRequest, patch mergePatch) ([]string, error) {
+	columns := []string{}
+
+	if req.AccountID != nil {
+		account, err := lookupAccount(tx, transaction.UserID, req.AccountID.String())
+		if err != nil {
+			return nil, err
+		}
+		transaction.AccountID = account.ID
+		transaction.AccountPublicID = account.PublicID
+		columns = append(columns, "account_id", "account_public_id")
+	}
+	if req.Amount != nil {
+		if *req.Amount != transaction.Amount {
+			var splits int64
+			if err := tx.Model(&models.TransactionSplit{}).Where("transaction_id = ?", transaction.ID).Count(&splits).Error; err != nil {
+				return nil, apierror.Internal(err, "Failed to load splits")
+			}
+			if splits > 0 {
+				return nil, apierror.Unprocessable("Transaction is split; change its amount together with its splits")
+			}
+		}
+		transaction.Amount = *req.Amount
+		columns = append(columns, "amount")
+	}
+	if req.Type != nil {
+		transaction.Type = *req.Type
+		columns = append(columns, "type")
+	}
+	if req.Description != nil || patch.isNull("description") {
+		transaction.Description = ""
+		if req.Description != nil {
+			transaction.Description = *req.Description
+		}
+		columns = append(columns, "description", "merchant")
+	}
+	if req.Notes != nil || patch.isNull("notes") {
+		transaction.Notes = ""
+		if req.Notes != nil {
+			transaction.Notes = *req.Notes
+		}
+		columns = append(columns, "notes")
+	}
+	if req.TransactionDate != nil {
+		transaction.TransactionDate = *req.TransactionDate
+		columns = append(columns, "transaction_date")
+	}
+	if req.Tags != nil || patch.isNull("tags") {
+		var names []string
+		if req.Tags != nil {
+			names = *req.Tags
+		}
+		tags, err := services.EnsureTags(tx, transaction.UserID, names)
+		if err != nil {
+			return nil, apierror.Internal(err, "Failed to save tags")
+		}
+		transaction.Tags = tags
+		// Tags live in a join table; touching the row moves its version.
+		columns = append(columns, "updated_at")
+	}
+
+	if req.CategoryID != nil || req.Category != nil || patch.isNull("category_id") || patch.isNull("category") {
+		category := ""
+		if req.Category != nil {
+			category = *req.Category
+		}
+		transaction.CategoryID = nil
+		transaction.Category = ""
+		if err := h.applyCategory(tx, transaction, req.CategoryID, category); err != nil {
+			return nil, categoryError(err)
+		}
+		columns = append(columns, "category_id", "category")
+	}
 
-	if err := h.db.Where("id = ? AND user_id = ?", transactionID, userID).Delete(&models.Transaction{}).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete transaction"})
+	return columns, nil
+}
+
+// saveTags replaces the transaction's tags with those set by applyPatch, if
+// the patch changed them.
+func saveTags(tx *gorm.DB, transaction *models.Transaction, patch mergePatch) error {
+	if _, ok := patch["tags"]; !ok {
+		return nil
+	}
+	association := tx.Model(transaction).Association("Tags")
+	if len(transaction.Tags) == 0 {
+		return association.Clear()
+	}
+	return association.Replace(transaction.Tags)
+}
+
+func (h *TransactionHandler) DeleteTransaction(c *gin.Context) {
+	transaction := middleware.CurrentTransaction(c)
+
+	err := h.db.WithContext(c.Request.Context()).Transaction(func(tx *gorm.DB) error {
+		if err := h.attachments.ReleaseForTransactions(tx, []uint{transaction.ID}); err != nil {
+			return err
+		}
+		if err := h.budgetService.ReleaseSpending(tx, transaction); err != nil {
+			return err
+		}
+		if err := tx.Delete(transaction).Error; err != nil {
+			return err
+		}
+		if err := services.AdjustBalance(tx, transaction.AccountID, -services.BalanceEffect(transaction.Amount, transaction.Type)); err != nil {
+			return err
+		}
+		return h.webhooks.Publish(tx, transaction.UserID, services.EventTransactionDeleted, transaction)
+	})
+	if err != nil {
+		apierror.Respond(c, apierror.Internal(err, "Failed to delete transaction"))
 		return
 	}
 
@@ -0,0 +1,366 @@
+// internal/api/handlers/transaction_bulk.go
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"finbro-backend-go/internal/apierror"
+	"finbro-backend-go/internal/db"
+	"finbro-backend-go/internal/db/models"
+	"finbro-backend-go/internal/logging"
+	"finbro-backend-go/internal/metrics"
+	"finbro-backend-go/internal/publicid"
+	"finbro-backend-go/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"gorm.io/gorm"
+)
+
+// MaxBulkOperations caps how many operations one bulk request may carry.
+const MaxBulkOperations = 500
+
+const (
+	// BulkModeAtomic applies every operation or none of them.
+	BulkModeAtomic = "atomic"
+	// BulkModePartial applies each operation on its own and reports a
+	// result per operation.
+	BulkModePartial = "partial"
+)
+
+const (
+	bulkOpCreate       = "create"
+	bulkOpUpdate       = "update"
+	bulkOpDelete       = "delete"
+	bulkOpRecategorize = "recategorize"
+	bulkOpMove         = "move"
+)
+
+// bulkPatchFields lists the merge patch members each patching operation
+// accepts; nil means any member of PatchTransactionRequest.
+var bulkPatchFields = map[string][]string{
+	bulkOpUpdate:       nil,
+	bulkOpRecategorize: {"category_id", "category"},
+	bulkOpMove:         {"account_id"},
+}
+
+type BulkTransactionRequest struct {
+	Mode       string          `json:"mode" binding:"omitempty,oneof=atomic partial"`
+	Operations []BulkOperation `json:"operations" binding:"required,min=1,dive"`
+}
+
+// BulkOperation is one step of a bulk request. ID names the target
+// transaction for everything but create, and Version, when set, must match
+// the target's current version. Data is a CreateTransactionRequest for
+// create and a merge patch for update, recategorize and move.
+type BulkOperation struct {
+	Op      string          `json:"op" binding:"required,oneof=create update delete recategorize move"`
+	ID      string          `json:"id"`
+	Version *int            `json:"version"`
+	Data    json.RawMessage `json:"data"`
+}
+
+type BulkOperationResult struct {
+	Index       int                 `json:"index"`
+	Op          string              `json:"op"`
+	Status      int                 `json:"status"`
+	Transaction *models.Transaction `json:"transaction,omitempty"`
+	Error       *BulkOperationError `json:"error,omitempty"`
+}
+
+type BulkOperationError struct {
+	Code   apierror.Code         `json:"code"`
+	Detail string                `json:"detail"`
+	Errors []apierror.FieldError `json:"errors,omitempty"`
+}
+
+type BulkTransactionResponse struct {
+	Mode      string                `json:"mode"`
+	Committed bool                  `json:"committed"`
+	Succeeded int                   `json:"succeeded"`
+	Failed    int                   `json:"failed"`
+	Results   []BulkOperationResult `json:"results"`
+}
+
+// BulkTransactions applies a batch of create, update, delete, recategorize
+// and move operations in order, inside one database transaction. Balance
+// effects are collected along the way and each affected account is updated
+// once at the end.
+//
+// In atomic mode (the default) the first failing operation rolls back the
+// whole batch: the response is 422 with that operation's error, and every
+// other operation is reported as 424 Failed Dependency. In partial mode each
+// operation runs in its own savepoint, so failures only undo themselves and
+// the response is 200 with a result per operation.
+func (h *TransactionHandler) BulkTransactions(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	var req BulkTransactionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	if len(req.Operations) > MaxBulkOperations {
+		apierror.Respond(c, apierror.Validation("The request contains invalid fields", apierror.FieldError{
+			Field:   "operations",
+			Code:    "max",
+			Message: fmt.Sprintf("must contain at most %d items", MaxBulkOperations),
+		}))
+		return
+	}
+	if req.Mode == "" {
+		req.Mode = BulkModeAtomic
+	}
+
+	resp := BulkTransactionResponse{
+		Mode:    req.Mode,
+		Results: make([]BulkOperationResult, len(req.Operations)),
+	}
+	for i, op := range req.Operations {
+		resp.Results[i] = BulkOperationResult{Index: i, Op: op.Op, Status: http.StatusFailedDependency}
+	}
+
+	ctx := c.Request.Context()
+	created := 0
+	var failure *apierror.Error
+
+	err := h.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		changes := services.BalanceChanges{}
+		// Creates share one compiled copy of the user's rules.
+		rules, err := h.ruleService.LoadRules(tx, userID)
+		if err != nil {
+			return apierror.Internal(err, "Failed to apply rules")
+		}
+
+		for i, op := range req.Operations {
+			opChanges := services.BalanceChanges{}
+			var (
+				status      int
+				transaction *models.Transaction
+			)
+			run := func(tx *gorm.DB) error {
+				var err error
+				status, transaction, err = h.runBulkOperation(tx, userID, rules, op, opChanges)
+				return err
+			}
+
+			var err error
+			if req.Mode == BulkModePartial {
+				err = tx.Transaction(run)
+			} else {
+				err = run(tx)
+			}
+			if err != nil {
+				apiErr := apierror.From(err)
+				resp.Results[i].Status = apiErr.Status
+				resp.Results[i].Error = &BulkOperationError{Code: apiErr.Code, Detail: apiErr.Detail, Errors: apiErr.Fields}
+				resp.Failed++
+				if req.Mode == BulkModeAtomic {
+					failure = apiErr
+					return apiErr
+				}
+				if apiErr.Status >= http.StatusInternalServerError {
+					logging.FromContext(ctx).Error(apiErr.Detail, "code", apiErr.Code, "index", i, "error", apiErr.Err)
+				}
+				continue
+			}
+
+			resp.Results[i].Status = status
+			resp.Results[i].Transaction = transaction
+			resp.Succeeded++
+			if op.Op == bulkOpCreate {
+				created++
+			}
+			changes.Merge(opChanges)
+		}
+
+		if err := changes.Apply(tx); err != nil {
+			return apierror.Internal(err, "Failed to update balances")
+		}
+		return nil
+	})
+	// A server error aborts the batch like any other failure, but is
+	// reported as the server error it is.
+	if failure != nil && failure.Status < http.StatusInternalServerError {
+		resp.Succeeded = 0
+		for i := range resp.Results {
+			resp.Results[i].Transaction = nil
+			if resp.Results[i].Error == nil {
+				resp.Results[i].Status = http.StatusFailedDependency
+			}
+		}
+		c.JSON(http.StatusUnprocessableEntity, resp)
+		return
+	}
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	resp.Committed = true
+	if created > 0 {
+		metrics.TransactionsCreated.WithLabelValues("bulk").Add(float64(created))
+	}
+	if resp.Succeeded > created {
+		h.categorizer.Invalidate(userID)
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// runBulkOperation applies one operation and records its balance effect in
+// changes. It returns the status and transaction to report for it; its
+// errors are API errors.
+func (h *TransactionHandler) runBulkOperation(tx *gorm.DB, userID uint, rules *services.RuleSet, op BulkOperation, changes services.BalanceChanges) (int, *models.Transaction, error) {
+	if op.Op == bulkOpCreate {
+		var req CreateTransactionRequest
+		if err := binding.JSON.BindBody(op.Data, &req); err != nil {
+			return 0, nil, err
+		}
+		transaction, err := h.newTransaction(tx, userID, rules, &req)
+		if err != nil {
+			return 0, nil, err
+		}
+		if err := h.insertTransaction(tx, transaction); err != nil {
+			return 0, nil, err
+		}
+		changes.Add(transaction)
+		return http.StatusCreated, transaction, nil
+	}
+
+	transaction, err := lookupTransaction(tx, userID, op.ID)
+	if err != nil {
+		return 0, nil, err
+	}
+	if op.Version != nil && *op.Version != transaction.Version {
+		return 0, nil, apierror.PreconditionFailed("Transaction has been modified")
+	}
+
+	if op.Op == bulkOpDelete {
+		if err := h.attachments.ReleaseForTransactions(tx, []uint{transaction.ID}); err != nil {
+			return 0, nil, apierror.Internal(err, "Failed to delete transaction")
+		}
+		if err := h.budgetService.ReleaseSpending(tx, transaction); err != nil {
+			return 0, nil, apierror.Internal(err, "Failed to update budgets")
+		}
+		if err := tx.Delete(transaction).Error; err != nil {
+			return 0, nil, apierror.Internal(err, "Failed to delete transaction")
+		}
+		if err := h.webhooks.Publish(tx, userID, services.EventTransactionDeleted, transaction); err != nil {
+			return 0, nil, apierror.Internal(err, "Failed to delete transaction")
+		}
+		changes.Remove(transaction)
+		return http.StatusOK, transaction, nil
+	}
+
+	var req PatchTransactionRequest
+	patch, err := decodeBulkPatch(op, &req)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	previous := *transaction
+	columns, err := h.applyPatch(tx, transaction, &req, patch)
+	if err != nil {
+		return 0, nil, err
+	}
+	if len(columns) == 0 {
+		return http.StatusOK, transaction, nil
+	}
+
+	if err := db.UpdateVersioned(tx, transaction, &transaction.Version, columns...); err != nil {
+		if errors.Is(err, db.ErrVersionConflict) {
+			return 0, nil, apierror.PreconditionFailed("Transaction has been modified")
+		}
+		return 0, nil, apierror.Internal(err, "Failed to update transaction")
+	}
+	if err := saveTags(tx, transaction, patch); err != nil {
+		return 0, nil, apierror.Internal(err, "Failed to save tags")
+	}
+	if err := h.budgetService.UpdateSpending(tx, &previous, transaction); err != nil {
+		return 0, nil, apierror.Internal(err, "Failed to update budgets")
+	}
+	if err := h.webhooks.Publish(tx, userID, services.EventTransactionUpdated, transaction); err != nil {
+		return 0, nil, apierror.Internal(err, "Failed to update transaction")
+	}
+	changes.Remove(&previous)
+	changes.Add(transaction)
+	return http.StatusOK, transaction, nil
+}
+
+// decodeBulkPatch decodes the data of an update, recategorize or move
+// operation, rejecting members the operation does not accept.
+func decodeBulkPatch(op BulkOperation, req *PatchTransactionRequest) (mergePatch, error) {
+	if len(op.Data) == 0 {
+		return nil, apierror.Validation("The request contains invalid fields", apierror.FieldError{
+			Field: "data", Code: "required", Message: "is required",
+		})
+	}
+
+	patch, err := decodeMergePatch(op.Data, req, "account_id", "amount", "type", "transaction_date")
+	if err != nil {
+		return nil, err
+	}
+
+	allowed := bulkPatchFields[op.Op]
+	if allowed == nil {
+		return patch, nil
+	}
+
+	var fields []apierror.FieldError
+	for member := range patch {
+		if !slices.Contains(allowed, member) {
+			fields = append(fields, apierror.FieldError{
+				Field:   member,
+				Code:    "not_allowed",
+				Message: "cannot be changed by " + op.Op,
+			})
+		}
+	}
+	present := false
+	for _, member := range allowed {
+		if _, ok := patch[member]; ok {
+			present = true
+		}
+	}
+	if !present {
+		fields = append(fields, apierror.FieldError{
+			Field:   allowed[0],
+			Code:    "required",
+			Message: "one of " + strings.Join(allowed, ", ") + " is required",
+		})
+	}
+	if len(fields) > 0 {
+		return nil, apierror.Validation("The request contains invalid fields", fields...)
+	}
+	return patch, nil
+}
+
+// lookupTransaction resolves a transaction reference to one of the user's
+// transactions. Its errors are API errors.
+func lookupTransaction(tx *gorm.DB, userID uint, ref string) (*models.Transaction, error) {
+	if ref == "" {
+		return nil, apierror.Validation("The request contains invalid fields", apierror.FieldError{
+			Field: "id", Code: "required", Message: "is required",
+		})
+	}
+	scope, err := publicid.Scope(ref)
+	if err != nil {
+		return nil, apierror.BadRequest("invalid id")
+	}
+
+	var transaction models.Transaction
+	err = tx.Scopes(scope).Where("user_id = ?", userID).First(&transaction).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, apierror.NotFound("Transaction not found")
+	}
+	if err != nil {
+		return nil, apierror.Internal(err, "Failed to load transaction")
+	}
+	return &transaction, nil
+}
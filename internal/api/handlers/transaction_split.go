@@ -0,0 +1,174 @@
+// internal/api/handlers/transaction_split.go
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+
+	"finbro-backend-go/internal/api/middleware"
+	"finbro-backend-go/internal/apierror"
+	"finbro-backend-go/internal/db"
+	"finbro-backend-go/internal/db/models"
+	"finbro-backend-go/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type SplitRequest struct {
+	Amount     float64 `json:"amount" binding:"required,amount"`
+	CategoryID *uint   `json:"category_id"`
+	Category   string  `json:"category"` // legacy: resolved to a category by name
+	Memo       string  `json:"memo" binding:"max=255"`
+}
+
+// ReplaceSplitsRequest replaces every split of a transaction. Amount, when
+// set, changes the transaction's amount in the same step, so the amount and
+// the splits that must add up to it can be edited together.
+type ReplaceSplitsRequest struct {
+	Amount *float64       `json:"amount" binding:"omitempty,amount"`
+	Splits []SplitRequest `json:"splits" binding:"required,min=1,max=50,dive"`
+}
+
+func (h *TransactionHandler) GetSplits(c *gin.Context) {
+	transaction := middleware.CurrentTransaction(c)
+
+	var splits []models.TransactionSplit
+	if err := h.db.WithContext(c.Request.Context()).
+		Where("transaction_id = ?", transaction.ID).
+		Order("id").
+		Find(&splits).Error; err != nil {
+		apierror.Respond(c, apierror.Internal(err, "Failed to fetch splits"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": splits})
+}
+
+func (h *TransactionHandler) ReplaceSplits(c *gin.Context) {
+	transaction := middleware.CurrentTransaction(c)
+
+	var req ReplaceSplitsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	amount := transaction.Amount
+	if req.Amount != nil {
+		amount = *req.Amount
+	}
+	h.saveSplits(c, transaction, amount, req.Splits)
+}
+
+// DeleteSplits merges a split transaction back into a single line under its
+// own category.
+func (h *TransactionHandler) DeleteSplits(c *gin.Context) {
+	transaction := middleware.CurrentTransaction(c)
+	h.saveSplits(c, transaction, transaction.Amount, nil)
+}
+
+// saveSplits replaces the transaction's splits with ones built from reqs,
+// or merges them back into one line when reqs is empty.
+func (h *TransactionHandler) saveSplits(c *gin.Context, transaction *models.Transaction, amount float64, reqs []SplitRequest) {
+	err := h.db.WithContext(c.Request.Context()).Transaction(func(tx *gorm.DB) error {
+		var splits []models.TransactionSplit
+		if len(reqs) > 0 {
+			var err error
+			if splits, err = h.newSplits(tx, transaction.UserID, amount, reqs); err != nil {
+				return err
+			}
+		}
+		return h.replaceSplits(tx, transaction, amount, splits)
+	})
+	if errors.Is(err, db.ErrVersionConflict) {
+		apierror.Respond(c, apierror.PreconditionFailed("Transaction has been modified"))
+		return
+	}
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	h.categorizer.Invalidate(transaction.UserID)
+
+	c.Header("ETag", middleware.ETag(transaction.Version))
+	c.JSON(http.StatusOK, transaction)
+}
+
+// replaceSplits swaps a transaction's splits for new ones and sets its
+// amount, moving the balance and budgets by any difference. The
+// transaction's version is bumped even when the amount stays the same, so
+// its ETag changes with its splits.
+func (h *TransactionHandler) replaceSplits(tx *gorm.DB, transaction *models.Transaction, amount float64, splits []models.TransactionSplit) error {
+	previous := *transaction
+	if err := tx.Where("transaction_id = ?", transaction.ID).Order("id").Find(&previous.Splits).Error; err != nil {
+		return apierror.Internal(err, "Failed to load splits")
+	}
+
+	transaction.Amount = amount
+	transaction.Splits = nil
+	if err := db.UpdateVersioned(tx, transaction, &transaction.Version, "amount"); err != nil {
+		return err
+	}
+	balance := services.BalanceEffect(amount, transaction.Type) - services.BalanceEffect(previous.Amount, previous.Type)
+	if err := services.AdjustBalance(tx, transaction.AccountID, balance); err != nil {
+		return apierror.Internal(err, "Failed to update balance")
+	}
+
+	if err := tx.Where("transaction_id = ?", transaction.ID).Delete(&models.TransactionSplit{}).Error; err != nil {
+		return apierror.Internal(err, "Failed to update splits")
+	}
+	for i := range splits {
+		splits[i].TransactionID = transaction.ID
+	}
+	if len(splits) > 0 {
+		if err := tx.Create(&splits).Error; err != nil {
+			return apierror.Internal(err, "Failed to update splits")
+		}
+	}
+	transaction.Splits = splits
+
+	if err := h.webhooks.Publish(tx, transaction.UserID, services.EventTransactionUpdated, transaction); err != nil {
+		return apierror.Internal(err, "Failed to update transaction")
+	}
+	if err := h.budgetService.UpdateSpending(tx, &previous, transaction); err != nil {
+		return apierror.Internal(err, "Failed to update budgets")
+	}
+	return nil
+}
+
+// newSplits builds splits from a request, resolving their categories and
+// checking that they add up to amount to the cent. Its errors are API
+// errors.
+func (h *TransactionHandler) newSplits(tx *gorm.DB, userID uint, amount float64, reqs []SplitRequest) ([]models.TransactionSplit, error) {
+	splits := make([]models.TransactionSplit, len(reqs))
+	total := 0.0
+	for i, req := range reqs {
+		category, err := h.resolveCategory(tx, userID, req.CategoryID, req.Category)
+		if err != nil {
+			return nil, categoryError(err)
+		}
+
+		splits[i] = models.TransactionSplit{
+			UserID: userID,
+			Amount: req.Amount,
+			Memo:   req.Memo,
+		}
+		if category != nil {
+			splits[i].CategoryID = &category.ID
+			splits[i].Category = category.Name
+		}
+		total += req.Amount
+	}
+
+	if math.Round(total*100) != math.Round(amount*100) {
+		return nil, apierror.Validation("The request contains invalid fields", apierror.FieldError{
+			Field:   "splits",
+			Code:    "sum",
+			Message: fmt.Sprintf("must add up to the transaction amount %.2f, not %.2f", amount, total),
+		})
+	}
+	return splits, nil
+}
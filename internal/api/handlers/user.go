@@ -4,6 +4,7 @@ package handlers
 import (
 	"net/http"
 
+	"finbro-backend-go/internal/apierror"
 	"finbro-backend-go/internal/db"
 	"finbro-backend-go/internal/db/models"
 
@@ -19,16 +20,16 @@ func NewUserHandler(db *db.DB) *UserHandler {
 }
 
 type UpdateProfileRequest struct {
-	FirstName string `json:"first_name"`
-	LastName  string `json:"last_name"`
+	FirstName string `json:"first_name" binding:"omitempty,name"`
+	LastName  string `json:"last_name" binding:"omitempty,name"`
 }
 
 func (h *UserHandler) GetProfile(c *gin.Context) {
 	userID, _ := c.Get("user_id")
 
 	var user models.User
-	if err := h.db.Preload("Accounts").First(&user, userID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+	if err := h.db.WithContext(c.Request.Context()).Preload("Accounts").First(&user, userID).Error; err != nil {
+		apierror.Respond(c, apierror.NotFound("User not found"))
 		return
 	}
 
@@ -41,21 +42,21 @@ func (h *UserHandler) UpdateProfile(c *gin.Context) {
 
 	var req UpdateProfileRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.Respond(c, err)
 		return
 	}
 
 	var user models.User
-	if err := h.db.First(&user, userID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+	if err := h.db.WithContext(c.Request.Context()).First(&user, userID).Error; err != nil {
+		apierror.Respond(c, apierror.NotFound("User not found"))
 		return
 	}
 
 	user.FirstName = req.FirstName
 	user.LastName = req.LastName
 
-	if err := h.db.Save(&user).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update profile"})
+	if err := h.db.WithContext(c.Request.Context()).Save(&user).Error; err != nil {
+		apierror.Respond(c, apierror.Internal(err, "Failed to update profile"))
 		return
 	}
 
@@ -63,12 +64,56 @@ func (h *UserHandler) UpdateProfile(c *gin.Context) {
 	c.JSON(http.StatusOK, user)
 }
 
+// PatchProfileRequest is a JSON Merge Patch; a null name clears it.
+type PatchProfileRequest struct {
+	FirstName *string `json:"first_name" binding:"omitempty,name"`
+	LastName  *string `json:"last_name" binding:"omitempty,name"`
+}
+
+func (h *UserHandler) PatchProfile(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	var req PatchProfileRequest
+	patch, ok := bindMergePatch(c, &req)
+	if !ok {
+		return
+	}
+
+	var user models.User
+	if err := h.db.WithContext(c.Request.Context()).First(&user, userID).Error; err != nil {
+		apierror.Respond(c, apierror.NotFound("User not found"))
+		return
+	}
+
+	updates := map[string]interface{}{}
+	if req.FirstName != nil {
+		updates["first_name"] = *req.FirstName
+	} else if patch.isNull("first_name") {
+		updates["first_name"] = ""
+	}
+	if req.LastName != nil {
+		updates["last_name"] = *req.LastName
+	} else if patch.isNull("last_name") {
+		updates["last_name"] = ""
+	}
+
+	if len(updates) > 0 {
+		if err := h.db.WithContext(c.Request.Context()).Model(&user).Updates(updates).Error; err != nil {
+			apierror.Respond(c, apierror.Internal(err, "Failed to update profile"))
+			return
+		}
+	}
+
+	user.Password = ""
+	c.JSON(http.StatusOK, user)
+}
+
 func (h *UserHandler) DeleteAccount(c *gin.Context) {
 	userID, _ := c.Get("user_id")
 
 	// Delete user and related data (cascading)
-	if err := h.db.Delete(&models.User{}, userID).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete account"})
+	if err := h.db.WithContext(c.Request.Context()).Delete(&models.User{}, userID).Error; err != nil {
+		apierror.Respond(c, apierror.Internal(err, "Failed to delete account"))
 		return
 	}
 
@@ -0,0 +1,60 @@
+// internal/api/handlers/webhook.go
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"finbro-backend-go/internal/apierror"
+	"finbro-backend-go/internal/db"
+	"finbro-backend-go/internal/services"
+	"finbro-backend-go/internal/webhooks"
+
+	"github.com/gin-gonic/gin"
+)
+
+const maxWebhookBodySize = 1 << 20
+
+type WebhookHandler struct {
+	db                    *db.DB
+	inboundWebhookService *services.InboundWebhookService
+}
+
+func NewWebhookHandler(db *db.DB, inboundWebhookService *services.InboundWebhookService) *WebhookHandler {
+	return &WebhookHandler{
+		db:                    db,
+		inboundWebhookService: inboundWebhookService,
+	}
+}
+
+// Receive accepts a provider webhook. Verification and storage happen
+// inline; processing happens in the background.
+func (h *WebhookHandler) Receive(c *gin.Context) {
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxWebhookBodySize))
+	if err != nil {
+		apierror.Respond(c, apierror.PayloadTooLarge("Webhook body too large"))
+		return
+	}
+
+	duplicate, err := h.inboundWebhookService.Receive(c.Request.Context(), c.Param("provider"), c.Request.Header, body)
+	if err != nil {
+		switch {
+		case errors.Is(err, webhooks.ErrUnknownProvider):
+			apierror.Respond(c, apierror.NotFound("Unknown webhook provider"))
+		case errors.Is(err, webhooks.ErrInvalidSignature):
+			apierror.Respond(c, apierror.Unauthorized("Invalid webhook signature"))
+		case errors.Is(err, services.ErrInvalidWebhookPayload):
+			apierror.Respond(c, apierror.BadRequest("Invalid webhook payload"))
+		default:
+			apierror.Respond(c, apierror.Internal(err, "Failed to store webhook"))
+		}
+		return
+	}
+
+	if duplicate {
+		c.JSON(http.StatusOK, gin.H{"status": "duplicate"})
+		return
+	}
+	c.JSON(http.StatusAccepted, gin.H{"status": "accepted"})
+}
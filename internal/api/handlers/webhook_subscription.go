@@ -0,0 +1,194 @@
+// internal/api/handlers/webhook_subscription.go
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"finbro-backend-go/internal/api/middleware"
+	"finbro-backend-go/internal/apierror"
+	"finbro-backend-go/internal/db"
+	"finbro-backend-go/internal/db/models"
+	"finbro-backend-go/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type WebhookSubscriptionHandler struct {
+	db       *db.DB
+	webhooks *services.OutboundWebhookService
+}
+
+func NewWebhookSubscriptionHandler(db *db.DB, webhooks *services.OutboundWebhookService) *WebhookSubscriptionHandler {
+	return &WebhookSubscriptionHandler{
+		db:       db,
+		webhooks: webhooks,
+	}
+}
+
+type WebhookSubscriptionRequest struct {
+	URL         string   `json:"url" binding:"required"`
+	Description string   `json:"description"`
+	EventTypes  []string `json:"event_types" binding:"required"`
+	IsActive    *bool    `json:"is_active"`
+}
+
+func (r WebhookSubscriptionRequest) applyTo(sub *models.WebhookSubscription) {
+	sub.URL = r.URL
+	sub.Description = r.Description
+	sub.EventTypes = r.EventTypes
+	sub.IsActive = true
+	if r.IsActive != nil {
+		sub.IsActive = *r.IsActive
+	}
+}
+
+// createdSubscription exposes the signing secret, which is only shown once.
+type createdSubscription struct {
+	*models.WebhookSubscription
+	Secret string `json:"secret"`
+}
+
+func (h *WebhookSubscriptionHandler) GetSubscriptions(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	subscriptions, err := h.webhooks.GetSubscriptions(userID.(uint))
+	if err != nil {
+		apierror.Respond(c, apierror.Internal(err, "Failed to fetch webhook subscriptions"))
+		return
+	}
+
+	c.JSON(http.StatusOK, subscriptions)
+}
+
+func (h *WebhookSubscriptionHandler) GetSubscription(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	subscriptionID, ok := middleware.PathID(c, "id")
+	if !ok {
+		return
+	}
+
+	sub, err := h.webhooks.GetSubscriptionByID(subscriptionID, userID.(uint))
+	if err != nil {
+		respondWebhookSubscriptionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, sub)
+}
+
+func (h *WebhookSubscriptionHandler) CreateSubscription(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	var req WebhookSubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	sub := &models.WebhookSubscription{UserID: userID.(uint)}
+	req.applyTo(sub)
+
+	if err := h.webhooks.CreateSubscription(sub); err != nil {
+		respondWebhookSubscriptionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, createdSubscription{WebhookSubscription: sub, Secret: sub.Secret})
+}
+
+func (h *WebhookSubscriptionHandler) UpdateSubscription(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	subscriptionID, ok := middleware.PathID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req WebhookSubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	sub, err := h.webhooks.GetSubscriptionByID(subscriptionID, userID.(uint))
+	if err != nil {
+		respondWebhookSubscriptionError(c, err)
+		return
+	}
+	req.applyTo(sub)
+
+	if err := h.webhooks.UpdateSubscription(sub); err != nil {
+		respondWebhookSubscriptionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, sub)
+}
+
+func (h *WebhookSubscriptionHandler) DeleteSubscription(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	subscriptionID, ok := middleware.PathID(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.webhooks.DeleteSubscription(subscriptionID, userID.(uint)); err != nil {
+		respondWebhookSubscriptionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Webhook subscription deleted successfully"})
+}
+
+// GetDeliveries returns the most recent delivery attempts, newest first.
+func (h *WebhookSubscriptionHandler) GetDeliveries(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	subscriptionID, ok := middleware.PathID(c, "id")
+	if !ok {
+		return
+	}
+	limit, _ := strconv.Atoi(c.Query("limit"))
+
+	deliveries, err := h.webhooks.GetDeliveries(subscriptionID, userID.(uint), limit)
+	if err != nil {
+		respondWebhookSubscriptionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, deliveries)
+}
+
+// Redeliver queues the event of an earlier delivery to be sent again.
+func (h *WebhookSubscriptionHandler) Redeliver(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	subscriptionID, ok := middleware.PathID(c, "id")
+	if !ok {
+		return
+	}
+	deliveryID, ok := middleware.PathID(c, "delivery_id")
+	if !ok {
+		return
+	}
+
+	delivery, err := h.webhooks.Redeliver(subscriptionID, deliveryID, userID.(uint))
+	if err != nil {
+		respondWebhookSubscriptionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, delivery)
+}
+
+func respondWebhookSubscriptionError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrSubscriptionNotFound):
+		apierror.Respond(c, apierror.NotFound("Webhook subscription not found"))
+	case errors.Is(err, services.ErrDeliveryNotFound):
+		apierror.Respond(c, apierror.NotFound("Webhook delivery not found"))
+	case errors.Is(err, services.ErrInvalidSubscription):
+		apierror.Respond(c, apierror.BadRequest(err.Error()))
+	default:
+		apierror.Respond(c, apierror.Internal(err, "Failed to process webhook subscription"))
+	}
+}
@@ -2,9 +2,12 @@
 package middleware
 
 import (
-	"net/http"
+	"fmt"
+	"strconv"
 	"strings"
 
+	"finbro-backend-go/internal/apierror"
+
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v4"
 )
@@ -15,34 +18,53 @@ type Claims struct {
 	jwt.RegisteredClaims
 }
 
+// principal returns the user the token was issued to. Tokens carry the user
+// ID as the subject; the user_id claim is only read from older tokens.
+func (c *Claims) principal() (uint, bool) {
+	if c.Subject != "" {
+		id, err := strconv.ParseUint(c.Subject, 10, 64)
+		if err != nil || id == 0 {
+			return 0, false
+		}
+		return uint(id), true
+	}
+	return c.UserID, c.UserID != 0
+}
+
 func AuthRequired(secretKey string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header required"})
-			c.Abort()
+			apierror.Respond(c, apierror.Unauthorized("Authorization header required"))
 			return
 		}
 
 		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
 		if tokenString == authHeader {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token format"})
-			c.Abort()
+			apierror.Respond(c, apierror.Unauthorized("Invalid token format"))
 			return
 		}
 
 		claims := &Claims{}
 		token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+			if token.Method != jwt.SigningMethodHS256 {
+				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+			}
 			return []byte(secretKey), nil
 		})
 
 		if err != nil || !token.Valid {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
-			c.Abort()
+			apierror.Respond(c, apierror.Unauthorized("Invalid token"))
+			return
+		}
+
+		userID, ok := claims.principal()
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Invalid token"))
 			return
 		}
 
-		c.Set("user_id", claims.UserID)
+		c.Set("user_id", userID)
 		c.Set("user_email", claims.Email)
 		c.Next()
 	}
@@ -0,0 +1,30 @@
+// internal/api/middleware/body_limit.go
+package middleware
+
+import (
+	"net/http"
+
+	"finbro-backend-go/internal/apierror"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MaxBodySize rejects requests whose body is larger than limit bytes. A
+// declared Content-Length over the limit fails fast with 413; bodies without
+// one are cut off while being read.
+func MaxBodySize(limit int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if limit <= 0 || c.Request.Body == nil {
+			c.Next()
+			return
+		}
+
+		if c.Request.ContentLength > limit {
+			apierror.Respond(c, apierror.PayloadTooLarge("Request body too large"))
+			return
+		}
+
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, limit)
+		c.Next()
+	}
+}
@@ -0,0 +1,54 @@
+// internal/api/middleware/etag.go
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"finbro-backend-go/internal/apierror"
+
+	"github.com/gin-gonic/gin"
+)
+
+const ifMatchRequiredKey = "if_match_required"
+
+// ETag returns the strong entity tag for a row version.
+func ETag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// RequireIfMatch makes the LoadAccount or LoadTransaction after it demand
+// If-Match as it does for PUT and PATCH. It is for routes that change the
+// loaded resource with another method, such as deleting a transaction's
+// splits.
+func RequireIfMatch() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(ifMatchRequiredKey, true)
+		c.Next()
+	}
+}
+
+// checkIfMatch enforces optimistic concurrency: updates must name the
+// version they were based on, and any request that sends If-Match fails
+// with 412 when the resource has moved on.
+func checkIfMatch(c *gin.Context, etag string) bool {
+	ifMatch := c.GetHeader("If-Match")
+	if ifMatch == "" {
+		method := c.Request.Method
+		if method == http.MethodPut || method == http.MethodPatch || c.GetBool(ifMatchRequiredKey) {
+			apierror.Respond(c, apierror.PreconditionRequired("If-Match header is required"))
+			return false
+		}
+		return true
+	}
+
+	for _, candidate := range strings.Split(ifMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	apierror.Respond(c, apierror.PreconditionFailed("Resource has been modified"))
+	return false
+}
@@ -0,0 +1,143 @@
+// internal/api/middleware/idempotency.go
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"finbro-backend-go/internal/apierror"
+	"finbro-backend-go/internal/logging"
+	"finbro-backend-go/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	IdempotencyKeyHeader   = "Idempotency-Key"
+	IdempotentReplayHeader = "Idempotent-Replayed"
+
+	maxIdempotencyKeyLength = 255
+)
+
+var idempotentMethods = map[string]bool{
+	http.MethodPost:   true,
+	http.MethodPut:    true,
+	http.MethodPatch:  true,
+	http.MethodDelete: true,
+}
+
+// Idempotency makes mutating requests that carry an Idempotency-Key header
+// safe to retry. The first request runs and its response is stored per user
+// and key; retries with the same body replay it, a different body is
+// rejected, and a retry racing the first request gets 409 for as long as
+// the first one runs. Only if its process dies, or cannot reach the database
+// for a minute, may a retry take the key over and run the request again.
+// Server errors are not stored, so those requests can be retried for real.
+func Idempotency(idempotency *services.IdempotencyService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := strings.TrimSpace(c.GetHeader(IdempotencyKeyHeader))
+		if key == "" || !idempotentMethods[c.Request.Method] {
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			apierror.Respond(c, apierror.BadRequest("Idempotency-Key must be at most 255 characters"))
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			apierror.Respond(c, err)
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		ctx := c.Request.Context()
+		record, acquired, err := idempotency.Begin(ctx, c.GetUint("user_id"), key,
+			c.Request.Method, c.Request.URL.Path, requestHash(c.Request, body))
+		switch {
+		case errors.Is(err, services.ErrIdempotencyKeyReused):
+			apierror.Respond(c, apierror.New(http.StatusUnprocessableEntity, apierror.CodeIdempotencyKeyReused, err.Error()))
+			return
+		case errors.Is(err, services.ErrIdempotencyKeyInFlight):
+			apierror.Respond(c, apierror.New(http.StatusConflict, apierror.CodeIdempotencyKeyInFlight, err.Error()))
+			return
+		case err != nil:
+			apierror.Respond(c, apierror.Internal(err, "Failed to check idempotency key"))
+			return
+		}
+
+		if !acquired {
+			for name, value := range idempotency.Headers(record) {
+				c.Header(name, value)
+			}
+			c.Header(IdempotentReplayHeader, "true")
+			c.Data(record.ResponseStatus, c.Writer.Header().Get("Content-Type"), record.ResponseBody)
+			c.Abort()
+			return
+		}
+
+		writer := &capturingWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+
+		stored := false
+		defer func() {
+			if !stored {
+				if err := idempotency.Release(context.WithoutCancel(ctx), record); err != nil {
+					logging.FromContext(ctx).Error("Failed to release idempotency key", "error", err)
+				}
+			}
+		}()
+		hold := idempotency.Hold(ctx, record)
+		defer hold()
+
+		c.Next()
+		hold()
+
+		if writer.Status() >= http.StatusInternalServerError {
+			return
+		}
+		stored = true
+
+		headers := make(map[string]string)
+		for _, name := range services.ReplayedHeaders {
+			if value := writer.Header().Get(name); value != "" {
+				headers[name] = value
+			}
+		}
+		if err := idempotency.Complete(context.WithoutCancel(ctx), record, writer.Status(), headers, writer.body.Bytes()); err != nil {
+			logging.FromContext(ctx).Error("Failed to store idempotent response", "error", err)
+		}
+	}
+}
+
+// requestHash identifies a request by method, path and body, so a key
+// reused for anything else is detected.
+func requestHash(r *http.Request, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(r.Method + " " + r.URL.Path + "\n"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// capturingWriter keeps a copy of the response body for storage.
+type capturingWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *capturingWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *capturingWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,50 @@
+// internal/api/middleware/metrics.go
+package middleware
+
+import (
+	"crypto/subtle"
+	"strconv"
+	"time"
+
+	"finbro-backend-go/internal/apierror"
+	"finbro-backend-go/internal/metrics"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Metrics records request counts and latency. Routes are labelled by their
+// template (/api/v1/accounts/:id), never the raw path, to keep cardinality
+// bounded.
+func Metrics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		started := time.Now()
+		metrics.HTTPInFlight.Inc()
+		defer metrics.HTTPInFlight.Dec()
+
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		status := strconv.Itoa(c.Writer.Status())
+
+		metrics.HTTPRequests.WithLabelValues(c.Request.Method, route, status).Inc()
+		metrics.HTTPDuration.WithLabelValues(c.Request.Method, route, status).
+			Observe(time.Since(started).Seconds())
+	}
+}
+
+// BearerToken guards an endpoint with a static token. An empty token
+// rejects every request.
+func BearerToken(token string) gin.HandlerFunc {
+	expected := []byte("Bearer " + token)
+	return func(c *gin.Context) {
+		provided := []byte(c.GetHeader("Authorization"))
+		if token == "" || subtle.ConstantTimeCompare(provided, expected) != 1 {
+			apierror.Respond(c, apierror.Unauthorized("Invalid metrics token"))
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,93 @@
+// internal/api/middleware/resource.go
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"finbro-backend-go/internal/apierror"
+	"finbro-backend-go/internal/db"
+	"finbro-backend-go/internal/db/models"
+	"finbro-backend-go/internal/publicid"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	accountKey     = "account"
+	transactionKey = "transaction"
+)
+
+// PathID parses a positive integer path parameter. On failure it responds
+// with 400 and reports false, so handlers can simply return.
+func PathID(c *gin.Context, param string) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param(param), 10, strconv.IntSize)
+	if err != nil || id == 0 {
+		apierror.Respond(c, apierror.BadRequest("Invalid "+param))
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// LoadAccount loads the account whose public ID (or, during the legacy ID
+// compatibility window, numeric ID) is the :id path parameter, scoped to
+// the authenticated user, and stores it for CurrentAccount.
+func LoadAccount(database *db.DB, preload ...string) gin.HandlerFunc {
+	return loadOwned(database, accountKey, "Account not found", preload, func(a *models.Account) int { return a.Version })
+}
+
+// LoadTransaction loads the transaction named by the :id path parameter like
+// LoadAccount, scoped to the authenticated user, and stores it for CurrentTransaction.
+func LoadTransaction(database *db.DB, preload ...string) gin.HandlerFunc {
+	return loadOwned(database, transactionKey, "Transaction not found", preload, func(t *models.Transaction) int { return t.Version })
+}
+
+func CurrentAccount(c *gin.Context) *models.Account {
+	return c.MustGet(accountKey).(*models.Account)
+}
+
+func CurrentTransaction(c *gin.Context) *models.Transaction {
+	return c.MustGet(transactionKey).(*models.Transaction)
+}
+
+// loadOwned answers 404 rather than 403 for another user's resource, so
+// callers cannot probe which IDs exist. It also checks If-Match against the
+// row version and sets the ETag on reads.
+func loadOwned[T any](database *db.DB, key, notFound string, preload []string, version func(*T) int) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, err := publicid.Scope(c.Param("id"))
+		if err != nil {
+			apierror.Respond(c, apierror.BadRequest("Invalid id"))
+			return
+		}
+
+		query := database.WithContext(c.Request.Context()).Scopes(scope)
+		for _, association := range preload {
+			query = query.Preload(association)
+		}
+
+		var entity T
+		err = query.Where("user_id = ?", c.GetUint("user_id")).First(&entity).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			apierror.Respond(c, apierror.NotFound(notFound))
+			return
+		}
+		if err != nil {
+			apierror.Respond(c, apierror.Internal(err, "Failed to load resource"))
+			return
+		}
+
+		etag := ETag(version(&entity))
+		if !checkIfMatch(c, etag) {
+			return
+		}
+		if c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead {
+			c.Header("ETag", etag)
+		}
+
+		c.Set(key, &entity)
+		c.Next()
+	}
+}
@@ -2,37 +2,70 @@
 package api
 
 import (
+	"net/http"
+
 	"finbro-backend-go/internal/api/handlers"
 	"finbro-backend-go/internal/api/middleware"
+	"finbro-backend-go/internal/apierror"
 	"finbro-backend-go/internal/config"
 	"finbro-backend-go/internal/db"
+	"finbro-backend-go/internal/metrics"
+	"finbro-backend-go/internal/services"
 
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 )
 
 func SetupRouter(
 	database *db.DB,
 	cfg *config.Config,
+	idempotency *services.IdempotencyService,
+	healthHandler *handlers.HealthHandler,
 	authHandler *handlers.AuthHandler,
 	userHandler *handlers.UserHandler,
 	accountHandler *handlers.AccountHandler,
 	transactionHandler *handlers.TransactionHandler,
+	categoryHandler *handlers.CategoryHandler,
+	tagHandler *handlers.TagHandler,
+	attachmentHandler *handlers.AttachmentHandler,
+	ruleHandler *handlers.RuleHandler,
+	assistantHandler *handlers.AssistantHandler,
+	bankLinkHandler *handlers.BankLinkHandler,
+	webhookHandler *handlers.WebhookHandler,
+	webhookSubscriptionHandler *handlers.WebhookSubscriptionHandler,
 ) *gin.Engine {
 	router := gin.New()
+	router.HandleMethodNotAllowed = true
 
 	// Global middleware
-	router.Use(gin.Logger())
-	router.Use(gin.Recovery())
+	router.Use(otelgin.Middleware(cfg.Tracing.ServiceName))
+	router.Use(middleware.RequestID())
+	router.Use(middleware.Logger())
+	router.Use(middleware.Metrics())
+	router.Use(middleware.Recovery())
 	router.Use(middleware.CORS())
+	router.Use(middleware.MaxBodySize(cfg.Server.MaxBodyBytes))
+
+	router.NoRoute(func(c *gin.Context) {
+		apierror.Respond(c, apierror.NotFound("Route not found"))
+	})
+	router.NoMethod(func(c *gin.Context) {
+		apierror.Respond(c, apierror.New(http.StatusMethodNotAllowed, apierror.CodeMethodNotAllowed, "Method not allowed"))
+	})
 
 	router.GET("/", func(c *gin.Context) {
 		c.JSON(200, gin.H{"message": "Welcome to Finbro API"})
 	})
 
-	// Health check
-	router.GET("/health", func(c *gin.Context) {
-		c.JSON(200, gin.H{"status": "healthy"})
-	})
+	// Health checks; /health is kept for existing monitors and reports readiness
+	router.GET("/livez", healthHandler.Livez)
+	router.GET("/readyz", healthHandler.Readyz)
+	router.GET("/health", healthHandler.Readyz)
+
+	// Metrics share the API listener only when no dedicated one is configured
+	if cfg.Metrics.Address == "" && cfg.Metrics.Token != "" {
+		router.GET("/metrics", middleware.BearerToken(cfg.Metrics.Token), gin.WrapH(metrics.Handler()))
+	}
 
 	jwtSecret := cfg.JWT.Secret
 
@@ -50,16 +83,21 @@ func SetupRouter(
 			authGroup.GET("/google/callback", authHandler.GoogleCallback)
 		}
 
+		// Provider webhooks (authenticated by signature, not JWT)
+		v1.POST("/webhooks/:provider", webhookHandler.Receive)
+
 		// Protected routes
 		protected := v1.Group("/")
 		// --- FIXED: Pass the correct JWT secret ---
 		protected.Use(middleware.AuthRequired(jwtSecret)) // Use the extracted secret
+		protected.Use(middleware.Idempotency(idempotency))
 		{
 			// User routes
 			users := protected.Group("/users")
 			{
 				users.GET("/profile", userHandler.GetProfile)
 				users.PUT("/profile", userHandler.UpdateProfile)
+				users.PATCH("/profile", userHandler.PatchProfile)
 				users.DELETE("/account", userHandler.DeleteAccount)
 			}
 
@@ -68,9 +106,11 @@ func SetupRouter(
 			{
 				accounts.GET("/", accountHandler.GetAccounts)
 				accounts.POST("/", accountHandler.CreateAccount)
-				accounts.GET("/:id", accountHandler.GetAccount)
-				accounts.PUT("/:id", accountHandler.UpdateAccount)
-				accounts.DELETE("/:id", accountHandler.DeleteAccount)
+				loadAccount := middleware.LoadAccount(database)
+				accounts.GET("/:id", loadAccount, accountHandler.GetAccount)
+				accounts.PUT("/:id", loadAccount, accountHandler.UpdateAccount)
+				accounts.PATCH("/:id", loadAccount, accountHandler.PatchAccount)
+				accounts.DELETE("/:id", loadAccount, accountHandler.DeleteAccount)
 			}
 
 			// Transaction routes
@@ -78,12 +118,102 @@ func SetupRouter(
 			{
 				transactions.GET("/", transactionHandler.GetTransactions)
 				transactions.POST("/", transactionHandler.CreateTransaction)
-				transactions.GET("/:id", transactionHandler.GetTransaction)
-				transactions.PUT("/:id", transactionHandler.UpdateTransaction)
-				transactions.DELETE("/:id", transactionHandler.DeleteTransaction)
+				transactions.GET("/search", transactionHandler.SearchTransactions)
+				transactions.POST("/bulk", transactionHandler.BulkTransactions)
+				transactions.GET("/export", transactionHandler.ExportTransactions)
+				transactions.POST("/tags", tagHandler.TagTransactions)
+				loadTransaction := middleware.LoadTransaction(database)
+				transactions.GET("/:id", middleware.LoadTransaction(database, "Account", "Tags", "Splits"), transactionHandler.GetTransaction)
+				transactions.PUT("/:id", loadTransaction, transactionHandler.UpdateTransaction)
+				transactions.PATCH("/:id", loadTransaction, transactionHandler.PatchTransaction)
+				transactions.DELETE("/:id", loadTransaction, transactionHandler.DeleteTransaction)
+				transactions.GET("/:id/suggestions", loadTransaction, transactionHandler.GetSuggestions)
+				transactions.GET("/:id/splits", loadTransaction, transactionHandler.GetSplits)
+				transactions.PUT("/:id/splits", loadTransaction, transactionHandler.ReplaceSplits)
+				transactions.DELETE("/:id/splits", middleware.RequireIfMatch(), loadTransaction, transactionHandler.DeleteSplits)
+				transactions.GET("/:id/attachments", loadTransaction, attachmentHandler.GetAttachments)
+				transactions.POST("/:id/attachments", loadTransaction, attachmentHandler.UploadAttachment)
+				transactions.GET("/:id/attachments/:attachment_id", loadTransaction, attachmentHandler.DownloadAttachment)
+				transactions.GET("/:id/attachments/:attachment_id/thumbnail", loadTransaction, attachmentHandler.DownloadThumbnail)
+				transactions.DELETE("/:id/attachments/:attachment_id", loadTransaction, attachmentHandler.DeleteAttachment)
+			}
+
+			// Category routes
+			categories := protected.Group("/categories")
+			{
+				categories.GET("/", categoryHandler.GetCategories)
+				categories.POST("/", categoryHandler.CreateCategory)
+				categories.GET("/:id", categoryHandler.GetCategory)
+				categories.PUT("/:id", categoryHandler.UpdateCategory)
+				categories.DELETE("/:id", categoryHandler.DeleteCategory)
+			}
+
+			// Tag routes
+			tags := protected.Group("/tags")
+			{
+				tags.GET("/", tagHandler.GetTags)
+				tags.POST("/", tagHandler.CreateTag)
+				tags.GET("/report", tagHandler.GetTagReport)
+				tags.GET("/:id", tagHandler.GetTag)
+				tags.PUT("/:id", tagHandler.UpdateTag)
+				tags.DELETE("/:id", tagHandler.DeleteTag)
+			}
+
+			// Categorization rule routes
+			rules := protected.Group("/rules")
+			{
+				rules.GET("/", ruleHandler.GetRules)
+				rules.POST("/", ruleHandler.CreateRule)
+				rules.GET("/:id", ruleHandler.GetRule)
+				rules.PUT("/:id", ruleHandler.UpdateRule)
+				rules.DELETE("/:id", ruleHandler.DeleteRule)
+				rules.POST("/:id/dry-run", ruleHandler.DryRunRule)
+				rules.POST("/:id/apply", ruleHandler.ApplyRule)
+			}
+
+			// AI assistant routes
+			assistant := protected.Group("/assistant")
+			{
+				assistant.POST("/ask", assistantHandler.Ask)
+			}
+
+			// Bank linking routes
+			bank := protected.Group("/bank")
+			{
+				bank.POST("/link-token", bankLinkHandler.CreateLinkToken)
+				bank.GET("/items", bankLinkHandler.GetItems)
+				bank.POST("/items", bankLinkHandler.ExchangePublicToken)
+				bank.POST("/items/:id/sync", bankLinkHandler.SyncItem)
+				bank.DELETE("/items/:id", bankLinkHandler.RemoveItem)
+			}
+
+			// Outbound webhook subscription routes
+			subscriptions := protected.Group("/webhook-subscriptions")
+			{
+				subscriptions.GET("/", webhookSubscriptionHandler.GetSubscriptions)
+				subscriptions.POST("/", webhookSubscriptionHandler.CreateSubscription)
+				subscriptions.GET("/:id", webhookSubscriptionHandler.GetSubscription)
+				subscriptions.PUT("/:id", webhookSubscriptionHandler.UpdateSubscription)
+				subscriptions.DELETE("/:id", webhookSubscriptionHandler.DeleteSubscription)
+				subscriptions.GET("/:id/deliveries", webhookSubscriptionHandler.GetDeliveries)
+				subscriptions.POST("/:id/deliveries/:delivery_id/redeliver", webhookSubscriptionHandler.Redeliver)
 			}
 		}
 	}
 
 	return router
 }
+
+// SetupMetricsRouter serves /metrics alone, for a listener that is not
+// exposed publicly. The bearer token still applies if one is configured.
+func SetupMetricsRouter(cfg *config.Config) *gin.Engine {
+	router := gin.New()
+	router.Use(gin.Recovery())
+
+	if cfg.Metrics.Token != "" {
+		router.GET("/metrics", middleware.BearerToken(cfg.Metrics.Token), gin.WrapH(metrics.Handler()))
+	} else {
+		router.GET("/metrics", gin.WrapH(metrics.Handler()))
+	}
+	return router
+}
@@ -0,0 +1,67 @@
+// internal/api/validators.go
+package api
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"finbro-backend-go/internal/utils"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+var registerValidatorsOnce sync.Once
+
+// RegisterValidators adds the domain-specific binding tags (currency,
+// account_type, amount, name, color) to gin's validator and makes validation errors
+// report JSON field names instead of Go struct field names.
+func RegisterValidators() error {
+	var err error
+	registerValidatorsOnce.Do(func() {
+		v, ok := binding.Validator.Engine().(*validator.Validate)
+		if !ok {
+			err = fmt.Errorf("unexpected binding validator engine %T", binding.Validator.Engine())
+			return
+		}
+
+		v.RegisterTagNameFunc(jsonFieldName)
+
+		validators := map[string]validator.Func{
+			"currency": func(fl validator.FieldLevel) bool {
+				return utils.IsValidCurrency(fl.Field().String())
+			},
+			"account_type": func(fl validator.FieldLevel) bool {
+				return utils.IsValidAccountType(fl.Field().String())
+			},
+			"amount": func(fl validator.FieldLevel) bool {
+				return utils.IsValidAmount(fl.Field().Float())
+			},
+			"name": func(fl validator.FieldLevel) bool {
+				return utils.IsValidName(fl.Field().String())
+			},
+			"color": func(fl validator.FieldLevel) bool {
+				return utils.IsValidColor(fl.Field().String())
+			},
+		}
+		for tag, fn := range validators {
+			if err = v.RegisterValidation(tag, fn); err != nil {
+				return
+			}
+		}
+	})
+	return err
+}
+
+func jsonFieldName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	switch name {
+	case "-":
+		return ""
+	case "":
+		return field.Name
+	}
+	return name
+}
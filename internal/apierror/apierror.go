@@ -0,0 +1,146 @@
+// internal/apierror/apierror.go
+package apierror
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Code is a stable, machine-readable error identifier. Clients may branch
+// on codes; messages are for humans and can change.
+type Code string
+
+const (
+	CodeInvalidRequest         Code = "invalid_request"
+	CodeValidationFailed       Code = "validation_failed"
+	CodeUnauthorized           Code = "unauthorized"
+	CodeForbidden              Code = "forbidden"
+	CodeNotFound               Code = "not_found"
+	CodeMethodNotAllowed       Code = "method_not_allowed"
+	CodeConflict               Code = "conflict"
+	CodeIdempotencyKeyReused   Code = "idempotency_key_reused"
+	CodeIdempotencyKeyInFlight Code = "idempotency_key_in_flight"
+	CodePayloadTooLarge        Code = "payload_too_large"
+	CodeUnsupportedMediaType   Code = "unsupported_media_type"
+	CodePreconditionFailed     Code = "precondition_failed"
+	CodePreconditionRequired   Code = "precondition_required"
+	CodeUnprocessable          Code = "unprocessable"
+	CodeInternal               Code = "internal_error"
+	CodeUpstream               Code = "upstream_error"
+	CodeServiceUnavailable     Code = "service_unavailable"
+)
+
+// TypeURIPrefix prefixes the code to form the RFC 7807 "type" member.
+const TypeURIPrefix = "urn:finbro:problem:"
+
+// FieldError describes one invalid request field.
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Error is an error that knows how it should be presented to API clients.
+// Err, when set, is the internal cause: it is logged but never returned.
+type Error struct {
+	Status int
+	Code   Code
+	Detail string
+	Fields []FieldError
+	Err    error
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %s: %v", e.Code, e.Detail, e.Err)
+	}
+	return fmt.Sprintf("%s: %s", e.Code, e.Detail)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+func New(status int, code Code, detail string) *Error {
+	return &Error{Status: status, Code: code, Detail: detail}
+}
+
+// Wrap attaches an internal cause to the error.
+func (e *Error) Wrap(err error) *Error {
+	e.Err = err
+	return e
+}
+
+func BadRequest(detail string) *Error {
+	return New(http.StatusBadRequest, CodeInvalidRequest, detail)
+}
+
+func Validation(detail string, fields ...FieldError) *Error {
+	e := New(http.StatusBadRequest, CodeValidationFailed, detail)
+	e.Fields = fields
+	return e
+}
+
+func Unauthorized(detail string) *Error {
+	return New(http.StatusUnauthorized, CodeUnauthorized, detail)
+}
+
+func Forbidden(detail string) *Error {
+	return New(http.StatusForbidden, CodeForbidden, detail)
+}
+
+func NotFound(detail string) *Error {
+	return New(http.StatusNotFound, CodeNotFound, detail)
+}
+
+func Conflict(detail string) *Error {
+	return New(http.StatusConflict, CodeConflict, detail)
+}
+
+func PreconditionFailed(detail string) *Error {
+	return New(http.StatusPreconditionFailed, CodePreconditionFailed, detail)
+}
+
+func PreconditionRequired(detail string) *Error {
+	return New(http.StatusPreconditionRequired, CodePreconditionRequired, detail)
+}
+
+func PayloadTooLarge(detail string) *Error {
+	return New(http.StatusRequestEntityTooLarge, CodePayloadTooLarge, detail)
+}
+
+func UnsupportedMediaType(detail string) *Error {
+	return New(http.StatusUnsupportedMediaType, CodeUnsupportedMediaType, detail)
+}
+
+func Unprocessable(detail string) *Error {
+	return New(http.StatusUnprocessableEntity, CodeUnprocessable, detail)
+}
+
+// Internal hides err from the client behind detail, which should say what
+// failed without saying why.
+func Internal(err error, detail string) *Error {
+	return New(http.StatusInternalServerError, CodeInternal, detail).Wrap(err)
+}
+
+func Upstream(err error, detail string) *Error {
+	return New(http.StatusBadGateway, CodeUpstream, detail).Wrap(err)
+}
+
+func Unavailable(detail string) *Error {
+	return New(http.StatusServiceUnavailable, CodeServiceUnavailable, detail)
+}
+
+// From converts any error into an *Error. Errors that are not already API
+// errors become a generic 500 so internals never leak.
+func From(err error) *Error {
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		return apiErr
+	}
+	if bindErr := fromBinding(err); bindErr != nil {
+		return bindErr
+	}
+	return Internal(err, "An unexpected error occurred")
+}
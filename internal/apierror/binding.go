@@ -0,0 +1,93 @@
+// internal/apierror/binding.go
+package apierror
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// fieldMessages maps validator tags to client-facing messages. Tags not
+// listed fall back to a generic message.
+var fieldMessages = map[string]string{
+	"required":     "is required",
+	"email":        "must be a valid email address",
+	"min":          "must be at least %s",
+	"max":          "must be at most %s",
+	"oneof":        "must be one of: %s",
+	"url":          "must be a valid URL",
+	"currency":     "must be a supported ISO 4217 currency code",
+	"account_type": "must be one of: checking, savings, credit, investment, loan, business",
+	"amount":       "must be greater than 0 and at most 999999999.99",
+	"name":         "must be between 1 and 50 characters",
+	"color":        "must be a hex color such as #4CAF50",
+	"gt":           "must be greater than %s",
+	"gte":          "must be at least %s",
+	"lte":          "must be at most %s",
+}
+
+// fromBinding translates errors from gin's binding into API errors, or
+// returns nil when err did not come from binding.
+func fromBinding(err error) *Error {
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		fields := make([]FieldError, 0, len(validationErrs))
+		for _, fe := range validationErrs {
+			fields = append(fields, FieldError{
+				Field:   fieldPath(fe),
+				Code:    fe.Tag(),
+				Message: fieldMessage(fe),
+			})
+		}
+		return Validation("The request contains invalid fields", fields...)
+	}
+
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return PayloadTooLarge("Request body too large")
+	}
+
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return BadRequest("Request body is not valid JSON").Wrap(err)
+	}
+	if errors.Is(err, io.EOF) {
+		return BadRequest("Request body is required").Wrap(err)
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		return Validation("The request contains invalid fields", FieldError{
+			Field:   typeErr.Field,
+			Code:    "type",
+			Message: fmt.Sprintf("must be of type %s", typeErr.Type.String()),
+		}).Wrap(err)
+	}
+	return nil
+}
+
+// fieldPath drops the top-level struct name, so "CreateAccountRequest.currency"
+// is reported as "currency".
+func fieldPath(fe validator.FieldError) string {
+	namespace := fe.Namespace()
+	if _, rest, ok := strings.Cut(namespace, "."); ok {
+		return rest
+	}
+	return fe.Field()
+}
+
+func fieldMessage(fe validator.FieldError) string {
+	message, ok := fieldMessages[fe.Tag()]
+	if !ok {
+		return "is invalid"
+	}
+	if strings.Contains(message, "%s") {
+		return fmt.Sprintf(message, fe.Param())
+	}
+	return message
+}
@@ -0,0 +1,55 @@
+// internal/apierror/respond.go
+package apierror
+
+import (
+	"net/http"
+
+	"finbro-backend-go/internal/logging"
+
+	"github.com/gin-gonic/gin"
+)
+
+const ContentType = "application/problem+json"
+
+// Problem is the RFC 7807 response body, extended with a stable code, the
+// request ID and per-field errors.
+type Problem struct {
+	Type      string       `json:"type"`
+	Title     string       `json:"title"`
+	Status    int          `json:"status"`
+	Detail    string       `json:"detail,omitempty"`
+	Instance  string       `json:"instance,omitempty"`
+	Code      Code         `json:"code"`
+	RequestID string       `json:"request_id,omitempty"`
+	Errors    []FieldError `json:"errors,omitempty"`
+}
+
+// Respond writes err as a problem document and aborts the request. Server
+// errors with an internal cause are logged.
+func Respond(c *gin.Context, err error) {
+	apiErr := From(err)
+
+	if apiErr.Status >= http.StatusInternalServerError && apiErr.Err != nil {
+		logging.FromContext(c.Request.Context()).Error(apiErr.Detail,
+			"code", apiErr.Code,
+			"error", apiErr.Err,
+		)
+	}
+	if apiErr.Err != nil {
+		_ = c.Error(apiErr.Err)
+	}
+
+	problem := Problem{
+		Type:      TypeURIPrefix + string(apiErr.Code),
+		Title:     http.StatusText(apiErr.Status),
+		Status:    apiErr.Status,
+		Detail:    apiErr.Detail,
+		Instance:  c.Request.URL.Path,
+		Code:      apiErr.Code,
+		RequestID: c.GetString("request_id"),
+		Errors:    apiErr.Fields,
+	}
+
+	c.Header("Content-Type", ContentType)
+	c.AbortWithStatusJSON(apiErr.Status, problem)
+}
@@ -0,0 +1,107 @@
+// internal/app/services.go
+package app
+
+import (
+	"fmt"
+	"time"
+
+	"finbro-backend-go/internal/aggregator"
+	"finbro-backend-go/internal/config"
+	"finbro-backend-go/internal/db"
+	"finbro-backend-go/internal/jobs"
+	"finbro-backend-go/internal/llm"
+	"finbro-backend-go/internal/secrets"
+	"finbro-backend-go/internal/services"
+	"finbro-backend-go/internal/storage"
+	"finbro-backend-go/internal/webhooks"
+)
+
+// Services holds everything shared by the API server and the worker, so
+// both binaries build the same graph.
+type Services struct {
+	Queue            *jobs.Queue
+	User             *services.UserService
+	Transaction      *services.TransactionService
+	Category         *services.CategoryService
+	Rule             *services.RuleService
+	Tag              *services.TagService
+	Attachment       *services.AttachmentService
+	Categorizer      *services.CategorizerService
+	Assistant        *services.AssistantService
+	Budget           *services.BudgetService
+	BankLink         *services.BankLinkService
+	InboundWebhooks  *services.InboundWebhookService
+	OutboundWebhooks *services.OutboundWebhookService
+	Idempotency      *services.IdempotencyService
+}
+
+func NewServices(cfg *config.Config, database *db.DB) (*Services, error) {
+	llmProvider, err := llm.NewProvider(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure LLM provider: %w", err)
+	}
+
+	bankAggregator, err := aggregator.New(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure bank aggregator: %w", err)
+	}
+
+	blobStore, err := storage.New(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure attachment store: %w", err)
+	}
+
+	var tokenEncrypter *secrets.Encrypter
+	if cfg.BankLink.EncryptionKey != "" {
+		if tokenEncrypter, err = secrets.NewEncrypter(cfg.BankLink.EncryptionKey); err != nil {
+			return nil, fmt.Errorf("failed to configure token encryption: %w", err)
+		}
+	}
+
+	s := &Services{Queue: jobs.NewQueue(database)}
+	s.User = services.NewUserService(database)
+	s.Transaction = services.NewTransactionService(database)
+	s.Category = services.NewCategoryService(database)
+	s.Categorizer = services.NewCategorizerService(database, cfg.Categorizer.AutoApplyThreshold)
+	s.OutboundWebhooks = services.NewOutboundWebhookService(database, s.Queue)
+	s.Budget = services.NewBudgetService(database, s.OutboundWebhooks)
+	s.Rule = services.NewRuleService(database, s.Category, s.Categorizer, s.Budget, s.OutboundWebhooks)
+	s.Tag = services.NewTagService(database)
+	s.Attachment = services.NewAttachmentService(database, blobStore, s.Queue, cfg.Attachments.MaxBytes)
+	s.Assistant = services.NewAssistantService(database, llmProvider, s.Transaction)
+	s.Idempotency = services.NewIdempotencyService(database)
+	s.BankLink = services.NewBankLinkService(database, bankAggregator, tokenEncrypter, s.Rule, s.Categorizer, s.Budget, s.Attachment)
+
+	webhookRegistry := webhooks.NewRegistry()
+	if keys, ok := bankAggregator.(webhooks.KeyFetcher); ok {
+		webhookRegistry.Register(webhooks.NewPlaidProvider(keys))
+	}
+	for name, secret := range cfg.Webhooks.HMACSecrets {
+		webhookRegistry.Register(webhooks.NewHMACProvider(name, secret))
+	}
+	s.InboundWebhooks = services.NewInboundWebhookService(database, s.Queue, webhookRegistry, s.BankLink)
+
+	return s, nil
+}
+
+// NewWorker returns a job worker with every job handler and cron schedule
+// registered.
+func (s *Services) NewWorker(cfg *config.Config) (*jobs.Worker, error) {
+	worker := jobs.NewWorker(s.Queue, jobs.WorkerConfig{
+		Queues:       cfg.Jobs.Queues,
+		Concurrency:  cfg.Jobs.Concurrency,
+		DrainTimeout: cfg.Jobs.DrainTimeout,
+	})
+
+	s.InboundWebhooks.RegisterJobs(worker)
+	s.OutboundWebhooks.RegisterJobs(worker)
+	s.Attachment.RegisterJobs(worker)
+	if err := s.Idempotency.RegisterJobs(worker); err != nil {
+		return nil, err
+	}
+
+	if err := worker.SchedulePrune("@daily", 7*24*time.Hour); err != nil {
+		return nil, err
+	}
+	return worker, nil
+}
@@ -6,8 +6,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"net/http"
 
 	"finbro-backend-go/internal/config"
+	"finbro-backend-go/internal/tracing"
 
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/google"
@@ -43,13 +45,23 @@ func (g *GoogleOAuth) GetAuthURL(state string) string {
 	return g.Config.AuthCodeURL(state, oauth2.AccessTypeOnline)
 }
 
+// tracedContext makes the oauth2 package send its requests through a
+// traced HTTP client.
+func tracedContext(ctx context.Context) context.Context {
+	return context.WithValue(ctx, oauth2.HTTPClient, &http.Client{Transport: tracing.Transport(nil)})
+}
+
 func (g *GoogleOAuth) ExchangeCode(ctx context.Context, code string) (*oauth2.Token, error) {
-	return g.Config.Exchange(ctx, code)
+	return g.Config.Exchange(tracedContext(ctx), code)
 }
 
 func (g *GoogleOAuth) GetUserInfo(ctx context.Context, token *oauth2.Token) (*GoogleUser, error) {
-	client := g.Config.Client(ctx, token)
-	resp, err := client.Get("https://www.googleapis.com/oauth2/v3/userinfo")
+	client := g.Config.Client(tracedContext(ctx), token)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://www.googleapis.com/oauth2/v3/userinfo", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build user info request: %w", err)
+	}
+	resp, err := client.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user info: %w", err)
 	}
@@ -4,6 +4,8 @@ package config
 import (
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -13,7 +15,17 @@ import (
 type Config struct {
 	Environment string `yaml:"environment"`
 	Server      struct {
-		Address string `yaml:"address"`
+		Address           string        `yaml:"address"`
+		ReadTimeout       time.Duration `yaml:"read_timeout"`
+		ReadHeaderTimeout time.Duration `yaml:"read_header_timeout"`
+		WriteTimeout      time.Duration `yaml:"write_timeout"`
+		IdleTimeout       time.Duration `yaml:"idle_timeout"`
+		MaxHeaderBytes    int           `yaml:"max_header_bytes"`
+		MaxBodyBytes      int64         `yaml:"max_body_bytes"`
+		// ShutdownDelay keeps serving after readiness flips so load
+		// balancers can notice before the listener closes.
+		ShutdownDelay   time.Duration `yaml:"shutdown_delay"`
+		ShutdownTimeout time.Duration `yaml:"shutdown_timeout"`
 	} `yaml:"server"`
 	Database struct {
 		URL string `yaml:"url"`
@@ -26,30 +38,142 @@ type Config struct {
 		URL string `yaml:"url"`
 	} `yaml:"redis"`
 	Plaid struct {
-		ClientID string `yaml:"client_id"`
-		Secret   string `yaml:"secret"`
+		ClientID    string `yaml:"client_id"`
+		Secret      string `yaml:"secret"`
+		Environment string `yaml:"environment"` // sandbox, development, production
+		BaseURL     string `yaml:"base_url"`    // overrides Environment, e.g. a local fake
 	} `yaml:"plaid"`
+	BankLink struct {
+		// Provider is "plaid" or "fake". Empty disables bank linking.
+		Provider string `yaml:"provider"`
+		// EncryptionKey encrypts stored access tokens (32 bytes, hex or base64).
+		EncryptionKey string `yaml:"encryption_key"`
+	} `yaml:"bank_link"`
+	Metrics struct {
+		// Address serves /metrics on its own listener, e.g. ":9090". When
+		// empty, /metrics is mounted on the API and requires Token.
+		Address string `yaml:"address"`
+		Token   string `yaml:"token"`
+	} `yaml:"metrics"`
+	Tracing struct {
+		// Exporter is "otlp", "stdout" or empty to disable trace export.
+		Exporter    string  `yaml:"exporter"`
+		Endpoint    string  `yaml:"endpoint"` // OTLP/HTTP collector, e.g. localhost:4318
+		Insecure    bool    `yaml:"insecure"`
+		SampleRatio float64 `yaml:"sample_ratio"`
+		ServiceName string  `yaml:"service_name"`
+	} `yaml:"tracing"`
+	Health struct {
+		CheckTimeout time.Duration `yaml:"check_timeout"`
+		CacheTTL     time.Duration `yaml:"cache_ttl"`
+		// MaxJobLag is how long a due job may wait before the queue is
+		// reported as degraded.
+		MaxJobLag time.Duration `yaml:"max_job_lag"`
+	} `yaml:"health"`
+	Jobs struct {
+		// Embedded runs a job worker inside the API process. Disable it
+		// when jobs are handled by cmd/worker instead.
+		Embedded     bool          `yaml:"embedded"`
+		Queues       []string      `yaml:"queues"`
+		Concurrency  int           `yaml:"concurrency"`
+		DrainTimeout time.Duration `yaml:"drain_timeout"`
+	} `yaml:"jobs"`
+	PublicIDs struct {
+		// AcceptLegacy lets URLs and request bodies keep using sequential
+		// numeric IDs while clients move to public IDs.
+		AcceptLegacy bool `yaml:"accept_legacy"`
+	} `yaml:"public_ids"`
+	Attachments struct {
+		// Store is "local" or "s3".
+		Store    string `yaml:"store"`
+		LocalDir string `yaml:"local_dir"`
+		// MaxBytes caps a single upload. It must leave room for the
+		// multipart framing under Server.MaxBodyBytes.
+		MaxBytes int64 `yaml:"max_bytes"`
+		S3       struct {
+			// Endpoint is the S3 API base URL, e.g. https://s3.us-east-1.amazonaws.com
+			// or a local S3-compatible server such as MinIO.
+			Endpoint        string `yaml:"endpoint"`
+			Region          string `yaml:"region"`
+			Bucket          string `yaml:"bucket"`
+			AccessKeyID     string `yaml:"access_key_id"`
+			SecretAccessKey string `yaml:"secret_access_key"`
+			// PathStyle addresses objects as /bucket/key instead of through
+			// a bucket subdomain, as most local stand-ins require.
+			PathStyle bool `yaml:"path_style"`
+		} `yaml:"s3"`
+	} `yaml:"attachments"`
+	Webhooks struct {
+		// HMACSecrets maps inbound webhook provider names to shared secrets.
+		HMACSecrets map[string]string `yaml:"hmac_secrets"`
+	} `yaml:"webhooks"`
 	OpenAI struct {
-		APIKey string `yaml:"api_key"`
+		APIKey  string `yaml:"api_key"`
+		BaseURL string `yaml:"base_url"`
+		Model   string `yaml:"model"`
 	} `yaml:"openai"`
+	LLM struct {
+		// Provider is "openai" or "fake". Empty selects openai when an API
+		// key is configured and disables AI features otherwise.
+		Provider string `yaml:"provider"`
+	} `yaml:"llm"`
+	Categorizer struct {
+		// AutoApplyThreshold is the confidence above which a suggested
+		// category is applied automatically. 0 disables auto-categorization.
+		AutoApplyThreshold float64 `yaml:"auto_apply_threshold"`
+	} `yaml:"categorizer"`
 	Google struct {
 		ClientID     string `yaml:"client_id"`
 		ClientSecret string `yaml:"client_secret"`
 		RedirectURL  string `yaml:"redirect_url"`
 	} `yaml:"google"`
+	Log struct {
+		Level  string `yaml:"level"`  // debug, info, warn, error
+		Format string `yaml:"format"` // json, text
+	} `yaml:"log"`
+
+	// File is the YAML file the config was read from, if any.
+	File string `yaml:"-"`
+	// Warnings collects non-fatal problems found while loading, to be
+	// logged once the logger is configured.
+	Warnings []string `yaml:"-"`
 }
 
 // Load loads config from YAML file with environment variable overrides
 func Load() (*Config, error) {
 	// Load .env file if present
-	if err := godotenv.Load(); err != nil {
-		fmt.Println("Warning: .env file not found, using system environment")
-	}
+	dotenvErr := godotenv.Load()
 
 	// Default config
 	cfg := &Config{
 		Environment: getEnv("ENVIRONMENT", "development"),
 	}
+	if dotenvErr != nil {
+		cfg.warn(".env file not found, using system environment")
+	}
+	cfg.Log.Level = "info"
+	cfg.Log.Format = "json"
+	cfg.Server.ReadTimeout = 15 * time.Second
+	cfg.Server.ReadHeaderTimeout = 5 * time.Second
+	cfg.Server.WriteTimeout = 30 * time.Second
+	cfg.Server.IdleTimeout = 120 * time.Second
+	cfg.Server.MaxHeaderBytes = 1 << 20
+	cfg.Server.MaxBodyBytes = 10 << 20
+	cfg.Server.ShutdownTimeout = 30 * time.Second
+	cfg.Categorizer.AutoApplyThreshold = 0.85
+	cfg.Tracing.SampleRatio = 1
+	cfg.Tracing.ServiceName = "finbro-api"
+	cfg.Health.CheckTimeout = 2 * time.Second
+	cfg.Health.CacheTTL = 5 * time.Second
+	cfg.Health.MaxJobLag = 5 * time.Minute
+	cfg.Jobs.Embedded = true
+	cfg.Jobs.Concurrency = 4
+	cfg.Jobs.DrainTimeout = 30 * time.Second
+	cfg.PublicIDs.AcceptLegacy = true
+	cfg.Attachments.Store = "local"
+	cfg.Attachments.LocalDir = "data/attachments"
+	cfg.Attachments.MaxBytes = 8 << 20
+	cfg.Attachments.S3.Region = "us-east-1"
 
 	// Determine config file path
 	configFile := "configs/config.yaml"
@@ -62,11 +186,12 @@ func Load() (*Config, error) {
 	// Read and parse YAML
 	data, err := os.ReadFile(configFile)
 	if err != nil {
-		fmt.Printf("Warning: cannot read config file %s: %v\n", configFile, err)
+		cfg.warn(fmt.Sprintf("cannot read config file %s: %v", configFile, err))
 	} else {
 		if err := yaml.Unmarshal(data, cfg); err != nil {
 			return nil, fmt.Errorf("failed to parse config file: %w", err)
 		}
+		cfg.File = configFile
 	}
 
 	// Apply environment variable overrides
@@ -77,15 +202,42 @@ func Load() (*Config, error) {
 		return nil, err
 	}
 
-	fmt.Printf("Config loaded from %s (env: %s)\n", configFile, cfg.Environment)
 	return cfg, nil
 }
 
+func (c *Config) warn(message string) {
+	c.Warnings = append(c.Warnings, message)
+}
+
 func (c *Config) applyEnvOverrides() {
 	// Server
 	if addr := getEnv("SERVER_ADDRESS", ""); addr != "" {
 		c.Server.Address = addr
 	}
+	for key, target := range map[string]*time.Duration{
+		"SERVER_READ_TIMEOUT":        &c.Server.ReadTimeout,
+		"SERVER_READ_HEADER_TIMEOUT": &c.Server.ReadHeaderTimeout,
+		"SERVER_WRITE_TIMEOUT":       &c.Server.WriteTimeout,
+		"SERVER_IDLE_TIMEOUT":        &c.Server.IdleTimeout,
+		"SERVER_SHUTDOWN_DELAY":      &c.Server.ShutdownDelay,
+		"SERVER_SHUTDOWN_TIMEOUT":    &c.Server.ShutdownTimeout,
+	} {
+		if value := getEnv(key, ""); value != "" {
+			if d, err := time.ParseDuration(value); err == nil {
+				*target = d
+			}
+		}
+	}
+	if size := getEnv("SERVER_MAX_HEADER_BYTES", ""); size != "" {
+		if n, err := strconv.Atoi(size); err == nil {
+			c.Server.MaxHeaderBytes = n
+		}
+	}
+	if size := getEnv("SERVER_MAX_BODY_BYTES", ""); size != "" {
+		if n, err := strconv.ParseInt(size, 10, 64); err == nil {
+			c.Server.MaxBodyBytes = n
+		}
+	}
 
 	// Database
 	if url := getEnv("DATABASE_URL", ""); url != "" {
@@ -114,11 +266,158 @@ func (c *Config) applyEnvOverrides() {
 	if secret := getEnv("PLAID_SECRET", ""); secret != "" {
 		c.Plaid.Secret = secret
 	}
+	if env := getEnv("PLAID_ENV", ""); env != "" {
+		c.Plaid.Environment = env
+	}
+	if url := getEnv("PLAID_BASE_URL", ""); url != "" {
+		c.Plaid.BaseURL = url
+	}
+
+	// Bank linking
+	if provider := getEnv("BANK_LINK_PROVIDER", ""); provider != "" {
+		c.BankLink.Provider = provider
+	}
+	if key := getEnv("BANK_TOKEN_ENCRYPTION_KEY", ""); key != "" {
+		c.BankLink.EncryptionKey = key
+	}
 
 	// OpenAI
 	if key := getEnv("OPENAI_API_KEY", ""); key != "" {
 		c.OpenAI.APIKey = key
 	}
+	if url := getEnv("OPENAI_BASE_URL", ""); url != "" {
+		c.OpenAI.BaseURL = url
+	}
+	if model := getEnv("OPENAI_MODEL", ""); model != "" {
+		c.OpenAI.Model = model
+	}
+	if provider := getEnv("LLM_PROVIDER", ""); provider != "" {
+		c.LLM.Provider = provider
+	}
+
+	// Metrics
+	if addr := getEnv("METRICS_ADDRESS", ""); addr != "" {
+		c.Metrics.Address = addr
+	}
+	if token := getEnv("METRICS_TOKEN", ""); token != "" {
+		c.Metrics.Token = token
+	}
+
+	// Tracing
+	if exporter := getEnv("TRACING_EXPORTER", ""); exporter != "" {
+		c.Tracing.Exporter = exporter
+	}
+	if endpoint := getEnv("TRACING_ENDPOINT", ""); endpoint != "" {
+		c.Tracing.Endpoint = endpoint
+	}
+	if insecure := getEnv("TRACING_INSECURE", ""); insecure != "" {
+		if b, err := strconv.ParseBool(insecure); err == nil {
+			c.Tracing.Insecure = b
+		}
+	}
+	if ratio := getEnv("TRACING_SAMPLE_RATIO", ""); ratio != "" {
+		if r, err := strconv.ParseFloat(ratio, 64); err == nil {
+			c.Tracing.SampleRatio = r
+		}
+	}
+	if name := getEnv("TRACING_SERVICE_NAME", ""); name != "" {
+		c.Tracing.ServiceName = name
+	}
+
+	// Health checks
+	for key, target := range map[string]*time.Duration{
+		"HEALTH_CHECK_TIMEOUT": &c.Health.CheckTimeout,
+		"HEALTH_CACHE_TTL":     &c.Health.CacheTTL,
+		"HEALTH_MAX_JOB_LAG":   &c.Health.MaxJobLag,
+	} {
+		if value := getEnv(key, ""); value != "" {
+			if d, err := time.ParseDuration(value); err == nil {
+				*target = d
+			}
+		}
+	}
+
+	// Background jobs
+	if embedded := getEnv("JOBS_EMBEDDED", ""); embedded != "" {
+		if b, err := strconv.ParseBool(embedded); err == nil {
+			c.Jobs.Embedded = b
+		}
+	}
+	if queues := getEnv("JOBS_QUEUES", ""); queues != "" {
+		c.Jobs.Queues = strings.Split(queues, ",")
+	}
+	if concurrency := getEnv("JOBS_CONCURRENCY", ""); concurrency != "" {
+		if n, err := strconv.Atoi(concurrency); err == nil {
+			c.Jobs.Concurrency = n
+		}
+	}
+	if timeout := getEnv("JOBS_DRAIN_TIMEOUT", ""); timeout != "" {
+		if d, err := time.ParseDuration(timeout); err == nil {
+			c.Jobs.DrainTimeout = d
+		}
+	}
+
+	if accept := getEnv("PUBLIC_IDS_ACCEPT_LEGACY", ""); accept != "" {
+		if b, err := strconv.ParseBool(accept); err == nil {
+			c.PublicIDs.AcceptLegacy = b
+		}
+	}
+
+	// Attachments
+	if store := getEnv("ATTACHMENTS_STORE", ""); store != "" {
+		c.Attachments.Store = store
+	}
+	if dir := getEnv("ATTACHMENTS_DIR", ""); dir != "" {
+		c.Attachments.LocalDir = dir
+	}
+	if size := getEnv("ATTACHMENTS_MAX_BYTES", ""); size != "" {
+		if n, err := strconv.ParseInt(size, 10, 64); err == nil {
+			c.Attachments.MaxBytes = n
+		}
+	}
+	for key, target := range map[string]*string{
+		"ATTACHMENTS_S3_ENDPOINT":          &c.Attachments.S3.Endpoint,
+		"ATTACHMENTS_S3_REGION":            &c.Attachments.S3.Region,
+		"ATTACHMENTS_S3_BUCKET":            &c.Attachments.S3.Bucket,
+		"ATTACHMENTS_S3_ACCESS_KEY_ID":     &c.Attachments.S3.AccessKeyID,
+		"ATTACHMENTS_S3_SECRET_ACCESS_KEY": &c.Attachments.S3.SecretAccessKey,
+	} {
+		if value := getEnv(key, ""); value != "" {
+			*target = value
+		}
+	}
+	if pathStyle := getEnv("ATTACHMENTS_S3_PATH_STYLE", ""); pathStyle != "" {
+		if b, err := strconv.ParseBool(pathStyle); err == nil {
+			c.Attachments.S3.PathStyle = b
+		}
+	}
+
+	// Inbound webhooks, e.g. WEBHOOK_HMAC_SECRETS=acme:secret1,other:secret2
+	if secrets := getEnv("WEBHOOK_HMAC_SECRETS", ""); secrets != "" {
+		if c.Webhooks.HMACSecrets == nil {
+			c.Webhooks.HMACSecrets = make(map[string]string)
+		}
+		for _, pair := range strings.Split(secrets, ",") {
+			if name, secret, ok := strings.Cut(pair, ":"); ok {
+				c.Webhooks.HMACSecrets[strings.TrimSpace(name)] = strings.TrimSpace(secret)
+			}
+		}
+	}
+
+	// Categorizer
+	if threshold := getEnv("CATEGORIZER_AUTO_APPLY_THRESHOLD", ""); threshold != "" {
+		if t, err := strconv.ParseFloat(threshold, 64); err == nil {
+			c.Categorizer.AutoApplyThreshold = t
+		}
+	}
+
+	// Logging
+	if level := getEnv("LOG_LEVEL", ""); level != "" {
+		c.Log.Level = level
+	}
+	if format := getEnv("LOG_FORMAT", ""); format != "" {
+		c.Log.Format = format
+	}
 
 	// Google OAuth
 	if id := getEnv("GOOGLE_OAUTH_CLIENT_ID", ""); id != "" {
@@ -144,11 +443,79 @@ func (c *Config) validate() error {
 	if c.Plaid.ClientID != "" && c.Plaid.Secret == "" {
 		return fmt.Errorf("PLAID_SECRET required if PLAID_CLIENT_ID is set")
 	}
-	if c.OpenAI.APIKey == "" {
-		fmt.Println("Warning: OPENAI_API_KEY not set - AI features will be disabled")
+	if c.BankLink.Provider == "" && c.Plaid.ClientID != "" {
+		c.BankLink.Provider = "plaid"
+	}
+	switch c.BankLink.Provider {
+	case "":
+	case "plaid", "fake":
+		if c.BankLink.Provider == "plaid" && c.Plaid.ClientID == "" {
+			return fmt.Errorf("PLAID_CLIENT_ID required when BANK_LINK_PROVIDER is plaid")
+		}
+		if c.BankLink.EncryptionKey == "" {
+			return fmt.Errorf("BANK_TOKEN_ENCRYPTION_KEY required when bank linking is enabled")
+		}
+	default:
+		return fmt.Errorf("BANK_LINK_PROVIDER must be plaid or fake")
+	}
+	if c.Categorizer.AutoApplyThreshold < 0 || c.Categorizer.AutoApplyThreshold > 1 {
+		return fmt.Errorf("CATEGORIZER_AUTO_APPLY_THRESHOLD must be between 0 and 1")
+	}
+	switch strings.ToLower(c.Log.Level) {
+	case "debug", "info", "warn", "error":
+	default:
+		return fmt.Errorf("LOG_LEVEL must be debug, info, warn or error")
+	}
+	if c.Log.Format != "json" && c.Log.Format != "text" {
+		return fmt.Errorf("LOG_FORMAT must be json or text")
+	}
+	switch c.Tracing.Exporter {
+	case "", "otlp", "stdout":
+	default:
+		return fmt.Errorf("TRACING_EXPORTER must be otlp or stdout")
+	}
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		return fmt.Errorf("TRACING_SAMPLE_RATIO must be between 0 and 1")
+	}
+	if c.Server.ShutdownTimeout <= 0 {
+		return fmt.Errorf("SERVER_SHUTDOWN_TIMEOUT must be positive")
+	}
+	if c.Metrics.Address == "" && c.Metrics.Token == "" {
+		c.warn("METRICS_ADDRESS and METRICS_TOKEN not set - /metrics disabled")
+	}
+	if c.Jobs.Concurrency < 1 {
+		return fmt.Errorf("JOBS_CONCURRENCY must be at least 1")
+	}
+	switch c.LLM.Provider {
+	case "":
+		if c.OpenAI.APIKey == "" {
+			c.warn("OPENAI_API_KEY not set - AI features will be disabled")
+		}
+	case "openai", "fake":
+	default:
+		return fmt.Errorf("LLM_PROVIDER must be openai or fake")
+	}
+	switch c.Attachments.Store {
+	case "local":
+		if c.Attachments.LocalDir == "" {
+			return fmt.Errorf("ATTACHMENTS_DIR required when ATTACHMENTS_STORE is local")
+		}
+	case "s3":
+		s3 := c.Attachments.S3
+		if s3.Endpoint == "" || s3.Bucket == "" || s3.AccessKeyID == "" || s3.SecretAccessKey == "" {
+			return fmt.Errorf("ATTACHMENTS_S3_ENDPOINT, ATTACHMENTS_S3_BUCKET, ATTACHMENTS_S3_ACCESS_KEY_ID and ATTACHMENTS_S3_SECRET_ACCESS_KEY required when ATTACHMENTS_STORE is s3")
+		}
+	default:
+		return fmt.Errorf("ATTACHMENTS_STORE must be local or s3")
+	}
+	if c.Attachments.MaxBytes <= 0 {
+		return fmt.Errorf("ATTACHMENTS_MAX_BYTES must be positive")
+	}
+	if c.Server.MaxBodyBytes > 0 && c.Attachments.MaxBytes >= c.Server.MaxBodyBytes {
+		return fmt.Errorf("ATTACHMENTS_MAX_BYTES must be below SERVER_MAX_BODY_BYTES")
 	}
 	if c.Google.ClientID == "" || c.Google.ClientSecret == "" {
-		fmt.Println("Warning: Google OAuth credentials missing - Google login disabled")
+		c.warn("Google OAuth credentials missing - Google login disabled")
 	}
 
 	return nil
@@ -0,0 +1,297 @@
+// internal/db/categories.go
+package db
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"finbro-backend-go/internal/db/models"
+	"finbro-backend-go/internal/utils"
+
+	"gorm.io/gorm"
+)
+
+type seedCategory struct {
+	Name     string
+	Icon     string
+	Color    string
+	Children []string
+}
+
+// systemCategories is the built-in taxonomy every user sees. Children inherit
+// the icon and color of their parent.
+var systemCategories = []seedCategory{
+	{Name: "Income", Icon: "wallet", Color: "#2E7D32", Children: []string{"Salary", "Interest", "Refunds", "Other Income"}},
+	{Name: "Food & Dining", Icon: "utensils", Color: "#EF6C00", Children: []string{"Groceries", "Restaurants", "Coffee Shops", "Fast Food"}},
+	{Name: "Transportation", Icon: "car", Color: "#1565C0", Children: []string{"Fuel", "Public Transit", "Rideshare", "Parking"}},
+	{Name: "Housing", Icon: "home", Color: "#6D4C41", Children: []string{"Rent", "Mortgage", "Utilities", "Home Improvement"}},
+	{Name: "Shopping", Icon: "shopping-bag", Color: "#AD1457", Children: []string{"Clothing", "Electronics", "Household"}},
+	{Name: "Entertainment", Icon: "film", Color: "#6A1B9A", Children: []string{"Streaming", "Events", "Games"}},
+	{Name: "Health", Icon: "heart", Color: "#C62828", Children: []string{"Pharmacy", "Doctor", "Fitness"}},
+	{Name: "Travel", Icon: "plane", Color: "#00838F", Children: []string{"Flights", "Hotels"}},
+	{Name: "Bills & Subscriptions", Icon: "receipt", Color: "#455A64", Children: []string{"Phone", "Internet", "Insurance", "Subscriptions"}},
+	{Name: "Transfers", Icon: "arrows", Color: "#546E7A"},
+	{Name: "Fees & Charges", Icon: "percent", Color: "#8D6E63"},
+	{Name: "Uncategorized", Icon: "question", Color: "#9E9E9E"},
+}
+
+// legacyCategoryAliases maps common free-form strings from before the
+// taxonomy existed onto system category slugs.
+var legacyCategoryAliases = map[string]string{
+	"food":          "food-and-dining",
+	"dining":        "restaurants",
+	"restaurant":    "restaurants",
+	"grocery":       "groceries",
+	"coffee":        "coffee-shops",
+	"gas":           "fuel",
+	"petrol":        "fuel",
+	"transport":     "transportation",
+	"travel":        "travel",
+	"uber":          "rideshare",
+	"bills":         "bills-and-subscriptions",
+	"subscription":  "subscriptions",
+	"utility":       "utilities",
+	"rent":          "rent",
+	"shopping":      "shopping",
+	"entertainment": "entertainment",
+	"health":        "health",
+	"medical":       "doctor",
+	"salary":        "salary",
+	"paycheck":      "salary",
+	"income":        "income",
+	"transfer":      "transfers",
+	"fees":          "fees-and-charges",
+	"other":         "uncategorized",
+}
+
+// SeedCategories creates the system taxonomy. It is safe to run on every
+// start: existing system categories are matched by slug and left untouched.
+func SeedCategories(db *DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, seed := range systemCategories {
+			parent, err := firstOrCreateSystemCategory(tx, seed.Name, seed.Icon, seed.Color, nil)
+			if err != nil {
+				return err
+			}
+			for _, child := range seed.Children {
+				if _, err := firstOrCreateSystemCategory(tx, child, seed.Icon, seed.Color, &parent.ID); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+func firstOrCreateSystemCategory(tx *gorm.DB, name, icon, color string, parentID *uint) (*models.Category, error) {
+	category := models.Category{
+		Name:     name,
+		Slug:     utils.Slugify(name),
+		Icon:     icon,
+		Color:    color,
+		ParentID: parentID,
+		IsSystem: true,
+	}
+	err := tx.Where("user_id IS NULL AND slug = ?", category.Slug).
+		FirstOrCreate(&category).Error
+	return &category, err
+}
+
+// migrateLegacyCategories links transactions and budgets that only carry a
+// free-form category string to a Category record. Strings are matched case
+// and whitespace insensitively against system categories and known aliases;
+// anything else becomes a custom category of the owning user.
+func migrateLegacyCategories(db *DB) error {
+	for _, table := range []string{"transactions", "budgets"} {
+		var legacy []struct {
+			UserID   uint
+			Category string
+		}
+		err := db.Table(table).
+			Select("user_id, MIN(category) AS category").
+			Where("category_id IS NULL AND TRIM(COALESCE(category, '')) <> ''").
+			Group("user_id, LOWER(TRIM(category))").
+			Scan(&legacy).Error
+		if err != nil {
+			return err
+		}
+
+		for _, row := range legacy {
+			category, err := resolveLegacyCategory(db, row.UserID, row.Category)
+			if err != nil {
+				return err
+			}
+
+			err = db.Table(table).
+				Where("user_id = ? AND category_id IS NULL", row.UserID).
+				Where("LOWER(TRIM(category)) = ?", strings.ToLower(strings.TrimSpace(row.Category))).
+				Updates(map[string]interface{}{
+					"category_id": category.ID,
+					"category":    category.Name,
+				}).Error
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func resolveLegacyCategory(db *DB, userID uint, name string) (*models.Category, error) {
+	slug := utils.Slugify(name)
+	if slug == "" {
+		// Nothing but punctuation or symbols; there is no name to keep.
+		slug = "uncategorized"
+	}
+	candidates := []string{slug}
+	if alias, ok := legacyCategoryAliases[slug]; ok {
+		candidates = append(candidates, alias)
+	}
+	if trimmed := strings.TrimSuffix(slug, "s"); trimmed != slug {
+		if alias, ok := legacyCategoryAliases[trimmed]; ok {
+			candidates = append(candidates, alias)
+		}
+	}
+
+	var category models.Category
+	for _, candidate := range candidates {
+		err := db.Where("slug = ? AND (user_id IS NULL OR user_id = ?)", candidate, userID).
+			Order("user_id NULLS FIRST").
+			First(&category).Error
+		if err == nil {
+			return &category, nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+	}
+
+	category = models.Category{
+		UserID: &userID,
+		Name:   strings.TrimSpace(name),
+		Slug:   slug,
+	}
+	if err := db.Create(&category).Error; err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
+// migrateCategorySlugs runs once, before the unique slug indexes exist. It
+// recomputes custom category slugs, which used to drop every non-ASCII
+// letter, and folds custom categories that share a slug with a system
+// category or with another of the user's categories into the older one.
+func migrateCategorySlugs(db *DB) error {
+	var indexed bool
+	if err := db.Raw(`SELECT to_regclass('idx_categories_user_slug') IS NOT NULL`).Scan(&indexed).Error; err != nil {
+		return err
+	}
+	if indexed {
+		return nil
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		var system []models.Category
+		if err := tx.Where("user_id IS NULL").Find(&system).Error; err != nil {
+			return err
+		}
+		systemBySlug := make(map[string]models.Category, len(system))
+		for _, category := range system {
+			systemBySlug[category.Slug] = category
+		}
+
+		var custom []models.Category
+		if err := tx.Where("user_id IS NOT NULL").Order("id").Find(&custom).Error; err != nil {
+			return err
+		}
+
+		type key struct {
+			userID uint
+			slug   string
+		}
+		kept := make(map[key]models.Category)
+		for _, category := range custom {
+			slug := utils.Slugify(category.Name)
+			if slug == "" {
+				slug = fmt.Sprintf("category-%d", category.ID)
+			}
+
+			if target, ok := systemBySlug[slug]; ok {
+				if err := mergeCategory(tx, category, target); err != nil {
+					return err
+				}
+				continue
+			}
+			k := key{*category.UserID, slug}
+			if target, ok := kept[k]; ok {
+				if err := mergeCategory(tx, category, target); err != nil {
+					return err
+				}
+				continue
+			}
+
+			if slug != category.Slug {
+				if err := tx.Model(&models.Category{}).Where("id = ?", category.ID).
+					UpdateColumn("slug", slug).Error; err != nil {
+					return err
+				}
+				category.Slug = slug
+			}
+			kept[k] = category
+		}
+
+		statements := []string{
+			`CREATE UNIQUE INDEX IF NOT EXISTS idx_categories_system_slug ON categories (slug) WHERE user_id IS NULL`,
+			`CREATE UNIQUE INDEX IF NOT EXISTS idx_categories_user_slug ON categories (user_id, slug) WHERE user_id IS NOT NULL`,
+		}
+		for _, stmt := range statements {
+			if err := tx.Exec(stmt).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// mergeCategory moves everything that points at a duplicate custom category
+// onto target and deletes the duplicate.
+func mergeCategory(tx *gorm.DB, duplicate, target models.Category) error {
+	userID := *duplicate.UserID
+	for _, table := range []string{"transactions", "transaction_splits", "budgets"} {
+		if err := tx.Table(table).
+			Where("user_id = ? AND category_id = ?", userID, duplicate.ID).
+			Updates(map[string]interface{}{"category_id": target.ID, "category": target.Name}).Error; err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Exec(`UPDATE rules SET actions = jsonb_set(actions, '{set_category_id}', to_jsonb(?::bigint))
+		WHERE user_id = ? AND actions->>'set_category_id' = ?`,
+		target.ID, userID, strconv.FormatUint(uint64(duplicate.ID), 10)).Error; err != nil {
+		return err
+	}
+
+	if target.ParentID != nil && *target.ParentID == duplicate.ID {
+		if err := tx.Model(&models.Category{}).Where("id = ?", target.ID).
+			UpdateColumn("parent_id", duplicate.ParentID).Error; err != nil {
+			return err
+		}
+		target.ParentID = duplicate.ParentID
+	}
+
+	// Categories are two levels deep, so children of the duplicate hang off
+	// target only when target is itself top-level.
+	parentID := target.ID
+	if target.ParentID != nil {
+		parentID = *target.ParentID
+	}
+	if err := tx.Model(&models.Category{}).
+		Where("parent_id = ?", duplicate.ID).
+		UpdateColumn("parent_id", parentID).Error; err != nil {
+		return err
+	}
+
+	return tx.Delete(&models.Category{}, duplicate.ID).Error
+}
@@ -2,8 +2,12 @@ package db
 
 import (
 	"fmt"
+	"time"
 
 	"finbro-backend-go/internal/db/models"
+	"finbro-backend-go/internal/publicid"
+	"finbro-backend-go/internal/tracing"
+	"finbro-backend-go/internal/utils"
 
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
@@ -22,6 +26,10 @@ func Initialize(databaseURL string) (*DB, error) {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
 
+	if err := db.Use(tracing.GormPlugin{}); err != nil {
+		return nil, fmt.Errorf("failed to register tracing plugin: %w", err)
+	}
+
 	// Configure connection pool
 	sqlDB, err := db.DB()
 	if err != nil {
@@ -43,10 +51,189 @@ func (db *DB) Close() error {
 }
 
 func Migrate(db *DB) error {
-	return db.AutoMigrate(
+	if err := db.AutoMigrate(
 		&models.User{},
 		&models.Account{},
 		&models.Transaction{},
+		&models.TransactionSplit{},
+		&models.Attachment{},
 		&models.Budget{},
-	)
+		&models.Category{},
+		&models.Tag{},
+		&models.Rule{},
+		&models.BankItem{},
+		&models.WebhookEvent{},
+		&models.WebhookSubscription{},
+		&models.WebhookDelivery{},
+		&models.Job{},
+		&models.IdempotencyKey{},
+	); err != nil {
+		return err
+	}
+
+	if err := SeedCategories(db); err != nil {
+		return fmt.Errorf("failed to seed categories: %w", err)
+	}
+	if err := migrateLegacyCategories(db); err != nil {
+		return fmt.Errorf("failed to migrate legacy categories: %w", err)
+	}
+	if err := migrateCategorySlugs(db); err != nil {
+		return fmt.Errorf("failed to migrate category slugs: %w", err)
+	}
+
+	if err := migrateTransactionSearch(db); err != nil {
+		return fmt.Errorf("failed to migrate transaction search: %w", err)
+	}
+
+	if err := migratePublicIDs(db); err != nil {
+		return fmt.Errorf("failed to backfill public IDs: %w", err)
+	}
+
+	if err := migrateRowVersions(db); err != nil {
+		return fmt.Errorf("failed to migrate row versions: %w", err)
+	}
+
+	if err := migrateTagCascades(db); err != nil {
+		return fmt.Errorf("failed to migrate transaction tag constraints: %w", err)
+	}
+
+	if err := migrateRuleAccountRefs(db); err != nil {
+		return fmt.Errorf("failed to migrate rule account references: %w", err)
+	}
+
+	// Deliveries used to store the endpoint's reply, which the delivery log
+	// then showed to the subscriber.
+	if err := db.Exec(`ALTER TABLE webhook_deliveries DROP COLUMN IF EXISTS response_body`).Error; err != nil {
+		return fmt.Errorf("failed to drop webhook response bodies: %w", err)
+	}
+
+	// Provider IDs used to be unique across all users. AutoMigrate has added
+	// the per-item and per-account indexes; drop the global ones.
+	for _, index := range []string{"idx_accounts_external_id", "idx_transactions_external_id"} {
+		if err := db.Exec(`DROP INDEX IF EXISTS ` + index).Error; err != nil {
+			return fmt.Errorf("failed to drop %s: %w", index, err)
+		}
+	}
+
+	if err := recordSchemaVersion(db); err != nil {
+		return fmt.Errorf("failed to record schema version: %w", err)
+	}
+
+	return nil
+}
+
+// migrateTransactionSearch adds the generated tsvector column used by the
+// transaction search endpoint and backfills merchant names for rows created
+// before the column existed.
+func migrateTransactionSearch(db *DB) error {
+	statements := []string{
+		`ALTER TABLE transactions ADD COLUMN IF NOT EXISTS search_vector tsvector
+			GENERATED ALWAYS AS (
+				setweight(to_tsvector('english'::regconfig, coalesce(merchant, '')), 'A') ||
+				setweight(to_tsvector('english'::regconfig, coalesce(description, '')), 'B') ||
+				setweight(to_tsvector('english'::regconfig, coalesce(category, '')), 'C')
+			) STORED`,
+		`CREATE INDEX IF NOT EXISTS idx_transactions_search_vector ON transactions USING GIN (search_vector)`,
+	}
+	for _, stmt := range statements {
+		if err := db.Exec(stmt).Error; err != nil {
+			return err
+		}
+	}
+
+	var pending []models.Transaction
+	return db.Select("id", "description").
+		Where("merchant IS NULL OR merchant = ''").
+		Where("description <> ''").
+		FindInBatches(&pending, 500, func(tx *gorm.DB, batch int) error {
+			for _, t := range pending {
+				merchant := utils.NormalizeMerchant(t.Description)
+				if err := tx.Model(&models.Transaction{}).Where("id = ?", t.ID).
+					UpdateColumn("merchant", merchant).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		}).Error
+}
+
+// migrateTagCascades recreates the transaction_tags foreign keys with ON
+// DELETE CASCADE. AutoMigrate only adds missing constraints, so databases
+// created before Transaction.Tags declared the cascade keep the old keys,
+// which block deleting any tagged transaction or tag.
+func migrateTagCascades(db *DB) error {
+	constraints := []struct{ name, column, references string }{
+		{"fk_transaction_tags_transaction", "transaction_id", "transactions"},
+		{"fk_transaction_tags_tag", "tag_id", "tags"},
+	}
+	for _, c := range constraints {
+		var onDelete string
+		if err := db.Raw(`SELECT confdeltype FROM pg_constraint
+			WHERE conname = ? AND conrelid = 'transaction_tags'::regclass`, c.name).
+			Scan(&onDelete).Error; err != nil {
+			return err
+		}
+		if onDelete == "c" {
+			continue
+		}
+		if err := db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Exec(`ALTER TABLE transaction_tags DROP CONSTRAINT IF EXISTS ` + c.name).Error; err != nil {
+				return err
+			}
+			return tx.Exec(`ALTER TABLE transaction_tags ADD CONSTRAINT ` + c.name +
+				` FOREIGN KEY (` + c.column + `) REFERENCES ` + c.references + `(id) ON DELETE CASCADE`).Error
+		}); err != nil {
+			return fmt.Errorf("%s: %w", c.name, err)
+		}
+	}
+	return nil
+}
+
+// migratePublicIDs backfills public IDs for rows created before the column
+// existed. IDs take the row's creation time so they sort the same way.
+func migratePublicIDs(db *DB) error {
+	type row struct {
+		ID        uint
+		CreatedAt time.Time
+	}
+
+	for _, table := range []string{"users", "accounts", "transactions", "budgets", "bank_items"} {
+		var pending []row
+		err := db.Table(table).Select("id", "created_at").
+			Where("public_id IS NULL OR public_id = ''").
+			FindInBatches(&pending, 500, func(tx *gorm.DB, batch int) error {
+				for _, r := range pending {
+					if err := tx.Table(table).Where("id = ?", r.ID).
+						UpdateColumn("public_id", publicid.NewAt(r.CreatedAt)).Error; err != nil {
+						return err
+					}
+				}
+				return nil
+			}).Error
+		if err != nil {
+			return fmt.Errorf("%s: %w", table, err)
+		}
+	}
+
+	if err := db.Exec(`UPDATE transactions SET account_public_id = accounts.public_id
+		FROM accounts
+		WHERE accounts.id = transactions.account_id
+			AND (transactions.account_public_id IS NULL OR transactions.account_public_id = '')`).Error; err != nil {
+		return err
+	}
+
+	return db.Exec(`UPDATE accounts SET bank_item_public_id = bank_items.public_id
+		FROM bank_items
+		WHERE bank_items.id = accounts.bank_item_id
+			AND (accounts.bank_item_public_id IS NULL OR accounts.bank_item_public_id = '')`).Error
+}
+
+// migrateRuleAccountRefs rewrites rule account conditions stored as numeric
+// account IDs to the account's public ID.
+func migrateRuleAccountRefs(db *DB) error {
+	return db.Exec(`UPDATE rules SET conditions = jsonb_set(rules.conditions, '{account_id}', to_jsonb(accounts.public_id))
+		FROM accounts
+		WHERE jsonb_typeof(rules.conditions->'account_id') = 'number'
+			AND accounts.id = (rules.conditions->>'account_id')::bigint
+			AND accounts.user_id = rules.user_id`).Error
 }
@@ -0,0 +1,118 @@
+// internal/db/dbtest/dbtest.go
+package dbtest
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+
+	"finbro-backend-go/internal/db"
+	"finbro-backend-go/internal/db/models"
+	"finbro-backend-go/internal/publicid"
+)
+
+// migrateLockID serializes Migrate across test binaries sharing the database.
+const migrateLockID = 727001
+
+var (
+	once     sync.Once
+	database *db.DB
+	openErr  error
+)
+
+// Open returns the migrated scratch database named by TEST_DATABASE_URL,
+// skipping the test when it is unset. Test packages run in parallel against
+// the same database, so tests work on their own user instead of emptying
+// tables.
+func Open(t testing.TB) *db.DB {
+	t.Helper()
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	once.Do(func() {
+		database, openErr = open(url)
+	})
+	if openErr != nil {
+		t.Fatalf("failed to open test database: %v", openErr)
+	}
+	return database
+}
+
+func open(url string) (*db.DB, error) {
+	database, err := db.Initialize(url)
+	if err != nil {
+		return nil, err
+	}
+
+	sqlDB, err := database.DB.DB()
+	if err != nil {
+		return nil, err
+	}
+	ctx := context.Background()
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", migrateLockID); err != nil {
+		return nil, err
+	}
+	defer conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", migrateLockID)
+
+	if err := db.Migrate(database); err != nil {
+		return nil, fmt.Errorf("migrate: %w", err)
+	}
+	return database, nil
+}
+
+// CreateUser adds a user with a unique email address.
+func CreateUser(t testing.TB, database *db.DB) *models.User {
+	t.Helper()
+	user := &models.User{
+		Email:     "test-" + publicid.New() + "@example.com",
+		Password:  "-",
+		FirstName: "Test",
+	}
+	if err := database.Create(user).Error; err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+	return user
+}
+
+// CreateAccount adds a checking account for the user.
+func CreateAccount(t testing.TB, database *db.DB, userID uint) *models.Account {
+	t.Helper()
+	account := &models.Account{
+		UserID:      userID,
+		AccountName: "Checking",
+		AccountType: "checking",
+		Currency:    "USD",
+		IsActive:    true,
+	}
+	if err := database.Create(account).Error; err != nil {
+		t.Fatalf("failed to create account: %v", err)
+	}
+	return account
+}
+
+// CreateTransaction adds a debit on the account.
+func CreateTransaction(t testing.TB, database *db.DB, account *models.Account, description string, amount float64) *models.Transaction {
+	t.Helper()
+	transaction := &models.Transaction{
+		UserID:          account.UserID,
+		AccountID:       account.ID,
+		AccountPublicID: account.PublicID,
+		Amount:          amount,
+		Description:     description,
+		Type:            "debit",
+	}
+	if err := database.Create(transaction).Error; err != nil {
+		t.Fatalf("failed to create transaction: %v", err)
+	}
+	return transaction
+}
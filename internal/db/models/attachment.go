@@ -0,0 +1,40 @@
+// internal/db/models/attachment.go
+package models
+
+import (
+	"time"
+
+	"finbro-backend-go/internal/publicid"
+
+	"gorm.io/gorm"
+)
+
+// Attachment is a file, such as a receipt, stored alongside a transaction.
+// The bytes live in the blob store under StorageKey; images also get a JPEG
+// thumbnail under ThumbnailKey.
+type Attachment struct {
+	ID            uint      `json:"-" gorm:"primaryKey"`
+	PublicID      string    `json:"id" gorm:"size:26;uniqueIndex"`
+	UserID        uint      `json:"-" gorm:"not null;index"`
+	TransactionID uint      `json:"-" gorm:"not null;index"`
+	Filename      string    `json:"filename" gorm:"not null"`
+	ContentType   string    `json:"content_type" gorm:"not null"` // sniffed from the content, not the upload's header
+	Size          int64     `json:"size" gorm:"not null"`
+	SHA256        string    `json:"sha256" gorm:"size:64;not null"`
+	StorageKey    string    `json:"-" gorm:"not null"`
+	ThumbnailKey  string    `json:"-"`
+	HasThumbnail  bool      `json:"has_thumbnail" gorm:"-"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+func (a *Attachment) BeforeCreate(tx *gorm.DB) error {
+	if a.PublicID == "" {
+		a.PublicID = publicid.New()
+	}
+	return nil
+}
+
+func (a *Attachment) AfterFind(tx *gorm.DB) error {
+	a.HasThumbnail = a.ThumbnailKey != ""
+	return nil
+}
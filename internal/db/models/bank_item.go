@@ -0,0 +1,44 @@
+// internal/db/models/bank_item.go
+package models
+
+import (
+	"time"
+
+	"finbro-backend-go/internal/publicid"
+
+	"gorm.io/gorm"
+)
+
+const (
+	BankItemActive        = "active"
+	BankItemLoginRequired = "login_required"
+	BankItemError         = "error"
+)
+
+// BankItem is a login at a financial institution linked through a bank data
+// aggregator. AccessToken is stored encrypted.
+type BankItem struct {
+	ID              uint       `json:"-" gorm:"primaryKey"`
+	PublicID        string     `json:"id" gorm:"size:26;uniqueIndex"`
+	UserID          uint       `json:"-" gorm:"not null;index"`
+	Provider        string     `json:"provider" gorm:"not null"`
+	ItemID          string     `json:"item_id" gorm:"not null;uniqueIndex"`
+	AccessToken     string     `json:"-" gorm:"not null"`
+	InstitutionName string     `json:"institution_name"`
+	Cursor          string     `json:"-"`
+	Status          string     `json:"status" gorm:"default:active"`
+	LastError       string     `json:"last_error,omitempty"`
+	LastSyncedAt    *time.Time `json:"last_synced_at"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+
+	// Relationships
+	Accounts []Account `json:"accounts,omitempty"`
+}
+
+func (b *BankItem) BeforeCreate(tx *gorm.DB) error {
+	if b.PublicID == "" {
+		b.PublicID = publicid.New()
+	}
+	return nil
+}
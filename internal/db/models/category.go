@@ -0,0 +1,22 @@
+// internal/db/models/category.go
+package models
+
+import "time"
+
+// Category is either a seeded system category (UserID nil) or a custom
+// category owned by a single user. Categories form a two-level hierarchy.
+type Category struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	UserID    *uint     `json:"-" gorm:"index"`
+	ParentID  *uint     `json:"parent_id,omitempty" gorm:"index"`
+	Name      string    `json:"name" gorm:"not null"`
+	Slug      string    `json:"slug" gorm:"not null;index"`
+	Icon      string    `json:"icon"`
+	Color     string    `json:"color"`
+	IsSystem  bool      `json:"is_system" gorm:"default:false"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// Relationships
+	Children []Category `json:"children,omitempty" gorm:"foreignKey:ParentID"`
+}
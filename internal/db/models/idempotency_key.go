@@ -0,0 +1,29 @@
+// internal/db/models/idempotency_key.go
+package models
+
+import "time"
+
+const (
+	IdempotencyProcessing = "processing"
+	IdempotencyCompleted  = "completed"
+)
+
+// IdempotencyKey records a mutating request made with an Idempotency-Key
+// header so a retry can be answered with the original response. The row
+// doubles as a lock while the first request is still running.
+type IdempotencyKey struct {
+	ID              uint      `json:"id" gorm:"primaryKey"`
+	UserID          uint      `json:"user_id" gorm:"not null;uniqueIndex:idx_idempotency_keys_user_key"`
+	Key             string    `json:"key" gorm:"size:255;not null;uniqueIndex:idx_idempotency_keys_user_key"`
+	Method          string    `json:"method"`
+	Path            string    `json:"path"`
+	RequestHash     string    `json:"request_hash" gorm:"not null"`
+	Status          string    `json:"status" gorm:"not null;default:processing"`
+	ResponseStatus  int       `json:"response_status"`
+	ResponseHeaders string    `json:"response_headers" gorm:"type:text"` // JSON object of replayed headers
+	ResponseBody    []byte    `json:"-"`
+	LockedUntil     time.Time `json:"locked_until"`
+	ExpiresAt       time.Time `json:"expires_at" gorm:"index"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
@@ -0,0 +1,31 @@
+// internal/db/models/job.go
+package models
+
+import "time"
+
+const (
+	JobPending   = "pending"
+	JobRunning   = "running"
+	JobSucceeded = "succeeded"
+	JobDead      = "dead"
+)
+
+// Job is one unit of background work in the Postgres-backed queue. Jobs
+// that exhaust their attempts are kept with status dead for inspection.
+type Job struct {
+	ID          uint       `json:"id" gorm:"primaryKey"`
+	Queue       string     `json:"queue" gorm:"not null;default:default;index:idx_jobs_claim,priority:1"`
+	Type        string     `json:"type" gorm:"not null;index"`
+	Payload     string     `json:"payload" gorm:"type:jsonb;not null;default:'{}'"`
+	Status      string     `json:"status" gorm:"not null;default:pending;index:idx_jobs_claim,priority:2"`
+	RunAt       time.Time  `json:"run_at" gorm:"not null;index:idx_jobs_claim,priority:3"`
+	Attempts    int        `json:"attempts" gorm:"default:0"`
+	MaxAttempts int        `json:"max_attempts" gorm:"default:10"`
+	UniqueKey   *string    `json:"unique_key,omitempty" gorm:"uniqueIndex"`
+	LockedBy    string     `json:"locked_by,omitempty"`
+	LockedAt    *time.Time `json:"locked_at"`
+	LastError   string     `json:"last_error,omitempty"`
+	FinishedAt  *time.Time `json:"finished_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
@@ -0,0 +1,39 @@
+// internal/db/models/rule.go
+package models
+
+import (
+	"time"
+
+	"finbro-backend-go/internal/publicid"
+)
+
+// Rule automatically edits transactions that match all of its conditions.
+// Rules run in ascending Priority order; StopProcessing ends the chain after
+// the rule has been applied.
+type Rule struct {
+	ID             uint           `json:"id" gorm:"primaryKey"`
+	UserID         uint           `json:"-" gorm:"not null;index"`
+	Name           string         `json:"name" gorm:"not null"`
+	Priority       int            `json:"priority" gorm:"default:100"`
+	IsActive       bool           `json:"is_active" gorm:"default:true"`
+	StopProcessing bool           `json:"stop_processing" gorm:"default:false"`
+	Conditions     RuleConditions `json:"conditions" gorm:"type:jsonb;serializer:json"`
+	Actions        RuleActions    `json:"actions" gorm:"type:jsonb;serializer:json"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+}
+
+type RuleConditions struct {
+	DescriptionContains string        `json:"description_contains,omitempty"`
+	DescriptionRegex    string        `json:"description_regex,omitempty"`
+	MinAmount           *float64      `json:"min_amount,omitempty"`
+	MaxAmount           *float64      `json:"max_amount,omitempty"`
+	AccountID           *publicid.Ref `json:"account_id,omitempty"` // account public ID
+	Type                string        `json:"type,omitempty"`       // debit, credit
+}
+
+type RuleActions struct {
+	SetCategoryID     *uint    `json:"set_category_id,omitempty"`
+	AddTags           []string `json:"add_tags,omitempty"`
+	RenameDescription string   `json:"rename_description,omitempty"`
+}
@@ -0,0 +1,19 @@
+// internal/db/models/transaction_split.go
+package models
+
+import "time"
+
+// TransactionSplit is one line of a transaction divided across categories.
+// When a transaction has splits their amounts add up to its amount, and
+// reports count the splits instead of the transaction's own category.
+type TransactionSplit struct {
+	ID            uint      `json:"-" gorm:"primaryKey"`
+	TransactionID uint      `json:"-" gorm:"not null;index"`
+	UserID        uint      `json:"-" gorm:"not null;index"`
+	Amount        float64   `json:"amount" gorm:"not null"`
+	CategoryID    *uint     `json:"category_id" gorm:"index"`
+	Category      string    `json:"category"` // denormalized category name
+	Memo          string    `json:"memo"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
@@ -4,6 +4,9 @@ package models
 import (
 	"time"
 
+	"finbro-backend-go/internal/publicid"
+	"finbro-backend-go/internal/utils"
+
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 )
@@ -15,8 +18,12 @@ const (
 	Business   UserType = "business"
 )
 
+// User, like the other models exposed through the API, carries a ULID public
+// ID serialized as "id". The sequential primary key stays internal and is
+// only used for joins.
 type User struct {
-	ID          uint      `json:"id" gorm:"primaryKey"`
+	ID          uint      `json:"-" gorm:"primaryKey"`
+	PublicID    string    `json:"id" gorm:"size:26;uniqueIndex"`
 	Email       string    `json:"email" gorm:"uniqueIndex;not null"`
 	Password    string    `json:"-" gorm:"not null"`
 	FirstName   string    `json:"first_name"`
@@ -50,6 +57,9 @@ func (u *User) CheckPassword(password string) bool {
 }
 
 func (u *User) BeforeCreate(tx *gorm.DB) error {
+	if u.PublicID == "" {
+		u.PublicID = publicid.New()
+	}
 	if u.UserType == "" {
 		u.UserType = Individual
 	}
@@ -57,17 +67,22 @@ func (u *User) BeforeCreate(tx *gorm.DB) error {
 }
 
 type Account struct {
-	ID            uint      `json:"id" gorm:"primaryKey"`
-	UserID        uint      `json:"user_id" gorm:"not null"`
-	AccountName   string    `json:"account_name" gorm:"not null"`
-	AccountType   string    `json:"account_type"`
-	Balance       float64   `json:"balance" gorm:"default:0"`
-	Currency      string    `json:"currency" gorm:"default:USD"`
-	BankName      string    `json:"bank_name"`
-	AccountNumber string    `json:"account_number"`
-	IsActive      bool      `json:"is_active" gorm:"default:true"`
-	CreatedAt     time.Time `json:"created_at"`
-	UpdatedAt     time.Time `json:"updated_at"`
+	ID               uint      `json:"-" gorm:"primaryKey"`
+	PublicID         string    `json:"id" gorm:"size:26;uniqueIndex"`
+	UserID           uint      `json:"-" gorm:"not null"`
+	AccountName      string    `json:"account_name" gorm:"not null"`
+	AccountType      string    `json:"account_type"`
+	Balance          float64   `json:"balance" gorm:"default:0"`
+	Currency         string    `json:"currency" gorm:"default:USD"`
+	BankName         string    `json:"bank_name"`
+	AccountNumber    string    `json:"account_number"`
+	IsActive         bool      `json:"is_active" gorm:"default:true"`
+	BankItemID       *uint     `json:"-" gorm:"index;uniqueIndex:idx_accounts_bank_item_external_id"`
+	BankItemPublicID *string   `json:"bank_item_id,omitempty" gorm:"size:26"` // denormalized bank item public ID
+	ExternalID       *string   `json:"-" gorm:"uniqueIndex:idx_accounts_bank_item_external_id"`
+	Version          int       `json:"version" gorm:"not null;default:1"` // bumped by a trigger on every update
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
 
 	// Relationships
 	User         User          `json:"user,omitempty"`
@@ -75,36 +90,85 @@ type Account struct {
 }
 
 type Transaction struct {
-	ID              uint      `json:"id" gorm:"primaryKey"`
-	UserID          uint      `json:"user_id" gorm:"not null"`
-	AccountID       uint      `json:"account_id" gorm:"not null"`
+	ID              uint      `json:"-" gorm:"primaryKey"`
+	PublicID        string    `json:"id" gorm:"size:26;uniqueIndex"`
+	UserID          uint      `json:"-" gorm:"not null"`
+	AccountID       uint      `json:"-" gorm:"not null;uniqueIndex:idx_transactions_account_external_id"`
+	AccountPublicID string    `json:"account_id" gorm:"size:26"` // denormalized account public ID
 	Amount          float64   `json:"amount" gorm:"not null"`
 	Description     string    `json:"description"`
-	Category        string    `json:"category"`
+	Notes           string    `json:"notes" gorm:"type:text"`
+	Merchant        string    `json:"merchant" gorm:"index"`
+	CategoryID      *uint     `json:"category_id" gorm:"index"`
+	Category        string    `json:"category"` // denormalized category name
 	TransactionDate time.Time `json:"transaction_date"`
 	Type            string    `json:"type"` // debit, credit
+	Pending         bool      `json:"pending" gorm:"default:false"`
+	ExternalID      *string   `json:"-" gorm:"uniqueIndex:idx_transactions_account_external_id"`
+	Version         int       `json:"version" gorm:"not null;default:1"` // bumped by a trigger on every update
 	CreatedAt       time.Time `json:"created_at"`
 	UpdatedAt       time.Time `json:"updated_at"`
 
 	// Relationships
-	User    User    `json:"user,omitempty"`
-	Account Account `json:"account,omitempty"`
+	User        User               `json:"user,omitempty"`
+	Account     Account            `json:"account,omitempty"`
+	Tags        []Tag              `json:"tags,omitempty" gorm:"many2many:transaction_tags;constraint:OnDelete:CASCADE"`
+	Splits      []TransactionSplit `json:"splits,omitempty" gorm:"constraint:OnDelete:CASCADE"`
+	Attachments []Attachment       `json:"attachments,omitempty" gorm:"constraint:OnDelete:CASCADE"`
+}
+
+func (a *Account) BeforeCreate(tx *gorm.DB) error {
+	if a.PublicID == "" {
+		a.PublicID = publicid.New()
+	}
+	return nil
+}
+
+// BeforeCreate assigns the public ID and, unless the caller already knows
+// it, looks up the account's public ID.
+func (t *Transaction) BeforeCreate(tx *gorm.DB) error {
+	if t.PublicID == "" {
+		t.PublicID = publicid.New()
+	}
+	if t.AccountPublicID == "" && t.AccountID != 0 {
+		return tx.Session(&gorm.Session{NewDB: true}).Model(&Account{}).
+			Select("public_id").
+			Where("id = ?", t.AccountID).
+			Scan(&t.AccountPublicID).Error
+	}
+	return nil
+}
+
+// BeforeSave keeps the normalized merchant name in sync with the description.
+// The search_vector column indexes it alongside the raw description.
+func (t *Transaction) BeforeSave(tx *gorm.DB) error {
+	t.Merchant = utils.NormalizeMerchant(t.Description)
+	return nil
 }
 
 type Budget struct {
-	ID        uint      `json:"id" gorm:"primaryKey"`
-	UserID    uint      `json:"user_id" gorm:"not null"`
-	Name      string    `json:"name" gorm:"not null"`
-	Category  string    `json:"category"`
-	Amount    float64   `json:"amount" gorm:"not null"`
-	Spent     float64   `json:"spent" gorm:"default:0"`
-	Period    string    `json:"period" gorm:"default:monthly"` // monthly, weekly, yearly
-	StartDate time.Time `json:"start_date"`
-	EndDate   time.Time `json:"end_date"`
-	IsActive  bool      `json:"is_active" gorm:"default:true"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID         uint      `json:"-" gorm:"primaryKey"`
+	PublicID   string    `json:"id" gorm:"size:26;uniqueIndex"`
+	UserID     uint      `json:"-" gorm:"not null"`
+	Name       string    `json:"name" gorm:"not null"`
+	CategoryID *uint     `json:"category_id" gorm:"index"`
+	Category   string    `json:"category"`
+	Amount     float64   `json:"amount" gorm:"not null"`
+	Spent      float64   `json:"spent" gorm:"default:0"`
+	Period     string    `json:"period" gorm:"default:monthly"` // monthly, weekly, yearly
+	StartDate  time.Time `json:"start_date"`
+	EndDate    time.Time `json:"end_date"`
+	IsActive   bool      `json:"is_active" gorm:"default:true"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
 
 	// Relationships
 	User User `json:"user,omitempty"`
 }
+
+func (b *Budget) BeforeCreate(tx *gorm.DB) error {
+	if b.PublicID == "" {
+		b.PublicID = publicid.New()
+	}
+	return nil
+}
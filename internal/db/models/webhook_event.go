@@ -0,0 +1,29 @@
+// internal/db/models/webhook_event.go
+package models
+
+import "time"
+
+const (
+	WebhookEventPending    = "pending"
+	WebhookEventProcessing = "processing"
+	WebhookEventProcessed  = "processed"
+	WebhookEventFailed     = "failed"
+)
+
+// WebhookEvent is a raw inbound webhook as received from a provider. The
+// (provider, event_id) pair is unique so redelivered events are ignored.
+type WebhookEvent struct {
+	ID            uint       `json:"id" gorm:"primaryKey"`
+	Provider      string     `json:"provider" gorm:"not null;uniqueIndex:idx_webhook_events_provider_event"`
+	EventID       string     `json:"event_id" gorm:"not null;uniqueIndex:idx_webhook_events_provider_event"`
+	EventType     string     `json:"event_type" gorm:"index"`
+	ItemID        string     `json:"item_id"`
+	Payload       string     `json:"payload" gorm:"type:text"`
+	Status        string     `json:"status" gorm:"default:pending;index"`
+	Attempts      int        `json:"attempts" gorm:"default:0"`
+	LastError     string     `json:"last_error,omitempty"`
+	NextAttemptAt time.Time  `json:"next_attempt_at" gorm:"index"`
+	ProcessedAt   *time.Time `json:"processed_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
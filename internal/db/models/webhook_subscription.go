@@ -0,0 +1,44 @@
+// internal/db/models/webhook_subscription.go
+package models
+
+import "time"
+
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliverySucceeded = "succeeded"
+	WebhookDeliveryFailed    = "failed"
+)
+
+// WebhookSubscription is a customer endpoint that receives signed event
+// notifications. An EventTypes entry of "*" matches every event.
+type WebhookSubscription struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	UserID      uint      `json:"-" gorm:"not null;index"`
+	URL         string    `json:"url" gorm:"not null"`
+	Description string    `json:"description"`
+	EventTypes  []string  `json:"event_types" gorm:"type:jsonb;serializer:json"`
+	Secret      string    `json:"-" gorm:"not null"`
+	IsActive    bool      `json:"is_active" gorm:"default:true"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// WebhookDelivery is one attempt chain to deliver an event to a
+// subscription; it doubles as the delivery log.
+type WebhookDelivery struct {
+	ID             uint       `json:"id" gorm:"primaryKey"`
+	SubscriptionID uint       `json:"subscription_id" gorm:"not null;index"`
+	UserID         uint       `json:"-" gorm:"not null;index"`
+	EventID        string     `json:"event_id" gorm:"not null;index"`
+	EventType      string     `json:"event_type"`
+	Payload        string     `json:"payload" gorm:"type:text"`
+	Status         string     `json:"status" gorm:"default:pending;index"`
+	Attempts       int        `json:"attempts" gorm:"default:0"`
+	NextAttemptAt  time.Time  `json:"next_attempt_at" gorm:"index"`
+	LastError      string     `json:"last_error,omitempty"`
+	ResponseStatus int        `json:"response_status,omitempty"`
+	DurationMs     int64      `json:"duration_ms,omitempty"`
+	DeliveredAt    *time.Time `json:"delivered_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
@@ -0,0 +1,41 @@
+// internal/db/schema.go
+package db
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm/clause"
+)
+
+// SchemaVersion is the schema this binary expects. Bump it whenever Migrate
+// gains a step, so readiness can tell when an instance is running ahead of
+// the database.
+const SchemaVersion = 12
+
+type schemaMigration struct {
+	Version   int       `gorm:"primaryKey;autoIncrement:false"`
+	AppliedAt time.Time `gorm:"not null"`
+}
+
+func (schemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+func recordSchemaVersion(db *DB) error {
+	if err := db.AutoMigrate(&schemaMigration{}); err != nil {
+		return err
+	}
+	return db.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&schemaMigration{Version: SchemaVersion, AppliedAt: time.Now()}).Error
+}
+
+// CurrentSchemaVersion returns the newest version recorded by Migrate, or 0
+// when migrations have never run.
+func (db *DB) CurrentSchemaVersion(ctx context.Context) (int, error) {
+	var version int
+	err := db.WithContext(ctx).Model(&schemaMigration{}).
+		Select("COALESCE(MAX(version), 0)").
+		Scan(&version).Error
+	return version, err
+}
@@ -0,0 +1,54 @@
+// internal/db/versioning.go
+package db
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// ErrVersionConflict is returned when a row changed after the caller read it.
+var ErrVersionConflict = errors.New("resource was modified concurrently")
+
+// versionedTables have a version column that a trigger bumps on every
+// update, whichever code path writes the row.
+var versionedTables = []string{"accounts", "transactions"}
+
+func migrateRowVersions(db *DB) error {
+	statements := []string{
+		`CREATE OR REPLACE FUNCTION bump_row_version() RETURNS trigger AS $$
+		BEGIN
+			NEW.version := OLD.version + 1;
+			RETURN NEW;
+		END;
+		$$ LANGUAGE plpgsql`,
+	}
+	for _, table := range versionedTables {
+		statements = append(statements,
+			`DROP TRIGGER IF EXISTS `+table+`_bump_version ON `+table,
+			`CREATE TRIGGER `+table+`_bump_version BEFORE UPDATE ON `+table+`
+				FOR EACH ROW EXECUTE FUNCTION bump_row_version()`,
+		)
+	}
+	for _, stmt := range statements {
+		if err := db.Exec(stmt).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// UpdateVersioned writes the given columns of model only if the row is
+// still at *version, and advances *version to match the row. It returns
+// ErrVersionConflict if another write got there first.
+func UpdateVersioned(tx *gorm.DB, model interface{}, version *int, columns ...string) error {
+	result := tx.Model(model).Where("version = ?", *version).Select(columns).Updates(model)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrVersionConflict
+	}
+	*version++
+	return nil
+}
@@ -0,0 +1,64 @@
+// internal/health/checks.go
+package health
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"finbro-backend-go/internal/db"
+	"finbro-backend-go/internal/jobs"
+)
+
+// DatabaseCheck pings Postgres and reports connection pool usage.
+func DatabaseCheck(database *db.DB) Check {
+	return func(ctx context.Context) (Details, error) {
+		sqlDB, err := database.DB.DB()
+		if err != nil {
+			return nil, err
+		}
+		if err := sqlDB.PingContext(ctx); err != nil {
+			return nil, err
+		}
+
+		stats := sqlDB.Stats()
+		return Details{
+			"open_connections": stats.OpenConnections,
+			"in_use":           stats.InUse,
+			"idle":             stats.Idle,
+		}, nil
+	}
+}
+
+// MigrationCheck fails until the database has at least the schema version
+// this binary was built for.
+func MigrationCheck(database *db.DB) Check {
+	return func(ctx context.Context) (Details, error) {
+		current, err := database.CurrentSchemaVersion(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		details := Details{"current": current, "expected": db.SchemaVersion}
+		if current < db.SchemaVersion {
+			return details, fmt.Errorf("schema version %d is behind expected %d", current, db.SchemaVersion)
+		}
+		return details, nil
+	}
+}
+
+// JobQueueCheck fails when the oldest due job has waited longer than maxLag.
+func JobQueueCheck(queue *jobs.Queue, maxLag time.Duration) Check {
+	return func(ctx context.Context) (Details, error) {
+		pending, lag, err := queue.Lag(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		details := Details{"pending": pending, "lag_seconds": int64(lag.Seconds())}
+		if lag > maxLag {
+			return details, fmt.Errorf("oldest job has waited %s, over the %s limit", lag.Round(time.Second), maxLag)
+		}
+		return details, nil
+	}
+}
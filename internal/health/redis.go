@@ -0,0 +1,106 @@
+// internal/health/redis.go
+package health
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// RedisCheck sends PING over a fresh connection using the RESP protocol
+// directly, so the check needs no Redis client library. redis:// and
+// rediss:// (TLS) URLs are supported, with optional credentials and DB.
+func RedisCheck(rawURL string) Check {
+	return func(ctx context.Context) (Details, error) {
+		u, err := url.Parse(rawURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redis url: %w", err)
+		}
+
+		host := u.Host
+		if u.Port() == "" {
+			host = net.JoinHostPort(u.Hostname(), "6379")
+		}
+
+		var dialer net.Dialer
+		conn, err := dialer.DialContext(ctx, "tcp", host)
+		if err != nil {
+			return nil, err
+		}
+		defer conn.Close()
+
+		if deadline, ok := ctx.Deadline(); ok {
+			_ = conn.SetDeadline(deadline)
+		}
+		if u.Scheme == "rediss" {
+			tlsConn := tls.Client(conn, &tls.Config{ServerName: u.Hostname()})
+			if err := tlsConn.HandshakeContext(ctx); err != nil {
+				return nil, err
+			}
+			conn = tlsConn
+		}
+
+		reader := bufio.NewReader(conn)
+		if password, ok := u.User.Password(); ok {
+			args := []string{"AUTH", password}
+			if username := u.User.Username(); username != "" {
+				args = []string{"AUTH", username, password}
+			}
+			if _, err := redisCommand(conn, reader, args...); err != nil {
+				return nil, err
+			}
+		}
+		if dbIndex := strings.TrimPrefix(u.Path, "/"); dbIndex != "" {
+			if _, err := strconv.Atoi(dbIndex); err != nil {
+				return nil, fmt.Errorf("invalid redis db %q", dbIndex)
+			}
+			if _, err := redisCommand(conn, reader, "SELECT", dbIndex); err != nil {
+				return nil, err
+			}
+		}
+
+		reply, err := redisCommand(conn, reader, "PING")
+		if err != nil {
+			return nil, err
+		}
+		if reply != "PONG" {
+			return nil, fmt.Errorf("unexpected PING reply %q", reply)
+		}
+		return Details{"address": host}, nil
+	}
+}
+
+// redisCommand writes a RESP array and reads a simple-string reply.
+func redisCommand(conn net.Conn, reader *bufio.Reader, args ...string) (string, error) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(&b, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	if _, err := conn.Write([]byte(b.String())); err != nil {
+		return "", err
+	}
+
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	line = strings.TrimRight(line, "\r\n")
+	if line == "" {
+		return "", fmt.Errorf("empty redis reply")
+	}
+
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return "", fmt.Errorf("redis: %s", line[1:])
+	default:
+		return "", fmt.Errorf("unexpected redis reply %q", line)
+	}
+}
@@ -0,0 +1,182 @@
+// internal/health/registry.go
+package health
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	StatusOK       = "ok"
+	StatusDegraded = "degraded"
+	StatusFail     = "fail"
+)
+
+const (
+	defaultCheckTimeout = 2 * time.Second
+	defaultCacheTTL     = 5 * time.Second
+)
+
+// Details carries extra facts about a component for dashboards, such as
+// latencies or versions.
+type Details map[string]interface{}
+
+// Check probes one dependency. A returned error marks the component failed.
+type Check func(ctx context.Context) (Details, error)
+
+type checkOptions struct {
+	timeout  time.Duration
+	critical bool
+}
+
+type CheckOption func(*checkOptions)
+
+// WithTimeout overrides the registry's default timeout for one check.
+func WithTimeout(d time.Duration) CheckOption {
+	return func(o *checkOptions) { o.timeout = d }
+}
+
+// NonCritical makes a failing check degrade the report instead of failing
+// readiness.
+func NonCritical() CheckOption {
+	return func(o *checkOptions) { o.critical = false }
+}
+
+// ComponentStatus is the outcome of one check.
+type ComponentStatus struct {
+	Status    string    `json:"status"`
+	Critical  bool      `json:"critical"`
+	Error     string    `json:"error,omitempty"`
+	LatencyMs int64     `json:"latency_ms"`
+	CheckedAt time.Time `json:"checked_at"`
+	Details   Details   `json:"details,omitempty"`
+}
+
+// Report aggregates every component. Status is fail when any critical check
+// failed and degraded when only non-critical ones did.
+type Report struct {
+	Status     string                     `json:"status"`
+	Components map[string]ComponentStatus `json:"components"`
+}
+
+type registeredCheck struct {
+	name  string
+	check Check
+	opts  checkOptions
+
+	mu     sync.Mutex
+	cached ComponentStatus
+}
+
+// Registry runs named checks with per-check timeouts and caches results for
+// a short TTL, so frequent probes do not hammer dependencies.
+type Registry struct {
+	timeout time.Duration
+	ttl     time.Duration
+	checks  []*registeredCheck
+}
+
+func NewRegistry(timeout, ttl time.Duration) *Registry {
+	if timeout <= 0 {
+		timeout = defaultCheckTimeout
+	}
+	if ttl < 0 {
+		ttl = defaultCacheTTL
+	}
+	return &Registry{timeout: timeout, ttl: ttl}
+}
+
+// Register adds a check. Checks are critical unless NonCritical is given.
+func (r *Registry) Register(name string, check Check, opts ...CheckOption) {
+	options := checkOptions{timeout: r.timeout, critical: true}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	r.checks = append(r.checks, &registeredCheck{name: name, check: check, opts: options})
+	sort.Slice(r.checks, func(i, j int) bool { return r.checks[i].name < r.checks[j].name })
+}
+
+// Run executes all checks concurrently, reusing results younger than the
+// cache TTL.
+func (r *Registry) Run(ctx context.Context) Report {
+	results := make([]ComponentStatus, len(r.checks))
+
+	var wg sync.WaitGroup
+	for i, c := range r.checks {
+		wg.Add(1)
+		go func(i int, c *registeredCheck) {
+			defer wg.Done()
+			results[i] = r.run(ctx, c)
+		}(i, c)
+	}
+	wg.Wait()
+
+	report := Report{Status: StatusOK, Components: make(map[string]ComponentStatus, len(results))}
+	for i, c := range r.checks {
+		result := results[i]
+		report.Components[c.name] = result
+		if result.Status != StatusFail {
+			continue
+		}
+		if c.opts.critical {
+			report.Status = StatusFail
+		} else if report.Status == StatusOK {
+			report.Status = StatusDegraded
+		}
+	}
+	return report
+}
+
+// run holds the check's lock while probing, so concurrent requests wait for
+// one probe instead of each starting their own.
+func (r *Registry) run(ctx context.Context, c *registeredCheck) ComponentStatus {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.cached.CheckedAt.IsZero() && time.Since(c.cached.CheckedAt) < r.ttl {
+		return c.cached
+	}
+
+	checkCtx, cancel := context.WithTimeout(ctx, c.opts.timeout)
+	defer cancel()
+
+	started := time.Now()
+	details, err := probe(checkCtx, c.check)
+	result := ComponentStatus{
+		Status:    StatusOK,
+		Critical:  c.opts.critical,
+		LatencyMs: time.Since(started).Milliseconds(),
+		CheckedAt: time.Now().UTC(),
+		Details:   details,
+	}
+	if err != nil {
+		result.Status = StatusFail
+		result.Error = err.Error()
+	}
+
+	c.cached = result
+	return result
+}
+
+// probe runs the check but gives up at the deadline even if the check
+// ignores its context.
+func probe(ctx context.Context, check Check) (Details, error) {
+	type outcome struct {
+		details Details
+		err     error
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		details, err := check(ctx)
+		done <- outcome{details, err}
+	}()
+
+	select {
+	case o := <-done:
+		return o.details, o.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
@@ -0,0 +1,24 @@
+// internal/health/state.go
+package health
+
+import "sync/atomic"
+
+// State tracks whether the process should receive traffic. It starts ready
+// and flips once shutdown begins, so load balancers stop routing to an
+// instance before its listener closes.
+type State struct {
+	draining atomic.Bool
+}
+
+func NewState() *State {
+	return &State{}
+}
+
+// SetDraining marks the process as shutting down. It cannot be undone.
+func (s *State) SetDraining() {
+	s.draining.Store(true)
+}
+
+func (s *State) Draining() bool {
+	return s.draining.Load()
+}
@@ -0,0 +1,227 @@
+// internal/jobs/cron.go
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxScheduleSearch bounds Next for schedules that never match, such as
+// "0 0 31 2 *".
+const maxScheduleSearch = 5 * 366 * 24 * time.Hour
+
+var cronMacros = map[string]string{
+	"@yearly":  "0 0 1 1 *",
+	"@monthly": "0 0 1 * *",
+	"@weekly":  "0 0 * * 0",
+	"@daily":   "0 0 * * *",
+	"@hourly":  "0 * * * *",
+}
+
+// Schedule is a parsed five-field cron expression (minute hour day-of-month
+// month day-of-week), evaluated in UTC.
+type Schedule struct {
+	minute, hour, dom, month, dow uint64
+	domAny, dowAny                bool
+}
+
+// ParseSchedule accepts standard cron syntax: "*", "*/n", "a-b", "a-b/n",
+// comma separated lists, and the @hourly/@daily/@weekly/@monthly/@yearly
+// macros. Day of week 7 is Sunday, like 0.
+func ParseSchedule(spec string) (*Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	if macro, ok := cronMacros[spec]; ok {
+		spec = macro
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("cron spec %q must have 5 fields", spec)
+	}
+
+	var s Schedule
+	var err error
+	if s.minute, err = parseCronField(fields[0], 0, 59); err != nil {
+		return nil, err
+	}
+	if s.hour, err = parseCronField(fields[1], 0, 23); err != nil {
+		return nil, err
+	}
+	if s.dom, err = parseCronField(fields[2], 1, 31); err != nil {
+		return nil, err
+	}
+	if s.month, err = parseCronField(fields[3], 1, 12); err != nil {
+		return nil, err
+	}
+	if s.dow, err = parseCronField(fields[4], 0, 7); err != nil {
+		return nil, err
+	}
+	if s.dow&(1<<7) != 0 {
+		s.dow |= 1
+	}
+	s.domAny = fields[2] == "*"
+	s.dowAny = fields[4] == "*"
+	return &s, nil
+}
+
+func parseCronField(field string, min, max int) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepPart)
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("invalid cron step %q", part)
+			}
+			step = n
+		}
+
+		lo, hi := min, max
+		if rangePart != "*" {
+			from, to, isRange := strings.Cut(rangePart, "-")
+			var err error
+			if lo, err = strconv.Atoi(from); err != nil {
+				return 0, fmt.Errorf("invalid cron value %q", part)
+			}
+			hi = lo
+			if isRange {
+				if hi, err = strconv.Atoi(to); err != nil {
+					return 0, fmt.Errorf("invalid cron value %q", part)
+				}
+			} else if hasStep {
+				hi = max
+			}
+		}
+		if lo < min || hi > max || lo > hi {
+			return 0, fmt.Errorf("cron value %q out of range %d-%d", part, min, max)
+		}
+
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+// Next returns the first minute strictly after t that matches, or the zero
+// time when there is none within five years.
+func (s *Schedule) Next(t time.Time) time.Time {
+	t = t.UTC().Truncate(time.Minute).Add(time.Minute)
+	limit := t.Add(maxScheduleSearch)
+
+	for t.Before(limit) {
+		switch {
+		case s.month&(1<<uint(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+		case !s.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, time.UTC)
+		case s.hour&(1<<uint(t.Hour())) == 0:
+			t = t.Truncate(time.Hour).Add(time.Hour)
+		case s.minute&(1<<uint(t.Minute())) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// dayMatches follows cron's rule that when both day fields are restricted,
+// either one matching is enough.
+func (s *Schedule) dayMatches(t time.Time) bool {
+	domMatch := s.dom&(1<<uint(t.Day())) != 0
+	dowMatch := s.dow&(1<<uint(t.Weekday())) != 0
+	switch {
+	case s.domAny && s.dowAny:
+		return true
+	case s.domAny:
+		return dowMatch
+	case s.dowAny:
+		return domMatch
+	default:
+		return domMatch || dowMatch
+	}
+}
+
+// JobPrune is the built-in job that deletes old succeeded jobs.
+const JobPrune = "jobs.prune"
+
+type pruneArgs struct {
+	RetentionHours int `json:"retention_hours"`
+}
+
+// SchedulePrune deletes succeeded jobs older than retention on the given
+// schedule, keeping the jobs table small.
+func (w *Worker) SchedulePrune(spec string, retention time.Duration) error {
+	Handle(w, JobPrune, func(ctx context.Context, args pruneArgs) error {
+		pruned, err := w.queue.Prune(time.Now().Add(-time.Duration(args.RetentionHours) * time.Hour))
+		if err == nil && pruned > 0 {
+			slog.Info("Pruned finished jobs", "count", pruned)
+		}
+		return err
+	})
+	return w.Cron(JobPrune, spec, JobPrune, pruneArgs{RetentionHours: int(retention.Hours())})
+}
+
+type cronEntry struct {
+	name     string
+	schedule *Schedule
+	jobType  string
+	args     interface{}
+	next     time.Time
+}
+
+// Cron enqueues a job of jobType on the given schedule. Every process that
+// registers the same name may run the scheduler; a unique key per name and
+// tick makes sure each tick is enqueued once.
+func (w *Worker) Cron(name, spec, jobType string, args interface{}) error {
+	schedule, err := ParseSchedule(spec)
+	if err != nil {
+		return err
+	}
+	w.cron = append(w.cron, &cronEntry{name: name, schedule: schedule, jobType: jobType, args: args})
+	return nil
+}
+
+func (w *Worker) schedule(ctx context.Context) {
+	now := time.Now()
+	for _, entry := range w.cron {
+		entry.next = entry.schedule.Next(now)
+	}
+
+	for {
+		var due time.Time
+		for _, entry := range w.cron {
+			if !entry.next.IsZero() && (due.IsZero() || entry.next.Before(due)) {
+				due = entry.next
+			}
+		}
+		if due.IsZero() {
+			return
+		}
+
+		timer := time.NewTimer(time.Until(due))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		for _, entry := range w.cron {
+			if entry.next.IsZero() || entry.next.After(due) {
+				continue
+			}
+			key := fmt.Sprintf("cron:%s:%d", entry.name, entry.next.Unix())
+			if _, err := w.queue.Enqueue(nil, entry.jobType, entry.args, UniqueKey(key), RunAt(entry.next)); err != nil {
+				slog.Error("Failed to enqueue cron job", "cron", entry.name, "error", err)
+			}
+			entry.next = entry.schedule.Next(entry.next)
+		}
+	}
+}
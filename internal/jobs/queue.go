@@ -0,0 +1,194 @@
+// internal/jobs/queue.go
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"finbro-backend-go/internal/db"
+	"finbro-backend-go/internal/db/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	DefaultQueue       = "default"
+	DefaultMaxAttempts = 10
+)
+
+// Queue enqueues jobs. Enqueue takes the caller's transaction, so a job is
+// only visible to workers once the work that scheduled it has committed.
+type Queue struct {
+	db     *db.DB
+	notify chan struct{}
+}
+
+func NewQueue(db *db.DB) *Queue {
+	return &Queue{
+		db:     db,
+		notify: make(chan struct{}, 1),
+	}
+}
+
+type enqueueOptions struct {
+	queue       string
+	runAt       time.Time
+	maxAttempts int
+	uniqueKey   string
+}
+
+type EnqueueOption func(*enqueueOptions)
+
+// OnQueue routes the job to a named queue; workers only claim from the
+// queues they are configured for.
+func OnQueue(name string) EnqueueOption {
+	return func(o *enqueueOptions) { o.queue = name }
+}
+
+// RunAt delays the job until t.
+func RunAt(t time.Time) EnqueueOption {
+	return func(o *enqueueOptions) { o.runAt = t }
+}
+
+// RunIn delays the job by d.
+func RunIn(d time.Duration) EnqueueOption {
+	return func(o *enqueueOptions) { o.runAt = time.Now().Add(d) }
+}
+
+func MaxAttempts(n int) EnqueueOption {
+	return func(o *enqueueOptions) { o.maxAttempts = n }
+}
+
+// UniqueKey makes Enqueue a no-op when a job with the same key already
+// exists, whatever its status.
+func UniqueKey(key string) EnqueueOption {
+	return func(o *enqueueOptions) { o.uniqueKey = key }
+}
+
+// Enqueue inserts a job of the given type with args encoded as JSON. Pass
+// nil for tx to enqueue outside a transaction. It returns nil, nil when a
+// UniqueKey collided with an existing job.
+func (q *Queue) Enqueue(tx *gorm.DB, jobType string, args interface{}, opts ...EnqueueOption) (*models.Job, error) {
+	options := enqueueOptions{
+		queue:       DefaultQueue,
+		runAt:       time.Now(),
+		maxAttempts: DefaultMaxAttempts,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	payload, err := json.Marshal(args)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s job: %w", jobType, err)
+	}
+
+	job := &models.Job{
+		Queue:       options.queue,
+		Type:        jobType,
+		Payload:     string(payload),
+		Status:      models.JobPending,
+		RunAt:       options.runAt,
+		MaxAttempts: options.maxAttempts,
+	}
+	if options.uniqueKey != "" {
+		job.UniqueKey = &options.uniqueKey
+	}
+
+	if tx == nil {
+		tx = q.db.DB
+	}
+	result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(job)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, nil
+	}
+
+	if !options.runAt.After(time.Now()) {
+		q.wake()
+	}
+	return job, nil
+}
+
+// wake nudges a local worker so jobs enqueued in this process start without
+// waiting for the next poll.
+func (q *Queue) wake() {
+	select {
+	case q.notify <- struct{}{}:
+	default:
+	}
+}
+
+// Retry moves a dead job back to pending with a fresh set of attempts.
+func (q *Queue) Retry(jobID uint) error {
+	result := q.db.Model(&models.Job{}).
+		Where("id = ? AND status = ?", jobID, models.JobDead).
+		Updates(map[string]interface{}{
+			"status":      models.JobPending,
+			"attempts":    0,
+			"run_at":      time.Now(),
+			"finished_at": nil,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrJobNotFound
+	}
+	q.wake()
+	return nil
+}
+
+// Prune deletes succeeded jobs that finished before the cutoff. Dead jobs
+// are kept until they are retried or removed by hand.
+func (q *Queue) Prune(before time.Time) (int64, error) {
+	result := q.db.Where("status = ? AND finished_at < ?", models.JobSucceeded, before).
+		Delete(&models.Job{})
+	return result.RowsAffected, result.Error
+}
+
+var ErrJobNotFound = errors.New("job not found")
+
+// permanentError marks a failure that retrying cannot fix.
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent wraps err so the job is dead-lettered instead of retried.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+func isPermanent(err error) bool {
+	var p *permanentError
+	return errors.As(err, &p)
+}
+
+// Lag reports how many jobs are due and how long the oldest of them has been
+// waiting. A growing lag means workers are not keeping up.
+func (q *Queue) Lag(ctx context.Context) (pending int64, lag time.Duration, err error) {
+	var stats struct {
+		Pending int64
+		Oldest  *time.Time
+	}
+	err = q.db.WithContext(ctx).Model(&models.Job{}).
+		Select("COUNT(*) AS pending, MIN(run_at) AS oldest").
+		Where("status = ? AND run_at <= ?", models.JobPending, time.Now()).
+		Scan(&stats).Error
+	if err != nil || stats.Oldest == nil {
+		return stats.Pending, 0, err
+	}
+	return stats.Pending, time.Since(*stats.Oldest), nil
+}
@@ -0,0 +1,361 @@
+// internal/jobs/worker.go
+package jobs
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	mathrand "math/rand"
+	"os"
+	"sync"
+	"time"
+
+	"finbro-backend-go/internal/db/models"
+	"finbro-backend-go/internal/logging"
+	"finbro-backend-go/internal/metrics"
+	"finbro-backend-go/internal/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	defaultConcurrency  = 4
+	defaultPollInterval = time.Second
+	defaultDrainTimeout = 30 * time.Second
+	defaultJobTimeout   = 5 * time.Minute
+	baseBackoff         = 10 * time.Second
+	maxBackoff          = time.Hour
+)
+
+// HandlerFunc runs one job. Returning an error schedules a retry unless the
+// error is wrapped with Permanent or the job is out of attempts.
+type HandlerFunc func(ctx context.Context, job *models.Job) error
+
+type handlerOptions struct {
+	timeout time.Duration
+	backoff func(attempt int) time.Duration
+}
+
+type HandlerOption func(*handlerOptions)
+
+// WithTimeout bounds a single run of the job. A job still running after
+// twice this long is considered abandoned and claimed again.
+func WithTimeout(d time.Duration) HandlerOption {
+	return func(o *handlerOptions) { o.timeout = d }
+}
+
+// WithBackoff replaces the default exponential retry delay.
+func WithBackoff(fn func(attempt int) time.Duration) HandlerOption {
+	return func(o *handlerOptions) { o.backoff = fn }
+}
+
+type handler struct {
+	fn   HandlerFunc
+	opts handlerOptions
+}
+
+type WorkerConfig struct {
+	Queues       []string
+	Concurrency  int
+	PollInterval time.Duration
+	// DrainTimeout is how long running jobs may take to finish after the
+	// worker is stopped before their contexts are cancelled.
+	DrainTimeout time.Duration
+}
+
+// Worker claims jobs with SELECT ... FOR UPDATE SKIP LOCKED, so any number
+// of API and worker processes can share one queue.
+type Worker struct {
+	queue    *Queue
+	cfg      WorkerConfig
+	id       string
+	handlers map[string]*handler
+	cron     []*cronEntry
+	wake     chan struct{}
+	wg       sync.WaitGroup
+}
+
+func NewWorker(queue *Queue, cfg WorkerConfig) *Worker {
+	if len(cfg.Queues) == 0 {
+		cfg.Queues = []string{DefaultQueue}
+	}
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = defaultConcurrency
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = defaultPollInterval
+	}
+	if cfg.DrainTimeout <= 0 {
+		cfg.DrainTimeout = defaultDrainTimeout
+	}
+
+	hostname, _ := os.Hostname()
+	suffix := make([]byte, 4)
+	_, _ = rand.Read(suffix)
+
+	return &Worker{
+		queue:    queue,
+		cfg:      cfg,
+		id:       fmt.Sprintf("%s-%d-%s", hostname, os.Getpid(), hex.EncodeToString(suffix)),
+		handlers: make(map[string]*handler),
+		wake:     make(chan struct{}, 1),
+	}
+}
+
+// Register adds an untyped handler. Most callers want Handle.
+func (w *Worker) Register(jobType string, fn HandlerFunc, opts ...HandlerOption) {
+	options := handlerOptions{
+		timeout: defaultJobTimeout,
+		backoff: defaultBackoff,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	w.handlers[jobType] = &handler{fn: fn, opts: options}
+}
+
+// Handle registers a handler whose payload is decoded into T. A payload that
+// does not decode dead-letters the job.
+func Handle[T any](w *Worker, jobType string, fn func(ctx context.Context, args T) error, opts ...HandlerOption) {
+	w.Register(jobType, func(ctx context.Context, job *models.Job) error {
+		var args T
+		if err := json.Unmarshal([]byte(job.Payload), &args); err != nil {
+			return Permanent(fmt.Errorf("failed to decode payload: %w", err))
+		}
+		return fn(ctx, args)
+	}, opts...)
+}
+
+// Start runs the worker until ctx is cancelled, then stops claiming and
+// drains running jobs. Wait blocks until the drain is complete.
+func (w *Worker) Start(ctx context.Context) {
+	runCtx, cancelRun := context.WithCancel(context.Background())
+
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		defer cancelRun()
+
+		var running sync.WaitGroup
+		w.poll(ctx, runCtx, &running)
+
+		done := make(chan struct{})
+		go func() {
+			running.Wait()
+			close(done)
+		}()
+
+		select {
+		case <-done:
+		case <-time.After(w.cfg.DrainTimeout):
+			slog.Warn("Job worker drain timed out, cancelling running jobs", "drain_timeout", w.cfg.DrainTimeout)
+			cancelRun()
+			<-done
+		}
+	}()
+
+	if len(w.cron) > 0 {
+		w.wg.Add(1)
+		go func() {
+			defer w.wg.Done()
+			w.schedule(ctx)
+		}()
+	}
+}
+
+func (w *Worker) Wait() {
+	w.wg.Wait()
+}
+
+func (w *Worker) poll(ctx, runCtx context.Context, running *sync.WaitGroup) {
+	slots := make(chan struct{}, w.cfg.Concurrency)
+	ticker := time.NewTicker(w.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for ctx.Err() == nil {
+		free := cap(slots) - len(slots)
+		claimed := 0
+		if free > 0 {
+			jobs, err := w.claim(free)
+			if err != nil {
+				slog.Error("Failed to claim jobs", "error", err)
+			}
+			claimed = len(jobs)
+
+			for i := range jobs {
+				job := &jobs[i]
+				slots <- struct{}{}
+				running.Add(1)
+				go func() {
+					defer running.Done()
+					w.run(runCtx, job)
+					<-slots
+					w.signal()
+				}()
+			}
+		}
+
+		if claimed > 0 && claimed == free {
+			// The queue may have more; come back as soon as a slot frees up.
+			select {
+			case <-ctx.Done():
+			case <-w.wake:
+			}
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+		case <-ticker.C:
+		case <-w.wake:
+		case <-w.queue.notify:
+		}
+	}
+}
+
+func (w *Worker) signal() {
+	select {
+	case w.wake <- struct{}{}:
+	default:
+	}
+}
+
+// claim locks up to n due jobs this worker can handle. Running jobs whose
+// lock is older than twice their handler timeout are reclaimed, since the
+// worker that held them has died.
+func (w *Worker) claim(n int) ([]models.Job, error) {
+	types := make([]string, 0, len(w.handlers))
+	var maxTimeout time.Duration
+	for jobType, h := range w.handlers {
+		types = append(types, jobType)
+		if h.opts.timeout > maxTimeout {
+			maxTimeout = h.opts.timeout
+		}
+	}
+	if len(types) == 0 {
+		return nil, nil
+	}
+
+	var jobs []models.Job
+	err := w.queue.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("queue IN ? AND type IN ?", w.cfg.Queues, types).
+			Where("(status = ? AND run_at <= ?) OR (status = ? AND locked_at < ?)",
+				models.JobPending, now,
+				models.JobRunning, now.Add(-2*maxTimeout)).
+			Order("run_at, id").
+			Limit(n).
+			Find(&jobs).Error; err != nil {
+			return err
+		}
+		if len(jobs) == 0 {
+			return nil
+		}
+
+		ids := make([]uint, len(jobs))
+		for i := range jobs {
+			ids[i] = jobs[i].ID
+			jobs[i].Status = models.JobRunning
+			jobs[i].Attempts++
+			jobs[i].LockedBy = w.id
+			jobs[i].LockedAt = &now
+		}
+		return tx.Model(&models.Job{}).Where("id IN ?", ids).Updates(map[string]interface{}{
+			"status":    models.JobRunning,
+			"attempts":  gorm.Expr("attempts + 1"),
+			"locked_by": w.id,
+			"locked_at": now,
+		}).Error
+	})
+	return jobs, err
+}
+
+func (w *Worker) run(runCtx context.Context, job *models.Job) {
+	h := w.handlers[job.Type]
+
+	ctx, cancel := context.WithTimeout(runCtx, h.opts.timeout)
+	defer cancel()
+
+	ctx, span := tracing.Tracer().Start(ctx, "job "+job.Type,
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			attribute.Int64("job.id", int64(job.ID)),
+			attribute.String("job.type", job.Type),
+			attribute.String("job.queue", job.Queue),
+			attribute.Int("job.attempt", job.Attempts),
+		))
+	defer span.End()
+	ctx = logging.WithLogger(ctx, slog.Default().With("job_id", job.ID, "job_type", job.Type, "attempt", job.Attempts))
+
+	err := invoke(ctx, h.fn, job)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	w.finish(job, h, err)
+}
+
+func invoke(ctx context.Context, fn HandlerFunc, job *models.Job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("handler panic: %v", r)
+		}
+	}()
+	return fn(ctx, job)
+}
+
+func (w *Worker) finish(job *models.Job, h *handler, jobErr error) {
+	now := time.Now()
+	updates := map[string]interface{}{
+		"locked_by": "",
+		"locked_at": nil,
+	}
+
+	result := "retried"
+	switch {
+	case jobErr == nil:
+		result = "succeeded"
+		updates["status"] = models.JobSucceeded
+		updates["finished_at"] = now
+		updates["last_error"] = ""
+	case isPermanent(jobErr) || job.Attempts >= job.MaxAttempts:
+		result = "dead"
+		updates["status"] = models.JobDead
+		updates["finished_at"] = now
+		updates["last_error"] = jobErr.Error()
+		slog.Error("Job dead", "job_id", job.ID, "job_type", job.Type, "attempts", job.Attempts, "error", jobErr)
+	default:
+		updates["status"] = models.JobPending
+		updates["run_at"] = now.Add(h.opts.backoff(job.Attempts))
+		updates["last_error"] = jobErr.Error()
+	}
+	metrics.JobsProcessed.WithLabelValues(job.Type, result).Inc()
+
+	// Only touch the row if it is still ours; it may have been reclaimed.
+	if err := w.queue.db.Model(&models.Job{}).
+		Where("id = ? AND locked_by = ?", job.ID, w.id).
+		Updates(updates).Error; err != nil {
+		slog.Error("Failed to record job result", "job_id", job.ID, "error", err)
+	}
+}
+
+// defaultBackoff waits 10s, 20s, 40s, ... up to an hour, with 10% jitter so
+// jobs that failed together do not retry together.
+func defaultBackoff(attempt int) time.Duration {
+	backoff := baseBackoff
+	for i := 1; i < attempt && backoff < maxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > maxBackoff {
+		backoff = maxBackoff
+	}
+	return backoff + time.Duration(mathrand.Int63n(int64(backoff/10)+1))
+}
@@ -0,0 +1,85 @@
+// internal/llm/fake.go
+package llm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// FakeProvider is a deterministic stand-in for a real model. It picks a tool
+// by keyword from the last user message, then echoes the tool results back
+// as its answer. It is meant for local development and tests.
+type FakeProvider struct {
+	keywords []fakeKeyword
+}
+
+type fakeKeyword struct {
+	words []string
+	tool  string
+}
+
+func NewFakeProvider() *FakeProvider {
+	return &FakeProvider{
+		keywords: []fakeKeyword{
+			{words: []string{"balance", "how much do i have", "net worth"}, tool: "get_account_balances"},
+			{words: []string{"category", "categories", "spend", "spent", "spending"}, tool: "get_spending_by_category"},
+			{words: []string{"month", "monthly", "income"}, tool: "get_monthly_totals"},
+			{words: []string{"recent", "last", "latest", "transaction", "charge"}, tool: "get_recent_transactions"},
+		},
+	}
+}
+
+func (f *FakeProvider) Chat(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if len(req.Messages) == 0 {
+		return nil, fmt.Errorf("no messages")
+	}
+
+	last := req.Messages[len(req.Messages)-1]
+	if last.Role == RoleTool {
+		var results []string
+		for i := len(req.Messages) - 1; i >= 0 && req.Messages[i].Role == RoleTool; i-- {
+			results = append([]string{req.Messages[i].Content}, results...)
+		}
+		return &ChatResponse{Message: Message{
+			Role:    RoleAssistant,
+			Content: "Here is what I found: " + strings.Join(results, " "),
+		}}, nil
+	}
+
+	question := strings.ToLower(last.Content)
+	for _, keyword := range f.keywords {
+		if !hasTool(req.Tools, keyword.tool) {
+			continue
+		}
+		for _, word := range keyword.words {
+			if strings.Contains(question, word) {
+				return &ChatResponse{Message: Message{
+					Role: RoleAssistant,
+					ToolCalls: []ToolCall{{
+						ID:        "call_1",
+						Name:      keyword.tool,
+						Arguments: "{}",
+					}},
+				}}, nil
+			}
+		}
+	}
+
+	return &ChatResponse{Message: Message{
+		Role:    RoleAssistant,
+		Content: "I can answer questions about your balances, spending by category, monthly totals and recent transactions.",
+	}}, nil
+}
+
+func hasTool(tools []Tool, name string) bool {
+	for _, tool := range tools {
+		if tool.Name == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,71 @@
+// internal/llm/provider.go
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"finbro-backend-go/internal/config"
+)
+
+const (
+	RoleSystem    = "system"
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+	RoleTool      = "tool"
+)
+
+// Provider is a chat completion backend that supports tool calling.
+type Provider interface {
+	Chat(ctx context.Context, req ChatRequest) (*ChatResponse, error)
+}
+
+type Message struct {
+	Role       string     `json:"role"`
+	Content    string     `json:"content"`
+	ToolCalls  []ToolCall `json:"tool_calls,omitempty"`
+	ToolCallID string     `json:"tool_call_id,omitempty"`
+}
+
+// Tool describes a function the model may call. Parameters is a JSON schema.
+type Tool struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	Parameters  json.RawMessage `json:"parameters"`
+}
+
+type ToolCall struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Arguments string `json:"arguments"`
+}
+
+type ChatRequest struct {
+	Messages    []Message
+	Tools       []Tool
+	Temperature float64
+}
+
+type ChatResponse struct {
+	Message Message
+}
+
+// NewProvider builds the provider selected in config. It returns nil when
+// no provider is configured, which disables AI features.
+func NewProvider(cfg *config.Config) (Provider, error) {
+	switch cfg.LLM.Provider {
+	case "fake":
+		return NewFakeProvider(), nil
+	case "openai":
+		return NewOpenAIClient(cfg.OpenAI.BaseURL, cfg.OpenAI.APIKey, cfg.OpenAI.Model, 30*time.Second), nil
+	case "":
+		if cfg.OpenAI.APIKey == "" {
+			return nil, nil
+		}
+		return NewOpenAIClient(cfg.OpenAI.BaseURL, cfg.OpenAI.APIKey, cfg.OpenAI.Model, 30*time.Second), nil
+	default:
+		return nil, fmt.Errorf("unknown LLM provider %q", cfg.LLM.Provider)
+	}
+}
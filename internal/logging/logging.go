@@ -0,0 +1,109 @@
+// internal/logging/logging.go
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"regexp"
+	"strings"
+)
+
+const redacted = "[REDACTED]"
+
+// sensitiveKeys are attribute keys, compared case-insensitively with "-"
+// folded to "_", whose values never reach the logs.
+var sensitiveKeys = map[string]bool{
+	"authorization":  true,
+	"password":       true,
+	"token":          true,
+	"access_token":   true,
+	"refresh_token":  true,
+	"public_token":   true,
+	"secret":         true,
+	"client_secret":  true,
+	"api_key":        true,
+	"account_number": true,
+	"cookie":         true,
+	"set_cookie":     true,
+	"code":           true,
+	"state":          true,
+}
+
+// bearerPattern catches credentials embedded in free-form strings such as
+// error messages.
+var bearerPattern = regexp.MustCompile(`(?i)(bearer|basic)\s+[A-Za-z0-9\-._~+/]+=*`)
+
+// New builds a logger writing JSON (or text) at the given level, with
+// sensitive attributes redacted.
+func New(w io.Writer, level, format string) (*slog.Logger, error) {
+	var lvl slog.Level
+	if err := lvl.UnmarshalText([]byte(level)); err != nil {
+		return nil, fmt.Errorf("invalid log level %q", level)
+	}
+
+	opts := &slog.HandlerOptions{Level: lvl, ReplaceAttr: redact}
+	switch format {
+	case "json", "":
+		return slog.New(slog.NewJSONHandler(w, opts)), nil
+	case "text":
+		return slog.New(slog.NewTextHandler(w, opts)), nil
+	default:
+		return nil, fmt.Errorf("invalid log format %q", format)
+	}
+}
+
+// IsSensitive reports whether values under key must be redacted.
+func IsSensitive(key string) bool {
+	return sensitiveKeys[strings.ReplaceAll(strings.ToLower(key), "-", "_")]
+}
+
+// RedactString masks bearer and basic credentials inside s.
+func RedactString(s string) string {
+	return bearerPattern.ReplaceAllString(s, "$1 "+redacted)
+}
+
+func redact(groups []string, a slog.Attr) slog.Attr {
+	if IsSensitive(a.Key) {
+		return slog.String(a.Key, redacted)
+	}
+	switch a.Value.Kind() {
+	case slog.KindString:
+		a.Value = slog.StringValue(RedactString(a.Value.String()))
+	case slog.KindAny:
+		if err, ok := a.Value.Any().(error); ok {
+			a.Value = slog.StringValue(RedactString(err.Error()))
+		}
+	}
+	return a
+}
+
+type contextKey struct{}
+
+// WithLogger returns a copy of ctx carrying logger.
+func WithLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, logger)
+}
+
+// FromContext returns the request-scoped logger, or the default logger when
+// ctx has none.
+func FromContext(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(contextKey{}).(*slog.Logger); ok {
+		return logger
+	}
+	return slog.Default()
+}
+
+// Setup installs the configured logger as the slog default. The standard
+// log package writes through it from then on, so stray log.Printf calls
+// still come out structured.
+func Setup(level, format string) (*slog.Logger, error) {
+	logger, err := New(os.Stderr, level, format)
+	if err != nil {
+		return nil, err
+	}
+	slog.SetDefault(logger)
+	return logger, nil
+}
@@ -0,0 +1,310 @@
+// internal/services/assistant_service.go
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"finbro-backend-go/internal/db"
+	"finbro-backend-go/internal/db/models"
+	"finbro-backend-go/internal/llm"
+)
+
+const assistantMaxSteps = 5
+
+var (
+	ErrAssistantDisabled = errors.New("AI assistant is not configured")
+	ErrAssistantNoAnswer = errors.New("assistant did not produce an answer")
+)
+
+const assistantSystemPrompt = `You are Finbro, a personal finance assistant. Answer questions about the
+user's own accounts and transactions using only the provided tools. Amounts
+are in the account currency. If the tools cannot answer the question, say so.
+Be concise.`
+
+type AssistantAnswer struct {
+	Answer    string   `json:"answer"`
+	ToolsUsed []string `json:"tools_used"`
+}
+
+// assistantTool is a read-only aggregation the model may call. The user ID
+// always comes from the authenticated request, never from tool arguments.
+type assistantTool struct {
+	definition llm.Tool
+	run        func(ctx context.Context, userID uint, args json.RawMessage) (interface{}, error)
+}
+
+type AssistantService struct {
+	db                 *db.DB
+	provider           llm.Provider
+	transactionService *TransactionService
+	tools              map[string]assistantTool
+}
+
+func NewAssistantService(db *db.DB, provider llm.Provider, transactionService *TransactionService) *AssistantService {
+	s := &AssistantService{
+		db:                 db,
+		provider:           provider,
+		transactionService: transactionService,
+	}
+	s.tools = s.registerTools()
+	return s
+}
+
+// Ask answers a natural-language question by letting the model call
+// whitelisted tools over the user's data until it produces a final answer.
+func (s *AssistantService) Ask(ctx context.Context, userID uint, question string) (*AssistantAnswer, error) {
+	if s.provider == nil {
+		return nil, ErrAssistantDisabled
+	}
+
+	definitions := make([]llm.Tool, 0, len(s.tools))
+	for _, name := range []string{"get_account_balances", "get_spending_by_category", "get_monthly_totals", "get_recent_transactions"} {
+		definitions = append(definitions, s.tools[name].definition)
+	}
+
+	messages := []llm.Message{
+		{Role: llm.RoleSystem, Content: assistantSystemPrompt + "\nToday is " + time.Now().Format("2006-01-02") + "."},
+		{Role: llm.RoleUser, Content: question},
+	}
+	answer := &AssistantAnswer{ToolsUsed: []string{}}
+
+	for step := 0; step < assistantMaxSteps; step++ {
+		resp, err := s.provider.Chat(ctx, llm.ChatRequest{Messages: messages, Tools: definitions})
+		if err != nil {
+			return nil, err
+		}
+
+		reply := resp.Message
+		if len(reply.ToolCalls) == 0 {
+			answer.Answer = reply.Content
+			return answer, nil
+		}
+
+		reply.Role = llm.RoleAssistant
+		messages = append(messages, reply)
+		for _, call := range reply.ToolCalls {
+			answer.ToolsUsed = append(answer.ToolsUsed, call.Name)
+			messages = append(messages, llm.Message{
+				Role:       llm.RoleTool,
+				ToolCallID: call.ID,
+				Content:    s.runTool(ctx, userID, call),
+			})
+		}
+	}
+
+	return nil, ErrAssistantNoAnswer
+}
+
+// runTool executes a tool call and returns its JSON result. Errors are
+// reported back to the model instead of failing the request.
+func (s *AssistantService) runTool(ctx context.Context, userID uint, call llm.ToolCall) string {
+	tool, ok := s.tools[call.Name]
+	if !ok {
+		return fmt.Sprintf(`{"error":"unknown tool %q"}`, call.Name)
+	}
+
+	args := json.RawMessage(call.Arguments)
+	if len(args) == 0 {
+		args = json.RawMessage("{}")
+	}
+
+	result, err := tool.run(ctx, userID, args)
+	if err != nil {
+		data, _ := json.Marshal(map[string]string{"error": err.Error()})
+		return string(data)
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		return `{"error":"failed to encode result"}`
+	}
+	return string(data)
+}
+
+func (s *AssistantService) registerTools() map[string]assistantTool {
+	return map[string]assistantTool{
+		"get_account_balances": {
+			definition: llm.Tool{
+				Name:        "get_account_balances",
+				Description: "List the user's active accounts with their type, balance and currency.",
+				Parameters:  json.RawMessage(`{"type":"object","properties":{}}`),
+			},
+			run: s.accountBalances,
+		},
+		"get_spending_by_category": {
+			definition: llm.Tool{
+				Name:        "get_spending_by_category",
+				Description: "Total debit spending per category between two dates (inclusive, YYYY-MM-DD). Defaults to the current month.",
+				Parameters: json.RawMessage(`{"type":"object","properties":{
+					"start_date":{"type":"string","description":"YYYY-MM-DD"},
+					"end_date":{"type":"string","description":"YYYY-MM-DD"}}}`),
+			},
+			run: s.spendingByCategory,
+		},
+		"get_monthly_totals": {
+			definition: llm.Tool{
+				Name:        "get_monthly_totals",
+				Description: "Income (credits) and spending (debits) per calendar month for the last N months.",
+				Parameters: json.RawMessage(`{"type":"object","properties":{
+					"months":{"type":"integer","minimum":1,"maximum":24}}}`),
+			},
+			run: s.monthlyTotals,
+		},
+		"get_recent_transactions": {
+			definition: llm.Tool{
+				Name:        "get_recent_transactions",
+				Description: "The user's most recent transactions, optionally filtered by a search query or category name.",
+				Parameters: json.RawMessage(`{"type":"object","properties":{
+					"query":{"type":"string"},
+					"category":{"type":"string"},
+					"limit":{"type":"integer","minimum":1,"maximum":20}}}`),
+			},
+			run: s.recentTransactions,
+		},
+	}
+}
+
+func (s *AssistantService) accountBalances(ctx context.Context, userID uint, _ json.RawMessage) (interface{}, error) {
+	var accounts []struct {
+		AccountName string  `json:"account_name"`
+		AccountType string  `json:"account_type"`
+		Balance     float64 `json:"balance"`
+		Currency    string  `json:"currency"`
+	}
+	err := s.db.WithContext(ctx).Model(&models.Account{}).
+		Select("account_name, account_type, balance, currency").
+		Where("user_id = ? AND is_active = ?", userID, true).
+		Scan(&accounts).Error
+	return accounts, err
+}
+
+func (s *AssistantService) spendingByCategory(ctx context.Context, userID uint, raw json.RawMessage) (interface{}, error) {
+	var args struct {
+		StartDate string `json:"start_date"`
+		EndDate   string `json:"end_date"`
+	}
+	if err := json.Unmarshal(raw, &args); err != nil {
+		return nil, fmt.Errorf("invalid arguments")
+	}
+
+	now := time.Now()
+	start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	end := now
+	if args.StartDate != "" {
+		t, err := time.Parse("2006-01-02", args.StartDate)
+		if err != nil {
+			return nil, fmt.Errorf("start_date must be YYYY-MM-DD")
+		}
+		start = t
+	}
+	if args.EndDate != "" {
+		t, err := time.Parse("2006-01-02", args.EndDate)
+		if err != nil {
+			return nil, fmt.Errorf("end_date must be YYYY-MM-DD")
+		}
+		end = t.Add(24*time.Hour - time.Nanosecond)
+	}
+
+	var rows []struct {
+		Category string  `json:"category"`
+		Total    float64 `json:"total"`
+	}
+	category := "COALESCE(NULLIF(" + lineCategoryName + ", ''), 'Uncategorized')"
+	err := s.db.WithContext(ctx).Model(&models.Transaction{}).
+		Select(category+" AS category, SUM("+lineAmount+") AS total").
+		Joins(splitLinesJoin).
+		Where("transactions.user_id = ? AND transactions.type = ?", userID, "debit").
+		Where("transactions.transaction_date BETWEEN ? AND ?", start, end).
+		Group(category).
+		Order("total DESC").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"start_date": start.Format("2006-01-02"),
+		"end_date":   end.Format("2006-01-02"),
+		"categories": rows,
+	}, nil
+}
+
+func (s *AssistantService) monthlyTotals(ctx context.Context, userID uint, raw json.RawMessage) (interface{}, error) {
+	var args struct {
+		Months int `json:"months"`
+	}
+	if err := json.Unmarshal(raw, &args); err != nil {
+		return nil, fmt.Errorf("invalid arguments")
+	}
+	if args.Months < 1 || args.Months > 24 {
+		args.Months = 6
+	}
+
+	now := time.Now()
+	since := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, -(args.Months - 1), 0)
+
+	var rows []struct {
+		Month    string  `json:"month"`
+		Income   float64 `json:"income"`
+		Spending float64 `json:"spending"`
+	}
+	err := s.db.WithContext(ctx).Model(&models.Transaction{}).
+		Select("TO_CHAR(DATE_TRUNC('month', transaction_date), 'YYYY-MM') AS month, "+
+			"COALESCE(SUM(CASE WHEN type = 'credit' THEN amount END), 0) AS income, "+
+			"COALESCE(SUM(CASE WHEN type = 'debit' THEN amount END), 0) AS spending").
+		Where("user_id = ? AND transaction_date >= ?", userID, since).
+		Group("month").
+		Order("month").
+		Scan(&rows).Error
+	return rows, err
+}
+
+func (s *AssistantService) recentTransactions(ctx context.Context, userID uint, raw json.RawMessage) (interface{}, error) {
+	var args struct {
+		Query    string `json:"query"`
+		Category string `json:"category"`
+		Limit    int    `json:"limit"`
+	}
+	if err := json.Unmarshal(raw, &args); err != nil {
+		return nil, fmt.Errorf("invalid arguments")
+	}
+	if args.Limit < 1 || args.Limit > 20 {
+		args.Limit = 10
+	}
+
+	filter := TransactionFilter{UserID: userID, Search: args.Query, Limit: args.Limit}
+	if args.Category != "" {
+		filter.Categories = []string{args.Category}
+	}
+	page, err := s.transactionService.GetTransactions(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	type row struct {
+		Date        string  `json:"date"`
+		Description string  `json:"description"`
+		Merchant    string  `json:"merchant,omitempty"`
+		Category    string  `json:"category,omitempty"`
+		Amount      float64 `json:"amount"`
+		Type        string  `json:"type"`
+		Account     string  `json:"account"`
+	}
+	rows := make([]row, 0, len(page.Transactions))
+	for _, t := range page.Transactions {
+		rows = append(rows, row{
+			Date:        t.TransactionDate.Format("2006-01-02"),
+			Description: t.Description,
+			Merchant:    t.Merchant,
+			Category:    t.Category,
+			Amount:      t.Amount,
+			Type:        t.Type,
+			Account:     t.Account.AccountName,
+		})
+	}
+	return rows, nil
+}
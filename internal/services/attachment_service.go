@@ -0,0 +1,313 @@
+// internal/services/attachment_service.go
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"finbro-backend-go/internal/db"
+	"finbro-backend-go/internal/db/models"
+	"finbro-backend-go/internal/jobs"
+	"finbro-backend-go/internal/publicid"
+	"finbro-backend-go/internal/storage"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	JobDeleteAttachmentBlobs = "attachments.delete_blobs"
+
+	MaxAttachmentsPerTransaction = 20
+	maxAttachmentFilename        = 255
+)
+
+var (
+	ErrAttachmentNotFound = errors.New("attachment not found")
+	ErrAttachmentEmpty    = errors.New("attachment is empty")
+	ErrAttachmentTooLarge = errors.New("attachment is too large")
+	ErrAttachmentType     = errors.New("attachment must be a JPEG, PNG, GIF or WebP image or a PDF")
+	ErrTooManyAttachments = fmt.Errorf("a transaction can have at most %d attachments", MaxAttachmentsPerTransaction)
+	ErrThumbnailNotFound  = errors.New("attachment has no thumbnail")
+)
+
+// attachmentContentTypes are the sniffed media types Create accepts.
+var attachmentContentTypes = map[string]bool{
+	"image/jpeg":      true,
+	"image/png":       true,
+	"image/gif":       true,
+	"image/webp":      true,
+	"application/pdf": true,
+}
+
+// AttachmentService stores files such as receipts for transactions. Rows
+// live in the database and bytes in the blob store; blobs are only removed
+// by a job enqueued in the same database transaction that removes their
+// rows, so a rolled-back delete never loses a file.
+type AttachmentService struct {
+	db       *db.DB
+	store    storage.BlobStore
+	queue    *jobs.Queue
+	maxBytes int64
+}
+
+func NewAttachmentService(db *db.DB, store storage.BlobStore, queue *jobs.Queue, maxBytes int64) *AttachmentService {
+	return &AttachmentService{
+		db:       db,
+		store:    store,
+		queue:    queue,
+		maxBytes: maxBytes,
+	}
+}
+
+// MaxBytes is the largest attachment Create accepts.
+func (s *AttachmentService) MaxBytes() int64 {
+	return s.maxBytes
+}
+
+func (s *AttachmentService) List(transactionID uint) ([]models.Attachment, error) {
+	attachments := []models.Attachment{}
+	err := s.db.Where("transaction_id = ?", transactionID).Order("id").Find(&attachments).Error
+	return attachments, err
+}
+
+// Get finds one of a transaction's attachments by its public ID.
+func (s *AttachmentService) Get(transactionID uint, ref string) (*models.Attachment, error) {
+	ref = strings.TrimSpace(ref)
+	if !publicid.Valid(ref) {
+		return nil, ErrAttachmentNotFound
+	}
+
+	var attachment models.Attachment
+	err := s.db.Where("public_id = ? AND transaction_id = ?", strings.ToUpper(ref), transactionID).
+		First(&attachment).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrAttachmentNotFound
+	}
+	return &attachment, err
+}
+
+// Create stores data as a new attachment of transaction. The content type
+// is sniffed from the bytes; the client's claim is ignored. Images that can
+// be decoded also get a thumbnail. The attachment limit is enforced with the
+// transaction row locked, so concurrent uploads cannot exceed it.
+func (s *AttachmentService) Create(ctx context.Context, transaction *models.Transaction, filename string, data []byte) (*models.Attachment, error) {
+	if len(data) == 0 {
+		return nil, ErrAttachmentEmpty
+	}
+	if int64(len(data)) > s.maxBytes {
+		return nil, ErrAttachmentTooLarge
+	}
+	contentType := sniffContentType(data)
+	if !attachmentContentTypes[contentType] {
+		return nil, ErrAttachmentType
+	}
+
+	// Fail fast before uploading anything; the check that counts is the
+	// one made with the insert.
+	if err := checkAttachmentLimit(s.db.WithContext(ctx), transaction.ID); err != nil {
+		return nil, err
+	}
+
+	sum := sha256.Sum256(data)
+	attachment := &models.Attachment{
+		PublicID:      publicid.New(),
+		UserID:        transaction.UserID,
+		TransactionID: transaction.ID,
+		Filename:      cleanFilename(filename),
+		ContentType:   contentType,
+		Size:          int64(len(data)),
+		SHA256:        hex.EncodeToString(sum[:]),
+	}
+	attachment.StorageKey = fmt.Sprintf("attachments/%d/%s", transaction.UserID, attachment.PublicID)
+
+	if err := s.store.Put(ctx, attachment.StorageKey, data, contentType); err != nil {
+		return nil, fmt.Errorf("failed to store attachment: %w", err)
+	}
+	keys := []string{attachment.StorageKey}
+
+	thumbnail, err := makeThumbnail(data)
+	if err != nil {
+		slog.WarnContext(ctx, "Failed to create attachment thumbnail", "attachment_id", attachment.PublicID, "error", err)
+	}
+	if thumbnail != nil {
+		thumbnailKey := attachment.StorageKey + ".thumb.jpg"
+		if err := s.store.Put(ctx, thumbnailKey, thumbnail, "image/jpeg"); err != nil {
+			s.deleteBlobs(ctx, keys)
+			return nil, fmt.Errorf("failed to store thumbnail: %w", err)
+		}
+		attachment.ThumbnailKey = thumbnailKey
+		attachment.HasThumbnail = true
+		keys = append(keys, thumbnailKey)
+	}
+
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").
+			First(&models.Transaction{}, transaction.ID).Error; err != nil {
+			return err
+		}
+		if err := checkAttachmentLimit(tx, transaction.ID); err != nil {
+			return err
+		}
+		return tx.Create(attachment).Error
+	})
+	if err != nil {
+		s.deleteBlobs(ctx, keys)
+		return nil, err
+	}
+	return attachment, nil
+}
+
+func checkAttachmentLimit(tx *gorm.DB, transactionID uint) error {
+	var count int64
+	if err := tx.Model(&models.Attachment{}).Where("transaction_id = ?", transactionID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count >= MaxAttachmentsPerTransaction {
+		return ErrTooManyAttachments
+	}
+	return nil
+}
+
+// Open returns the attachment's contents, or its thumbnail's.
+func (s *AttachmentService) Open(ctx context.Context, attachment *models.Attachment, thumbnail bool) (io.ReadCloser, error) {
+	key := attachment.StorageKey
+	if thumbnail {
+		if attachment.ThumbnailKey == "" {
+			return nil, ErrThumbnailNotFound
+		}
+		key = attachment.ThumbnailKey
+	}
+
+	body, err := s.store.Get(ctx, key)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, ErrAttachmentNotFound
+	}
+	return body, err
+}
+
+// Delete removes one of a transaction's attachments and schedules its
+// blobs for deletion.
+func (s *AttachmentService) Delete(ctx context.Context, transactionID uint, ref string) error {
+	attachment, err := s.Get(transactionID, ref)
+	if err != nil {
+		return err
+	}
+
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Delete(attachment)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrAttachmentNotFound
+		}
+		return s.enqueueDelete(tx, attachmentKeys([]models.Attachment{*attachment}))
+	})
+}
+
+// ReleaseForTransactions schedules the blobs of every attachment of the
+// given transactions for deletion. Call it in the database transaction that
+// deletes them; their attachment rows go with them by cascade.
+func (s *AttachmentService) ReleaseForTransactions(tx *gorm.DB, transactionIDs []uint) error {
+	var attachments []models.Attachment
+	if err := tx.Select("storage_key", "thumbnail_key").
+		Where("transaction_id IN ?", transactionIDs).
+		Find(&attachments).Error; err != nil {
+		return err
+	}
+	if len(attachments) == 0 {
+		return nil
+	}
+	return s.enqueueDelete(tx, attachmentKeys(attachments))
+}
+
+type deleteAttachmentBlobsArgs struct {
+	Keys []string `json:"keys"`
+}
+
+func (s *AttachmentService) enqueueDelete(tx *gorm.DB, keys []string) error {
+	_, err := s.queue.Enqueue(tx, JobDeleteAttachmentBlobs, deleteAttachmentBlobsArgs{Keys: keys})
+	return err
+}
+
+// RegisterJobs installs the blob deletion handler on w.
+func (s *AttachmentService) RegisterJobs(w *jobs.Worker) {
+	jobs.Handle(w, JobDeleteAttachmentBlobs, s.deleteBlobsJob)
+}
+
+// deleteBlobsJob deletes blobs whose rows are gone. Deleting a missing blob
+// succeeds, so a retry after a partial run is harmless.
+func (s *AttachmentService) deleteBlobsJob(ctx context.Context, args deleteAttachmentBlobsArgs) error {
+	for _, key := range args.Keys {
+		if err := s.store.Delete(ctx, key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteBlobs cleans up after a failed Create. Blobs it cannot remove are
+// orphaned but never referenced.
+func (s *AttachmentService) deleteBlobs(ctx context.Context, keys []string) {
+	ctx = context.WithoutCancel(ctx)
+	for _, key := range keys {
+		if err := s.store.Delete(ctx, key); err != nil {
+			slog.ErrorContext(ctx, "Failed to delete attachment blob", "key", key, "error", err)
+		}
+	}
+}
+
+func attachmentKeys(attachments []models.Attachment) []string {
+	keys := make([]string, 0, 2*len(attachments))
+	for _, attachment := range attachments {
+		keys = append(keys, attachment.StorageKey)
+		if attachment.ThumbnailKey != "" {
+			keys = append(keys, attachment.ThumbnailKey)
+		}
+	}
+	return keys
+}
+
+// sniffContentType returns the media type http.DetectContentType finds in
+// data, without parameters.
+func sniffContentType(data []byte) string {
+	mediaType, _, err := mime.ParseMediaType(http.DetectContentType(data))
+	if err != nil {
+		return ""
+	}
+	return mediaType
+}
+
+// cleanFilename keeps the base name of a client-supplied filename, without
+// control characters and no longer than maxAttachmentFilename bytes.
+func cleanFilename(name string) string {
+	if i := strings.LastIndexAny(name, `/\`); i >= 0 {
+		name = name[i+1:]
+	}
+	name = strings.TrimSpace(strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) || r == unicode.ReplacementChar {
+			return -1
+		}
+		return r
+	}, name))
+
+	for len(name) > maxAttachmentFilename {
+		_, size := utf8.DecodeLastRuneInString(name)
+		name = name[:len(name)-size]
+	}
+	if name == "" || name == "." || name == ".." {
+		return "attachment"
+	}
+	return name
+}
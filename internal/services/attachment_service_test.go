@@ -0,0 +1,212 @@
+// internal/services/attachment_service_test.go
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"strings"
+	"sync"
+	"testing"
+
+	"finbro-backend-go/internal/db/dbtest"
+	"finbro-backend-go/internal/db/models"
+	"finbro-backend-go/internal/jobs"
+	"finbro-backend-go/internal/storage"
+)
+
+func testPNG(t *testing.T, width, height int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for x := 0; x < width; x++ {
+		for y := 0; y < height; y++ {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 200, A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestSniffContentType(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{"png", testPNG(t, 2, 2), "image/png"},
+		{"pdf", []byte("%PDF-1.7\n1 0 obj\n"), "application/pdf"},
+		{"webp", []byte("RIFF\x24\x00\x00\x00WEBPVP8 "), "image/webp"},
+		{"html disguised as an image", []byte("<html><script>alert(1)</script>"), "text/html"},
+	}
+	for _, tt := range tests {
+		if got := sniffContentType(tt.data); got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestCleanFilename(t *testing.T) {
+	tests := map[string]string{
+		"receipt.pdf":              "receipt.pdf",
+		`C:\Users\me\receipt.jpg`:  "receipt.jpg",
+		"../../etc/passwd":         "passwd",
+		"bad\x00name\n.png":        "badname.png",
+		"  ":                       "attachment",
+		"..":                       "attachment",
+		strings.Repeat("é", 200):   strings.Repeat("é", 127),
+		"café receipt (copy).jpeg": "café receipt (copy).jpeg",
+	}
+	for input, want := range tests {
+		if got := cleanFilename(input); got != want {
+			t.Errorf("cleanFilename(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+// Create validates the upload before it touches the database or the store.
+func TestCreateAttachmentRejectsInvalidUploads(t *testing.T) {
+	store, err := storage.NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	svc := NewAttachmentService(nil, store, nil, 1024)
+	transaction := &models.Transaction{ID: 1, UserID: 1}
+
+	tests := []struct {
+		name string
+		data []byte
+		want error
+	}{
+		{"empty", nil, ErrAttachmentEmpty},
+		{"too large", append([]byte("%PDF-1.7\n"), make([]byte, 1024)...), ErrAttachmentTooLarge},
+		{"html", []byte("<!DOCTYPE html><p>hi</p>"), ErrAttachmentType},
+		{"plain text", []byte("just some notes"), ErrAttachmentType},
+	}
+	for _, tt := range tests {
+		if _, err := svc.Create(context.Background(), transaction, "upload", tt.data); !errors.Is(err, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestDeleteAttachmentRemovesBlobs(t *testing.T) {
+	database := dbtest.Open(t)
+	ctx := context.Background()
+
+	store, err := storage.NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	svc := NewAttachmentService(database, store, jobs.NewQueue(database), 1<<20)
+
+	user := dbtest.CreateUser(t, database)
+	account := dbtest.CreateAccount(t, database, user.ID)
+	transaction := dbtest.CreateTransaction(t, database, account, "Hardware store", 42)
+
+	attachment, err := svc.Create(ctx, transaction, "receipt.png", testPNG(t, 600, 300))
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if attachment.ContentType != "image/png" || !attachment.HasThumbnail {
+		t.Fatalf("Create: got content type %q, thumbnail %v", attachment.ContentType, attachment.HasThumbnail)
+	}
+	for _, key := range []string{attachment.StorageKey, attachment.ThumbnailKey} {
+		r, err := store.Get(ctx, key)
+		if err != nil {
+			t.Fatalf("blob %s was not stored: %v", key, err)
+		}
+		r.Close()
+	}
+
+	if err := svc.Delete(ctx, transaction.ID, attachment.PublicID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := svc.Get(transaction.ID, attachment.PublicID); !errors.Is(err, ErrAttachmentNotFound) {
+		t.Fatalf("Get after Delete: got %v, want ErrAttachmentNotFound", err)
+	}
+
+	// The blobs go in a job committed with the row's deletion.
+	var job models.Job
+	if err := database.Where("type = ? AND payload LIKE ?", JobDeleteAttachmentBlobs, "%"+attachment.StorageKey+"%").
+		First(&job).Error; err != nil {
+		t.Fatalf("no blob deletion job was enqueued: %v", err)
+	}
+	var args deleteAttachmentBlobsArgs
+	if err := json.Unmarshal([]byte(job.Payload), &args); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.deleteBlobsJob(ctx, args); err != nil {
+		t.Fatalf("deleteBlobsJob: %v", err)
+	}
+	for _, key := range []string{attachment.StorageKey, attachment.ThumbnailKey} {
+		if _, err := store.Get(ctx, key); !errors.Is(err, storage.ErrNotFound) {
+			t.Errorf("blob %s: got %v, want ErrNotFound", key, err)
+		}
+	}
+
+	// A retried job must not fail on blobs that are already gone.
+	if err := svc.deleteBlobsJob(ctx, args); err != nil {
+		t.Errorf("deleteBlobsJob retry: %v", err)
+	}
+}
+
+func TestCreateAttachmentLimitHoldsUnderConcurrency(t *testing.T) {
+	database := dbtest.Open(t)
+	ctx := context.Background()
+
+	store, err := storage.NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	svc := NewAttachmentService(database, store, jobs.NewQueue(database), 1<<20)
+
+	user := dbtest.CreateUser(t, database)
+	account := dbtest.CreateAccount(t, database, user.ID)
+	transaction := dbtest.CreateTransaction(t, database, account, "Office supplies", 64)
+
+	pdf := []byte("%PDF-1.7\n1 0 obj\n")
+	for i := 0; i < MaxAttachmentsPerTransaction-1; i++ {
+		if _, err := svc.Create(ctx, transaction, "receipt.pdf", pdf); err != nil {
+			t.Fatalf("Create #%d: %v", i+1, err)
+		}
+	}
+
+	const uploads = 5
+	errs := make([]error, uploads)
+	var wg sync.WaitGroup
+	for i := range errs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, errs[i] = svc.Create(ctx, transaction, "receipt.pdf", pdf)
+		}()
+	}
+	wg.Wait()
+
+	succeeded := 0
+	for _, err := range errs {
+		switch {
+		case err == nil:
+			succeeded++
+		case !errors.Is(err, ErrTooManyAttachments):
+			t.Errorf("concurrent Create: %v", err)
+		}
+	}
+	if succeeded != 1 {
+		t.Errorf("%d of %d concurrent uploads succeeded, want exactly 1", succeeded, uploads)
+	}
+	attachments, err := svc.List(transaction.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(attachments) != MaxAttachmentsPerTransaction {
+		t.Errorf("transaction has %d attachments, want %d", len(attachments), MaxAttachmentsPerTransaction)
+	}
+}
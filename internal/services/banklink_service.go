@@ -0,0 +1,455 @@
+// internal/services/banklink_service.go
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"finbro-backend-go/internal/aggregator"
+	"finbro-backend-go/internal/db"
+	"finbro-backend-go/internal/db/models"
+	"finbro-backend-go/internal/metrics"
+	"finbro-backend-go/internal/publicid"
+	"finbro-backend-go/internal/secrets"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrBankLinkDisabled = errors.New("bank linking is not configured")
+	ErrBankItemNotFound = errors.New("bank item not found")
+)
+
+type SyncSummary struct {
+	Added    int `json:"added"`
+	Modified int `json:"modified"`
+	Removed  int `json:"removed"`
+}
+
+// BankLinkService links institutions through an aggregator and mirrors
+// their accounts and transactions into the local models. Synced transactions
+// do not change balances; balances are taken from the provider instead.
+type BankLinkService struct {
+	db          *db.DB
+	aggregator  aggregator.Aggregator
+	encrypter   *secrets.Encrypter
+	ruleService *RuleService
+	categorizer *CategorizerService
+	budgets     *BudgetService
+	attachments *AttachmentService
+}
+
+func NewBankLinkService(
+	db *db.DB,
+	agg aggregator.Aggregator,
+	encrypter *secrets.Encrypter,
+	ruleService *RuleService,
+	categorizer *CategorizerService,
+	budgets *BudgetService,
+	attachments *AttachmentService,
+) *BankLinkService {
+	return &BankLinkService{
+		db:          db,
+		aggregator:  agg,
+		encrypter:   encrypter,
+		ruleService: ruleService,
+		categorizer: categorizer,
+		budgets:     budgets,
+		attachments: attachments,
+	}
+}
+
+func (s *BankLinkService) enabled() error {
+	if s.aggregator == nil || s.encrypter == nil {
+		return ErrBankLinkDisabled
+	}
+	return nil
+}
+
+func (s *BankLinkService) CreateLinkToken(ctx context.Context, userID uint) (*aggregator.LinkToken, error) {
+	if err := s.enabled(); err != nil {
+		return nil, err
+	}
+	return s.aggregator.CreateLinkToken(ctx, strconv.FormatUint(uint64(userID), 10))
+}
+
+// ExchangePublicToken completes the link flow: it stores the item with its
+// encrypted access token and runs the initial sync, which imports its
+// accounts.
+func (s *BankLinkService) ExchangePublicToken(ctx context.Context, userID uint, publicToken string) (*models.BankItem, error) {
+	if err := s.enabled(); err != nil {
+		return nil, err
+	}
+
+	linked, err := s.aggregator.ExchangePublicToken(ctx, publicToken)
+	if err != nil {
+		return nil, err
+	}
+	encrypted, err := s.encrypter.Encrypt(linked.AccessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	item := &models.BankItem{
+		UserID:          userID,
+		Provider:        s.aggregator.Name(),
+		ItemID:          linked.ItemID,
+		AccessToken:     encrypted,
+		InstitutionName: linked.InstitutionName,
+		Status:          models.BankItemActive,
+	}
+	if err := s.db.Create(item).Error; err != nil {
+		return nil, err
+	}
+
+	if _, err := s.SyncItem(ctx, item); err != nil {
+		return nil, err
+	}
+
+	return s.GetItem(item.PublicID, userID)
+}
+
+func (s *BankLinkService) GetItems(userID uint) ([]models.BankItem, error) {
+	var items []models.BankItem
+	err := s.db.Preload("Accounts").Where("user_id = ?", userID).Find(&items).Error
+	return items, err
+}
+
+// GetItem loads one of the user's items by its public ID or, during the
+// legacy ID compatibility window, its numeric ID.
+func (s *BankLinkService) GetItem(ref string, userID uint) (*models.BankItem, error) {
+	scope, err := publicid.Scope(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	var item models.BankItem
+	err = s.db.Preload("Accounts").Scopes(scope).Where("user_id = ?", userID).First(&item).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrBankItemNotFound
+	}
+	return &item, err
+}
+
+// GetItemByExternalID looks an item up by the aggregator's item ID, e.g. for
+// webhook delivery.
+func (s *BankLinkService) GetItemByExternalID(externalID string) (*models.BankItem, error) {
+	var item models.BankItem
+	err := s.db.Where("item_id = ?", externalID).First(&item).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrBankItemNotFound
+	}
+	return &item, err
+}
+
+// RemoveItem unlinks an item at the provider. Its accounts are kept but
+// deactivated so history stays intact.
+func (s *BankLinkService) RemoveItem(ctx context.Context, ref string, userID uint) error {
+	if err := s.enabled(); err != nil {
+		return err
+	}
+	item, err := s.GetItem(ref, userID)
+	if err != nil {
+		return err
+	}
+
+	accessToken, err := s.encrypter.Decrypt(item.AccessToken)
+	if err != nil {
+		return err
+	}
+	if err := s.aggregator.RemoveItem(ctx, accessToken); err != nil && !errors.Is(err, aggregator.ErrInvalidToken) {
+		return err
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Account{}).
+			Where("bank_item_id = ?", item.ID).
+			Updates(map[string]interface{}{"is_active": false, "bank_item_id": nil, "bank_item_public_id": nil}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.BankItem{}, item.ID).Error
+	})
+}
+
+// RefreshAccounts upserts the item's accounts and their current balances.
+// External IDs are only unique per item, so accounts are matched within it.
+func (s *BankLinkService) RefreshAccounts(ctx context.Context, item *models.BankItem) error {
+	if err := s.enabled(); err != nil {
+		return err
+	}
+	accessToken, err := s.encrypter.Decrypt(item.AccessToken)
+	if err != nil {
+		return err
+	}
+
+	remote, err := s.aggregator.GetAccounts(ctx, accessToken)
+	if err != nil {
+		return s.recordItemError(item, err)
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		for _, ra := range remote {
+			externalID := ra.ExternalID
+			var account models.Account
+			err := tx.Where("bank_item_id = ? AND external_id = ?", item.ID, externalID).First(&account).Error
+			if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+
+			account.UserID = item.UserID
+			account.BankItemID = &item.ID
+			account.BankItemPublicID = &item.PublicID
+			account.ExternalID = &externalID
+			account.AccountName = ra.Name
+			account.AccountType = mapAccountType(ra.Type, ra.Subtype)
+			account.Balance = ra.CurrentBalance
+			account.BankName = item.InstitutionName
+			account.IsActive = true
+			if ra.Currency != "" {
+				account.Currency = ra.Currency
+			}
+			if ra.Mask != "" {
+				account.AccountNumber = "****" + ra.Mask
+			}
+
+			if err := tx.Omit(clause.Associations).Save(&account).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// SyncItem pulls transaction changes since the item's stored cursor. Each
+// page is applied together with its cursor in one database transaction, so
+// a sync interrupted midway resumes from the last committed page, and
+// replaying a page is harmless because rows are upserted by external ID.
+// Accounts are refreshed first so transactions on an account opened since
+// the last sync have somewhere to go.
+func (s *BankLinkService) SyncItem(ctx context.Context, item *models.BankItem) (*SyncSummary, error) {
+	summary, err := s.syncItem(ctx, item)
+
+	result := "success"
+	if err != nil {
+		result = "failure"
+	}
+	metrics.ImportsProcessed.WithLabelValues("bank_sync", result).Inc()
+	return summary, err
+}
+
+func (s *BankLinkService) syncItem(ctx context.Context, item *models.BankItem) (*SyncSummary, error) {
+	if err := s.enabled(); err != nil {
+		return nil, err
+	}
+	accessToken, err := s.encrypter.Decrypt(item.AccessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.RefreshAccounts(ctx, item); err != nil {
+		return nil, err
+	}
+	accounts, err := s.accountsByExternalID(item.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &SyncSummary{}
+	for {
+		page, err := s.aggregator.SyncTransactions(ctx, accessToken, item.Cursor)
+		if err != nil {
+			return summary, s.recordItemError(item, err)
+		}
+
+		err = s.db.Transaction(func(tx *gorm.DB) error {
+			rules, err := s.ruleService.LoadRules(tx, item.UserID)
+			if err != nil {
+				return err
+			}
+			for _, t := range page.Added {
+				if err := s.upsertTransaction(tx, item, accounts, rules, t); err != nil {
+					return err
+				}
+			}
+			for _, t := range page.Modified {
+				if err := s.upsertTransaction(tx, item, accounts, rules, t); err != nil {
+					return err
+				}
+			}
+			if len(page.Removed) > 0 {
+				if err := s.removeTransactions(tx, accounts, page.Removed); err != nil {
+					return err
+				}
+			}
+
+			now := time.Now()
+			item.Cursor = page.NextCursor
+			item.Status = models.BankItemActive
+			item.LastError = ""
+			item.LastSyncedAt = &now
+			return tx.Model(item).Select("cursor", "status", "last_error", "last_synced_at").Updates(item).Error
+		})
+		if err != nil {
+			return summary, err
+		}
+
+		summary.Added += len(page.Added)
+		metrics.TransactionsCreated.WithLabelValues("bank_sync").Add(float64(len(page.Added)))
+		summary.Modified += len(page.Modified)
+		summary.Removed += len(page.Removed)
+
+		if !page.HasMore {
+			break
+		}
+	}
+
+	return summary, nil
+}
+
+func (s *BankLinkService) accountsByExternalID(itemID uint) (map[string]models.Account, error) {
+	var accounts []models.Account
+	if err := s.db.Where("bank_item_id = ?", itemID).Find(&accounts).Error; err != nil {
+		return nil, err
+	}
+
+	byExternalID := make(map[string]models.Account, len(accounts))
+	for _, account := range accounts {
+		if account.ExternalID != nil {
+			byExternalID[*account.ExternalID] = account
+		}
+	}
+	return byExternalID, nil
+}
+
+// upsertTransaction creates or updates the local copy of a provider
+// transaction. New transactions go through the user's rules and the
+// categorizer; updates keep the user's categorization.
+func (s *BankLinkService) upsertTransaction(tx *gorm.DB, item *models.BankItem, accounts map[string]models.Account, rules *RuleSet, remote aggregator.Transaction) error {
+	account, ok := accounts[remote.AccountExternalID]
+	if !ok {
+		return fmt.Errorf("sync returned transaction for unknown account %q", remote.AccountExternalID)
+	}
+
+	var transaction models.Transaction
+	err := tx.Where("account_id = ? AND external_id = ?", account.ID, remote.ExternalID).First(&transaction).Error
+	isNew := errors.Is(err, gorm.ErrRecordNotFound)
+	if err != nil && !isNew {
+		return err
+	}
+	previous := transaction
+
+	externalID := remote.ExternalID
+	transaction.UserID = item.UserID
+	transaction.AccountID = account.ID
+	transaction.AccountPublicID = account.PublicID
+	transaction.ExternalID = &externalID
+	transaction.Amount = math.Abs(remote.Amount)
+	transaction.Type = "debit"
+	if remote.Amount < 0 {
+		transaction.Type = "credit"
+	}
+	transaction.TransactionDate = remote.Date
+	transaction.Pending = remote.Pending
+
+	description := strings.TrimSpace(remote.Name)
+	if description == "" {
+		description = remote.MerchantName
+	}
+
+	if !isNew {
+		transaction.Description = description
+		if err := tx.Omit(clause.Associations).Save(&transaction).Error; err != nil {
+			return err
+		}
+		return s.budgets.UpdateSpending(tx, &previous, &transaction)
+	}
+
+	transaction.Description = description
+	if err := rules.Apply(tx, &transaction); err != nil {
+		return err
+	}
+	if _, err := s.categorizer.AutoCategorize(&transaction); err != nil {
+		return err
+	}
+	if err := tx.Omit("User", "Account").Create(&transaction).Error; err != nil {
+		return err
+	}
+	return s.budgets.RecordSpending(tx, &transaction)
+}
+
+// removeTransactions deletes the local copies of provider transactions
+// that no longer exist, releasing their budget spending and attachments.
+// Removals only carry the transaction ID, so they match any of the item's
+// accounts.
+func (s *BankLinkService) removeTransactions(tx *gorm.DB, accounts map[string]models.Account, externalIDs []string) error {
+	accountIDs := make([]uint, 0, len(accounts))
+	for _, account := range accounts {
+		accountIDs = append(accountIDs, account.ID)
+	}
+	if len(accountIDs) == 0 {
+		return nil
+	}
+
+	var transactions []models.Transaction
+	if err := tx.Where("account_id IN ? AND external_id IN ?", accountIDs, externalIDs).
+		Find(&transactions).Error; err != nil {
+		return err
+	}
+	if len(transactions) == 0 {
+		return nil
+	}
+
+	ids := make([]uint, len(transactions))
+	for i := range transactions {
+		if err := s.budgets.ReleaseSpending(tx, &transactions[i]); err != nil {
+			return err
+		}
+		ids[i] = transactions[i].ID
+	}
+	if err := s.attachments.ReleaseForTransactions(tx, ids); err != nil {
+		return err
+	}
+	return tx.Delete(&models.Transaction{}, ids).Error
+}
+
+// recordItemError stores provider failures on the item so clients can
+// prompt the user to re-authenticate.
+func (s *BankLinkService) recordItemError(item *models.BankItem, cause error) error {
+	status := models.BankItemError
+	if errors.Is(cause, aggregator.ErrItemLoginRequired) {
+		status = models.BankItemLoginRequired
+	}
+	if err := s.SetItemStatus(item, status, cause.Error()); err != nil {
+		return err
+	}
+	return cause
+}
+
+func (s *BankLinkService) SetItemStatus(item *models.BankItem, status, lastError string) error {
+	item.Status = status
+	item.LastError = lastError
+	return s.db.Model(item).Select("status", "last_error").Updates(item).Error
+}
+
+func mapAccountType(providerType, subtype string) string {
+	switch providerType {
+	case "depository":
+		if subtype == "savings" || subtype == "cd" || subtype == "money market" {
+			return "savings"
+		}
+		return "checking"
+	case "credit":
+		return "credit"
+	case "loan":
+		return "loan"
+	case "investment", "brokerage":
+		return "investment"
+	}
+	return "checking"
+}
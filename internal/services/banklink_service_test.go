@@ -0,0 +1,320 @@
+// internal/services/banklink_service_test.go
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"finbro-backend-go/internal/aggregator"
+	"finbro-backend-go/internal/db"
+	"finbro-backend-go/internal/db/dbtest"
+	"finbro-backend-go/internal/db/models"
+	"finbro-backend-go/internal/jobs"
+	"finbro-backend-go/internal/secrets"
+	"finbro-backend-go/internal/storage"
+)
+
+func newTestBankLinkService(t *testing.T, database *db.DB, agg aggregator.Aggregator) (*BankLinkService, *secrets.Encrypter) {
+	t.Helper()
+	encrypter, err := secrets.NewEncrypter(strings.Repeat("ab", 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	store, err := storage.NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	queue := jobs.NewQueue(database)
+	categories := NewCategoryService(database)
+	categorizer := NewCategorizerService(database, 0.9)
+	webhooks := NewOutboundWebhookService(database, queue)
+	budgets := NewBudgetService(database, webhooks)
+	rules := NewRuleService(database, categories, categorizer, budgets, webhooks)
+	attachments := NewAttachmentService(database, store, queue, 1<<20)
+	return NewBankLinkService(database, agg, encrypter, rules, categorizer, budgets, attachments), encrypter
+}
+
+func TestBankSyncMirrorsProviderChanges(t *testing.T) {
+	database := dbtest.Open(t)
+	ctx := context.Background()
+	fake := aggregator.NewFake()
+	svc, encrypter := newTestBankLinkService(t, database, fake)
+	user := dbtest.CreateUser(t, database)
+
+	item, err := svc.ExchangePublicToken(ctx, user.ID, "public-fake-test")
+	if err != nil {
+		t.Fatalf("ExchangePublicToken: %v", err)
+	}
+	if len(item.Accounts) != 2 {
+		t.Fatalf("linked %d accounts, want 2", len(item.Accounts))
+	}
+	for _, account := range item.Accounts {
+		if account.BankItemPublicID == nil || *account.BankItemPublicID != item.PublicID {
+			t.Errorf("account %s: bank_item_id = %v, want %s", account.AccountName, account.BankItemPublicID, item.PublicID)
+		}
+	}
+	if n := countTransactions(t, database, user.ID); n != 4 {
+		t.Fatalf("initial sync imported %d transactions, want the 4 seeded ones", n)
+	}
+
+	var payroll models.Transaction
+	if err := database.Where("user_id = ? AND description = ?", user.ID, "ACME CORP PAYROLL").First(&payroll).Error; err != nil {
+		t.Fatal(err)
+	}
+	if payroll.Type != "credit" || payroll.Amount != 2500 {
+		t.Errorf("payroll: got %s %.2f, want credit 2500.00", payroll.Type, payroll.Amount)
+	}
+
+	// The budget only covers today, so only the transaction added below
+	// counts against it.
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	budget := &models.Budget{
+		UserID:    user.ID,
+		Name:      "Everything",
+		Amount:    100,
+		Period:    "monthly",
+		StartDate: today,
+		EndDate:   today.AddDate(0, 0, 1),
+		IsActive:  true,
+	}
+	if err := database.Create(budget).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	accessToken, err := encrypter.Decrypt(item.AccessToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	added := aggregator.Transaction{
+		AccountExternalID: *item.Accounts[0].ExternalID,
+		Amount:            12.5,
+		Name:              "CORNER DELI",
+		Date:              today,
+		Currency:          "USD",
+	}
+	added.ExternalID, err = fake.AddTransaction(accessToken, added)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	summary := syncItem(t, svc, item)
+	if *summary != (SyncSummary{Added: 1}) {
+		t.Fatalf("sync after add: got %+v", *summary)
+	}
+	local := syncedTransaction(t, database, added.ExternalID)
+	if local.Amount != 12.5 || local.Type != "debit" || local.Description != "CORNER DELI" {
+		t.Errorf("added: got %s %.2f %q", local.Type, local.Amount, local.Description)
+	}
+	assertSpent(t, database, budget.ID, 12.5)
+
+	added.Amount = 20
+	added.Name = "CORNER DELI AND GROCERY"
+	if err := fake.ModifyTransaction(accessToken, added); err != nil {
+		t.Fatal(err)
+	}
+	summary = syncItem(t, svc, item)
+	if *summary != (SyncSummary{Modified: 1}) {
+		t.Fatalf("sync after modify: got %+v", *summary)
+	}
+	modified := syncedTransaction(t, database, added.ExternalID)
+	if modified.ID != local.ID || modified.Amount != 20 || modified.Description != "CORNER DELI AND GROCERY" {
+		t.Errorf("modified: got #%d %.2f %q", modified.ID, modified.Amount, modified.Description)
+	}
+	assertSpent(t, database, budget.ID, 20)
+
+	if err := fake.RemoveTransaction(accessToken, added.ExternalID); err != nil {
+		t.Fatal(err)
+	}
+	summary = syncItem(t, svc, item)
+	if *summary != (SyncSummary{Removed: 1}) {
+		t.Fatalf("sync after remove: got %+v", *summary)
+	}
+	var remaining int64
+	if err := database.Model(&models.Transaction{}).Where("external_id = ?", added.ExternalID).Count(&remaining).Error; err != nil {
+		t.Fatal(err)
+	}
+	if remaining != 0 {
+		t.Errorf("removed transaction is still stored")
+	}
+	assertSpent(t, database, budget.ID, 0)
+
+	// Nothing new upstream: the stored cursor makes the next sync a no-op.
+	if summary := syncItem(t, svc, item); *summary != (SyncSummary{}) {
+		t.Errorf("idle sync: got %+v", *summary)
+	}
+	if n := countTransactions(t, database, user.ID); n != 4 {
+		t.Errorf("after syncs: %d transactions, want 4", n)
+	}
+}
+
+// A transaction on an account opened since the last sync must not stall
+// the item.
+func TestBankSyncPicksUpNewAccounts(t *testing.T) {
+	database := dbtest.Open(t)
+	ctx := context.Background()
+	fake := aggregator.NewFake()
+	svc, encrypter := newTestBankLinkService(t, database, fake)
+	user := dbtest.CreateUser(t, database)
+
+	item, err := svc.ExchangePublicToken(ctx, user.ID, "public-fake-test")
+	if err != nil {
+		t.Fatalf("ExchangePublicToken: %v", err)
+	}
+	accessToken, err := encrypter.Decrypt(item.AccessToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	savingsID, err := fake.AddAccount(accessToken, aggregator.Account{
+		Name: "Fake Savings", Type: "depository", Subtype: "savings", CurrentBalance: 500, Currency: "USD",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	transactionID, err := fake.AddTransaction(accessToken, aggregator.Transaction{
+		AccountExternalID: savingsID,
+		Amount:            -500,
+		Name:              "TRANSFER FROM CHECKING",
+		Date:              time.Now().UTC().Truncate(24 * time.Hour),
+		Currency:          "USD",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if summary := syncItem(t, svc, item); *summary != (SyncSummary{Added: 1}) {
+		t.Fatalf("sync after new account: got %+v", *summary)
+	}
+	var savings models.Account
+	if err := database.Where("bank_item_id = ? AND external_id = ?", item.ID, savingsID).First(&savings).Error; err != nil {
+		t.Fatalf("new account was not imported: %v", err)
+	}
+	if savings.UserID != user.ID || savings.Balance != 500 {
+		t.Errorf("new account: got user %d, balance %.2f", savings.UserID, savings.Balance)
+	}
+	if local := syncedTransaction(t, database, transactionID); local.AccountID != savings.ID {
+		t.Errorf("transaction went to account #%d, want #%d", local.AccountID, savings.ID)
+	}
+}
+
+// Provider IDs are only unique within an item: rows another user already
+// has under the same external IDs must stay theirs.
+func TestBankSyncKeepsExternalIDsPerItem(t *testing.T) {
+	database := dbtest.Open(t)
+	ctx := context.Background()
+	fake := aggregator.NewFake()
+	svc, encrypter := newTestBankLinkService(t, database, fake)
+
+	linked, err := fake.ExchangePublicToken(ctx, "public-fake-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	remoteAccounts, err := fake.GetAccounts(ctx, linked.AccessToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	page, err := fake.SyncTransactions(ctx, linked.AccessToken, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Another user's item already holds the same account and transaction IDs.
+	other := dbtest.CreateUser(t, database)
+	otherItem := &models.BankItem{UserID: other.ID, Provider: "fake", ItemID: "item-other-" + linked.ItemID, AccessToken: "-", Status: models.BankItemActive}
+	if err := database.Create(otherItem).Error; err != nil {
+		t.Fatal(err)
+	}
+	otherAccount := dbtest.CreateAccount(t, database, other.ID)
+	accountExternalID := page.Added[0].AccountExternalID
+	otherAccount.BankItemID = &otherItem.ID
+	otherAccount.ExternalID = &accountExternalID
+	if err := database.Save(otherAccount).Error; err != nil {
+		t.Fatal(err)
+	}
+	otherTransaction := dbtest.CreateTransaction(t, database, otherAccount, "Theirs", 10)
+	transactionExternalID := page.Added[0].ExternalID
+	otherTransaction.ExternalID = &transactionExternalID
+	if err := database.Save(otherTransaction).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	user := dbtest.CreateUser(t, database)
+	encrypted, err := encrypter.Encrypt(linked.AccessToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	item := &models.BankItem{UserID: user.ID, Provider: "fake", ItemID: linked.ItemID, AccessToken: encrypted, Status: models.BankItemActive}
+	if err := database.Create(item).Error; err != nil {
+		t.Fatal(err)
+	}
+	if summary := syncItem(t, svc, item); summary.Added != len(page.Added) {
+		t.Fatalf("sync: got %+v, want %d added", *summary, len(page.Added))
+	}
+
+	var accounts int64
+	if err := database.Model(&models.Account{}).Where("user_id = ? AND bank_item_id = ?", user.ID, item.ID).Count(&accounts).Error; err != nil {
+		t.Fatal(err)
+	}
+	if accounts != int64(len(remoteAccounts)) {
+		t.Errorf("user has %d linked accounts, want %d", accounts, len(remoteAccounts))
+	}
+	if n := countTransactions(t, database, user.ID); n != int64(len(page.Added)) {
+		t.Errorf("user has %d transactions, want %d", n, len(page.Added))
+	}
+
+	var theirs models.Account
+	if err := database.First(&theirs, otherAccount.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if theirs.UserID != other.ID || theirs.BankItemID == nil || *theirs.BankItemID != otherItem.ID {
+		t.Errorf("other user's account moved to user %d, item %v", theirs.UserID, theirs.BankItemID)
+	}
+	var theirTransaction models.Transaction
+	if err := database.First(&theirTransaction, otherTransaction.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if theirTransaction.UserID != other.ID || theirTransaction.AccountID != otherAccount.ID || theirTransaction.Description != "Theirs" {
+		t.Errorf("other user's transaction changed: user %d, account %d, %q", theirTransaction.UserID, theirTransaction.AccountID, theirTransaction.Description)
+	}
+}
+
+func syncItem(t *testing.T, svc *BankLinkService, item *models.BankItem) *SyncSummary {
+	t.Helper()
+	summary, err := svc.SyncItem(context.Background(), item)
+	if err != nil {
+		t.Fatalf("SyncItem: %v", err)
+	}
+	return summary
+}
+
+func syncedTransaction(t *testing.T, database *db.DB, externalID string) models.Transaction {
+	t.Helper()
+	var transaction models.Transaction
+	if err := database.Where("external_id = ?", externalID).First(&transaction).Error; err != nil {
+		t.Fatalf("transaction %s: %v", externalID, err)
+	}
+	return transaction
+}
+
+func countTransactions(t *testing.T, database *db.DB, userID uint) int64 {
+	t.Helper()
+	var count int64
+	if err := database.Model(&models.Transaction{}).Where("user_id = ?", userID).Count(&count).Error; err != nil {
+		t.Fatal(err)
+	}
+	return count
+}
+
+func assertSpent(t *testing.T, database *db.DB, budgetID uint, want float64) {
+	t.Helper()
+	var budget models.Budget
+	if err := database.First(&budget, budgetID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if budget.Spent != want {
+		t.Errorf("budget spent = %.2f, want %.2f", budget.Spent, want)
+	}
+}
@@ -0,0 +1,150 @@
+// internal/services/budget_service.go
+package services
+
+import (
+	"slices"
+
+	"finbro-backend-go/internal/db"
+	"finbro-backend-go/internal/db/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type BudgetService struct {
+	db       *db.DB
+	webhooks *OutboundWebhookService
+}
+
+func NewBudgetService(db *db.DB, webhooks *OutboundWebhookService) *BudgetService {
+	return &BudgetService{db: db, webhooks: webhooks}
+}
+
+// RecordSpending adds a new debit to every active budget it falls under. A
+// budget without a category covers all spending; one with a category also
+// covers that category's children. A split debit counts each split under
+// its own category.
+func (s *BudgetService) RecordSpending(tx *gorm.DB, t *models.Transaction) error {
+	return s.UpdateSpending(tx, nil, t)
+}
+
+// ReleaseSpending takes a transaction that is about to be deleted off the
+// budgets it was recorded against.
+func (s *BudgetService) ReleaseSpending(tx *gorm.DB, t *models.Transaction) error {
+	return s.UpdateSpending(tx, t, nil)
+}
+
+// UpdateSpending moves a transaction's contribution to budgets from before
+// to after, either of which may be nil. Call it in the database transaction
+// that changes the amount, type, category, date or splits of a transaction,
+// with before holding the old values. Each budget moves by its net change,
+// and budget.exceeded is published for every budget that goes over its
+// amount because of it, so an edit that leaves a budget over does not
+// announce it again. Splits not loaded on before or after are read from tx.
+func (s *BudgetService) UpdateSpending(tx *gorm.DB, before, after *models.Transaction) error {
+	deltas := make(map[uint]float64)
+	budgets := make(map[uint]*models.Budget)
+	collect := func(t *models.Transaction, sign float64) error {
+		if t == nil {
+			return nil
+		}
+		lines, err := spendingLines(tx, t)
+		if err != nil {
+			return err
+		}
+		for _, line := range lines {
+			matched, err := s.matchingBudgets(tx, t, line.categoryID)
+			if err != nil {
+				return err
+			}
+			for i := range matched {
+				if _, ok := budgets[matched[i].ID]; !ok {
+					budgets[matched[i].ID] = &matched[i]
+				}
+				deltas[matched[i].ID] += sign * line.amount
+			}
+		}
+		return nil
+	}
+	if err := collect(before, -1); err != nil {
+		return err
+	}
+	if err := collect(after, 1); err != nil {
+		return err
+	}
+
+	ids := make([]uint, 0, len(deltas))
+	for id := range deltas {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+
+	for _, id := range ids {
+		delta := deltas[id]
+		if delta == 0 {
+			continue
+		}
+		budget := budgets[id]
+		wasOver := budget.Spent > budget.Amount
+		budget.Spent += delta
+
+		if err := tx.Model(budget).Update("spent", gorm.Expr("spent + ?", delta)).Error; err != nil {
+			return err
+		}
+		if !wasOver && budget.Spent > budget.Amount {
+			if err := s.webhooks.Publish(tx, budget.UserID, EventBudgetExceeded, budget); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+type spendingLine struct {
+	categoryID *uint
+	amount     float64
+}
+
+// spendingLines is what a transaction contributes to budgets: one line per
+// split, or the whole amount under its own category. Credits contribute
+// nothing. A saved transaction whose splits are not loaded has them read
+// from tx.
+func spendingLines(tx *gorm.DB, t *models.Transaction) ([]spendingLine, error) {
+	if t.Type != "debit" {
+		return nil, nil
+	}
+	splits := t.Splits
+	if splits == nil && t.ID != 0 {
+		if err := tx.Where("transaction_id = ?", t.ID).Order("id").Find(&splits).Error; err != nil {
+			return nil, err
+		}
+	}
+	if len(splits) == 0 {
+		return []spendingLine{{categoryID: t.CategoryID, amount: t.Amount}}, nil
+	}
+	lines := make([]spendingLine, len(splits))
+	for i, split := range splits {
+		lines[i] = spendingLine{categoryID: split.CategoryID, amount: split.Amount}
+	}
+	return lines, nil
+}
+
+// matchingBudgets locks and returns every active budget covering
+// categoryID on the transaction's date.
+func (s *BudgetService) matchingBudgets(tx *gorm.DB, t *models.Transaction, categoryID *uint) ([]models.Budget, error) {
+	query := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("user_id = ? AND is_active = ?", t.UserID, true).
+		Where("start_date <= ? AND (end_date IS NULL OR end_date >= ? OR end_date < start_date)",
+			t.TransactionDate, t.TransactionDate)
+	if categoryID != nil {
+		query = query.Where(
+			"category_id IS NULL OR category_id = ? OR category_id = (SELECT parent_id FROM categories WHERE id = ?)",
+			*categoryID, *categoryID)
+	} else {
+		query = query.Where("category_id IS NULL")
+	}
+
+	var budgets []models.Budget
+	err := query.Order("id").Find(&budgets).Error
+	return budgets, err
+}
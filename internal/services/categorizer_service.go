@@ -0,0 +1,239 @@
+// internal/services/categorizer_service.go
+package services
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"finbro-backend-go/internal/db"
+	"finbro-backend-go/internal/db/models"
+	"finbro-backend-go/internal/utils"
+)
+
+const (
+	categorizerTrainingLimit = 5000
+	categorizerModelTTL      = 10 * time.Minute
+	// Below this many categorized transactions the model is still used for
+	// suggestions but never applies a category on its own.
+	categorizerMinExamples = 20
+)
+
+type CategorySuggestion struct {
+	CategoryID uint    `json:"category_id"`
+	Category   string  `json:"category"`
+	Confidence float64 `json:"confidence"`
+}
+
+// CategorizerService suggests categories from the user's own history using
+// a multinomial naive Bayes model over description tokens. Models are
+// trained lazily per user and cached in memory.
+type CategorizerService struct {
+	db                 *db.DB
+	autoApplyThreshold float64
+
+	mu     sync.Mutex
+	models map[uint]*naiveBayesModel
+}
+
+func NewCategorizerService(db *db.DB, autoApplyThreshold float64) *CategorizerService {
+	return &CategorizerService{
+		db:                 db,
+		autoApplyThreshold: autoApplyThreshold,
+		models:             make(map[uint]*naiveBayesModel),
+	}
+}
+
+type naiveBayesModel struct {
+	trainedAt   time.Time
+	examples    int
+	classDocs   map[uint]int
+	classTokens map[uint]map[string]int
+	classTotals map[uint]int
+	classNames  map[uint]string
+	vocabulary  map[string]struct{}
+}
+
+// Suggest returns up to limit categories for the transaction, best first.
+func (s *CategorizerService) Suggest(transaction *models.Transaction, limit int) ([]CategorySuggestion, error) {
+	model, err := s.model(transaction.UserID)
+	if err != nil {
+		return nil, err
+	}
+	return model.predict(categorizerTokens(transaction), limit), nil
+}
+
+// AutoCategorize sets the category of an uncategorized transaction when the
+// top suggestion is confident enough. It reports whether it did.
+func (s *CategorizerService) AutoCategorize(transaction *models.Transaction) (bool, error) {
+	if transaction.CategoryID != nil || s.autoApplyThreshold <= 0 || s.autoApplyThreshold > 1 {
+		return false, nil
+	}
+
+	model, err := s.model(transaction.UserID)
+	if err != nil {
+		return false, err
+	}
+	if model.examples < categorizerMinExamples {
+		return false, nil
+	}
+
+	suggestions := model.predict(categorizerTokens(transaction), 1)
+	if len(suggestions) == 0 || suggestions[0].Confidence < s.autoApplyThreshold {
+		return false, nil
+	}
+
+	transaction.CategoryID = &suggestions[0].CategoryID
+	transaction.Category = suggestions[0].Category
+	return true, nil
+}
+
+// Invalidate drops the cached model so the next call retrains it, e.g. after
+// the user recategorized a transaction.
+func (s *CategorizerService) Invalidate(userID uint) {
+	s.mu.Lock()
+	delete(s.models, userID)
+	s.mu.Unlock()
+}
+
+func (s *CategorizerService) model(userID uint) (*naiveBayesModel, error) {
+	s.mu.Lock()
+	cached, ok := s.models[userID]
+	s.mu.Unlock()
+	if ok && time.Since(cached.trainedAt) < categorizerModelTTL {
+		return cached, nil
+	}
+
+	model, err := s.train(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	s.models[userID] = model
+	s.mu.Unlock()
+	return model, nil
+}
+
+func (s *CategorizerService) train(userID uint) (*naiveBayesModel, error) {
+	var history []models.Transaction
+	if err := s.db.Select("description", "merchant", "category_id", "category").
+		Where("user_id = ? AND category_id IS NOT NULL", userID).
+		Order("transaction_date DESC").
+		Limit(categorizerTrainingLimit).
+		Find(&history).Error; err != nil {
+		return nil, err
+	}
+
+	model := &naiveBayesModel{
+		trainedAt:   time.Now(),
+		classDocs:   make(map[uint]int),
+		classTokens: make(map[uint]map[string]int),
+		classTotals: make(map[uint]int),
+		classNames:  make(map[uint]string),
+		vocabulary:  make(map[string]struct{}),
+	}
+
+	for i := range history {
+		t := &history[i]
+		tokens := categorizerTokens(t)
+		if len(tokens) == 0 {
+			continue
+		}
+
+		class := *t.CategoryID
+		model.examples++
+		model.classDocs[class]++
+		model.classNames[class] = t.Category
+		if model.classTokens[class] == nil {
+			model.classTokens[class] = make(map[string]int)
+		}
+		for _, token := range tokens {
+			model.classTokens[class][token]++
+			model.classTotals[class]++
+			model.vocabulary[token] = struct{}{}
+		}
+	}
+
+	return model, nil
+}
+
+// predict scores every class with Laplace smoothed log probabilities and
+// turns the scores into confidences with a softmax.
+func (m *naiveBayesModel) predict(tokens []string, limit int) []CategorySuggestion {
+	known := tokens[:0:0]
+	for _, token := range tokens {
+		if _, ok := m.vocabulary[token]; ok {
+			known = append(known, token)
+		}
+	}
+	if len(known) == 0 || m.examples == 0 {
+		return []CategorySuggestion{}
+	}
+
+	vocabSize := float64(len(m.vocabulary))
+	scores := make(map[uint]float64, len(m.classDocs))
+	maxScore := math.Inf(-1)
+	for class, docs := range m.classDocs {
+		score := math.Log(float64(docs) / float64(m.examples))
+		denominator := float64(m.classTotals[class]) + vocabSize
+		for _, token := range known {
+			score += math.Log((float64(m.classTokens[class][token]) + 1) / denominator)
+		}
+		scores[class] = score
+		maxScore = math.Max(maxScore, score)
+	}
+
+	var sum float64
+	for _, score := range scores {
+		sum += math.Exp(score - maxScore)
+	}
+
+	suggestions := make([]CategorySuggestion, 0, len(scores))
+	for class, score := range scores {
+		suggestions = append(suggestions, CategorySuggestion{
+			CategoryID: class,
+			Category:   m.classNames[class],
+			Confidence: math.Round(math.Exp(score-maxScore)/sum*1e4) / 1e4,
+		})
+	}
+	sort.Slice(suggestions, func(i, j int) bool {
+		if suggestions[i].Confidence != suggestions[j].Confidence {
+			return suggestions[i].Confidence > suggestions[j].Confidence
+		}
+		return suggestions[i].CategoryID < suggestions[j].CategoryID
+	})
+
+	if limit > 0 && len(suggestions) > limit {
+		suggestions = suggestions[:limit]
+	}
+	return suggestions
+}
+
+// categorizerTokens extracts the features of a transaction: lowercase words
+// of the description plus the normalized merchant as a single token.
+func categorizerTokens(t *models.Transaction) []string {
+	words := strings.FieldsFunc(strings.ToLower(t.Description), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	tokens := make([]string, 0, len(words)+1)
+	for _, word := range words {
+		if len(word) < 2 || strings.IndexFunc(word, unicode.IsLetter) < 0 {
+			continue
+		}
+		tokens = append(tokens, word)
+	}
+
+	merchant := t.Merchant
+	if merchant == "" {
+		merchant = utils.NormalizeMerchant(t.Description)
+	}
+	if merchant != "" {
+		tokens = append(tokens, "merchant:"+strings.ToLower(merchant))
+	}
+	return tokens
+}
@@ -0,0 +1,292 @@
+// internal/services/category_service.go
+package services
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"finbro-backend-go/internal/db"
+	"finbro-backend-go/internal/db/models"
+	"finbro-backend-go/internal/utils"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrCategoryNotFound  = errors.New("category not found")
+	ErrSystemCategory    = errors.New("system categories cannot be modified")
+	ErrDuplicateCategory = errors.New("a category with this name already exists")
+	ErrInvalidParent     = errors.New("parent must be a top-level category")
+	ErrInvalidCategory   = errors.New("category name must contain a letter or digit")
+	ErrCategoryInUse     = errors.New("category is still used by a rule or budget")
+)
+
+type CategoryService struct {
+	db *db.DB
+}
+
+func NewCategoryService(db *db.DB) *CategoryService {
+	return &CategoryService{db: db}
+}
+
+// visibleTo scopes a query to system categories and the user's own ones.
+func visibleTo(query *gorm.DB, userID uint) *gorm.DB {
+	return query.Where("user_id IS NULL OR user_id = ?", userID)
+}
+
+// GetCategoryTree returns the categories visible to the user as a list of
+// top-level categories with their children attached.
+func (s *CategoryService) GetCategoryTree(userID uint) ([]models.Category, error) {
+	var categories []models.Category
+	if err := visibleTo(s.db.Model(&models.Category{}), userID).
+		Order("is_system DESC, name ASC").
+		Find(&categories).Error; err != nil {
+		return nil, err
+	}
+
+	children := make(map[uint][]models.Category)
+	for _, category := range categories {
+		if category.ParentID != nil {
+			children[*category.ParentID] = append(children[*category.ParentID], category)
+		}
+	}
+
+	roots := make([]models.Category, 0)
+	for _, category := range categories {
+		if category.ParentID == nil {
+			category.Children = children[category.ID]
+			roots = append(roots, category)
+		}
+	}
+	return roots, nil
+}
+
+// GetCategoryByID loads a category the user can see. It reads through tx so
+// callers inside a database transaction see their own writes.
+func (s *CategoryService) GetCategoryByID(tx *gorm.DB, categoryID, userID uint) (*models.Category, error) {
+	var category models.Category
+	err := visibleTo(tx.Where("id = ?", categoryID), userID).First(&category).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrCategoryNotFound
+	}
+	return &category, err
+}
+
+// ResolveByName finds the category a free-form name refers to, creating a
+// custom category for the user when nothing matches. It lets clients that
+// still send category names share records with clients that send IDs. It
+// runs on tx, so a category created for a write that rolls back goes too.
+func (s *CategoryService) ResolveByName(tx *gorm.DB, userID uint, name string) (*models.Category, error) {
+	name = strings.TrimSpace(name)
+	slug := utils.Slugify(name)
+	if slug == "" {
+		return nil, ErrInvalidCategory
+	}
+
+	category, err := findBySlug(tx, userID, slug)
+	if err == nil || !errors.Is(err, gorm.ErrRecordNotFound) {
+		return category, err
+	}
+
+	// A concurrent request may create the same category first; the unique
+	// slug index makes this insert a no-op and the lookup below finds theirs.
+	created := models.Category{UserID: &userID, Name: name, Slug: slug}
+	result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&created)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 1 {
+		return &created, nil
+	}
+	return findBySlug(tx, userID, slug)
+}
+
+// findBySlug prefers the system category when the user's own one somehow
+// shares its slug.
+func findBySlug(tx *gorm.DB, userID uint, slug string) (*models.Category, error) {
+	var category models.Category
+	err := visibleTo(tx.Where("slug = ?", slug), userID).
+		Order("user_id NULLS FIRST").
+		First(&category).Error
+	if err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
+func (s *CategoryService) CreateCategory(userID uint, category *models.Category) error {
+	category.ID = 0
+	category.UserID = &userID
+	category.IsSystem = false
+	category.Slug = utils.Slugify(category.Name)
+
+	if err := s.validate(userID, category); err != nil {
+		return err
+	}
+
+	return duplicateError(s.db.Create(category).Error)
+}
+
+// UpdateCategory saves changes to a custom category and keeps the
+// denormalized category name on transactions and budgets in sync.
+func (s *CategoryService) UpdateCategory(userID uint, category *models.Category) error {
+	if category.IsSystem || category.UserID == nil || *category.UserID != userID {
+		return ErrSystemCategory
+	}
+	category.Slug = utils.Slugify(category.Name)
+
+	if err := s.validate(userID, category); err != nil {
+		return err
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Children").Save(category).Error; err != nil {
+			return duplicateError(err)
+		}
+		for _, model := range []interface{}{&models.Transaction{}, &models.TransactionSplit{}, &models.Budget{}} {
+			if err := tx.Model(model).
+				Where("user_id = ? AND category_id = ?", userID, category.ID).
+				UpdateColumn("category", category.Name).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// DeleteCategory removes a custom category. Its transactions and child
+// categories move up to the deleted category's parent. Rules and budgets are
+// not moved, since that would change what they do, so a category either
+// still references is refused with ErrCategoryInUse.
+func (s *CategoryService) DeleteCategory(categoryID, userID uint) error {
+	category, err := s.GetCategoryByID(s.db.DB, categoryID, userID)
+	if err != nil {
+		return err
+	}
+	if category.IsSystem || category.UserID == nil {
+		return ErrSystemCategory
+	}
+
+	var parent *models.Category
+	if category.ParentID != nil {
+		if parent, err = s.GetCategoryByID(s.db.DB, *category.ParentID, userID); err != nil {
+			return err
+		}
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := checkUnused(tx, userID, category.ID); err != nil {
+			return err
+		}
+
+		updates := map[string]interface{}{"category_id": nil, "category": ""}
+		if parent != nil {
+			updates = map[string]interface{}{"category_id": parent.ID, "category": parent.Name}
+		}
+		for _, model := range []interface{}{&models.Transaction{}, &models.TransactionSplit{}} {
+			if err := tx.Model(model).
+				Where("user_id = ? AND category_id = ?", userID, category.ID).
+				UpdateColumns(updates).Error; err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Model(&models.Category{}).
+			Where("parent_id = ?", category.ID).
+			UpdateColumn("parent_id", category.ParentID).Error; err != nil {
+			return err
+		}
+
+		return tx.Delete(&models.Category{}, category.ID).Error
+	})
+}
+
+// checkUnused reports ErrCategoryInUse while a budget or rule of the user
+// points at the category.
+func checkUnused(tx *gorm.DB, userID, categoryID uint) error {
+	var budgets int64
+	if err := tx.Model(&models.Budget{}).
+		Where("user_id = ? AND category_id = ?", userID, categoryID).
+		Count(&budgets).Error; err != nil {
+		return err
+	}
+
+	var rules int64
+	if err := tx.Model(&models.Rule{}).
+		Where("user_id = ? AND actions->>'set_category_id' = ?", userID, strconv.FormatUint(uint64(categoryID), 10)).
+		Count(&rules).Error; err != nil {
+		return err
+	}
+
+	if budgets > 0 || rules > 0 {
+		return ErrCategoryInUse
+	}
+	return nil
+}
+
+func (s *CategoryService) validate(userID uint, category *models.Category) error {
+	if category.Slug == "" {
+		return ErrInvalidCategory
+	}
+	if err := s.validateParent(userID, category); err != nil {
+		return err
+	}
+	return s.checkDuplicate(userID, category)
+}
+
+func (s *CategoryService) validateParent(userID uint, category *models.Category) error {
+	if category.ParentID == nil {
+		return nil
+	}
+	if category.ID != 0 && *category.ParentID == category.ID {
+		return ErrInvalidParent
+	}
+
+	parent, err := s.GetCategoryByID(s.db.DB, *category.ParentID, userID)
+	if err != nil {
+		return err
+	}
+	if parent.ParentID != nil {
+		return ErrInvalidParent
+	}
+
+	if category.ID != 0 {
+		var childCount int64
+		if err := s.db.Model(&models.Category{}).
+			Where("parent_id = ?", category.ID).
+			Count(&childCount).Error; err != nil {
+			return err
+		}
+		if childCount > 0 {
+			return ErrInvalidParent
+		}
+	}
+	return nil
+}
+
+// checkDuplicate also rejects a custom category named like a system one,
+// which would otherwise shadow it.
+func (s *CategoryService) checkDuplicate(userID uint, category *models.Category) error {
+	var count int64
+	err := visibleTo(s.db.Model(&models.Category{}), userID).
+		Where("slug = ? AND id <> ?", category.Slug, category.ID).
+		Count(&count).Error
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrDuplicateCategory
+	}
+	return nil
+}
+
+// duplicateError turns a unique slug index violation, left for a request
+// that raced past checkDuplicate, into ErrDuplicateCategory.
+func duplicateError(err error) error {
+	if db.IsUniqueViolation(err) {
+		return ErrDuplicateCategory
+	}
+	return err
+}
@@ -0,0 +1,216 @@
+// internal/services/idempotency_service.go
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"finbro-backend-go/internal/db"
+	"finbro-backend-go/internal/db/models"
+	"finbro-backend-go/internal/jobs"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	JobPruneIdempotencyKeys = "idempotency.prune"
+
+	// IdempotencyTTL is how long a stored response can be replayed.
+	IdempotencyTTL = 24 * time.Hour
+
+	// idempotencyLockTimeout is how long a key stays locked after its
+	// holder last extended it. Holders extend it every
+	// idempotencyLockHeartbeat for as long as the request runs, so a retry
+	// only takes over from a process that died or lost the database for
+	// this long.
+	idempotencyLockTimeout   = time.Minute
+	idempotencyLockHeartbeat = idempotencyLockTimeout / 4
+)
+
+var (
+	ErrIdempotencyKeyReused   = errors.New("idempotency key was already used for a different request")
+	ErrIdempotencyKeyInFlight = errors.New("a request with this idempotency key is still in progress")
+)
+
+// ReplayedHeaders are the response headers stored with an idempotent
+// response and sent again on replay.
+var ReplayedHeaders = []string{"Content-Type", "Location", "ETag"}
+
+type IdempotencyService struct {
+	db *db.DB
+}
+
+func NewIdempotencyService(db *db.DB) *IdempotencyService {
+	return &IdempotencyService{db: db}
+}
+
+// Begin claims key for a request. When acquired is true the caller runs the
+// request and must then call Complete or Release. Otherwise the returned
+// record holds a completed response to replay.
+func (s *IdempotencyService) Begin(ctx context.Context, userID uint, key, method, path, requestHash string) (record *models.IdempotencyKey, acquired bool, err error) {
+	database := s.db.WithContext(ctx)
+	now := time.Now()
+
+	// Expired keys can be reused as if they had never been seen.
+	if err := database.Where("user_id = ? AND key = ? AND expires_at <= ?", userID, key, now).
+		Delete(&models.IdempotencyKey{}).Error; err != nil {
+		return nil, false, err
+	}
+
+	record = &models.IdempotencyKey{
+		UserID:      userID,
+		Key:         key,
+		Method:      method,
+		Path:        path,
+		RequestHash: requestHash,
+		Status:      models.IdempotencyProcessing,
+		LockedUntil: lockExpiry(now),
+		ExpiresAt:   now.Add(IdempotencyTTL),
+	}
+	result := database.Clauses(clause.OnConflict{DoNothing: true}).Create(record)
+	if result.Error != nil {
+		return nil, false, result.Error
+	}
+	if result.RowsAffected == 1 {
+		return record, true, nil
+	}
+
+	var existing models.IdempotencyKey
+	err = database.Where("user_id = ? AND key = ?", userID, key).First(&existing).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		// Released between our insert and lookup; the client can retry.
+		return nil, false, ErrIdempotencyKeyInFlight
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	if existing.RequestHash != requestHash {
+		return nil, false, ErrIdempotencyKeyReused
+	}
+	if existing.Status == models.IdempotencyCompleted {
+		return &existing, false, nil
+	}
+
+	// Take over a lock whose holder died without releasing it. The
+	// conditional update makes sure only one retry wins.
+	if existing.LockedUntil.Before(now) {
+		lockedUntil := lockExpiry(now)
+		result := database.Model(&models.IdempotencyKey{}).
+			Where("id = ? AND status = ? AND locked_until = ?", existing.ID, models.IdempotencyProcessing, existing.LockedUntil).
+			Update("locked_until", lockedUntil)
+		if result.Error != nil {
+			return nil, false, result.Error
+		}
+		if result.RowsAffected == 1 {
+			existing.LockedUntil = lockedUntil
+			return &existing, true, nil
+		}
+	}
+	return nil, false, ErrIdempotencyKeyInFlight
+}
+
+// Hold keeps the lock on an acquired key while the request runs, however
+// long that takes. The lock is not tied to ctx's cancellation, since a
+// handler keeps running after its client goes away. Call the returned
+// function once the handler has returned and before Complete or Release;
+// calling it again is a no-op.
+func (s *IdempotencyService) Hold(ctx context.Context, record *models.IdempotencyKey) (stop func()) {
+	ctx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(idempotencyLockHeartbeat)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			lockedUntil := lockExpiry(time.Now())
+			result := s.db.WithContext(ctx).Model(&models.IdempotencyKey{}).
+				Where("id = ? AND status = ? AND locked_until = ?", record.ID, models.IdempotencyProcessing, record.LockedUntil).
+				Update("locked_until", lockedUntil)
+			switch {
+			case result.Error != nil:
+				// Retry on the next tick; the lock outlives a few missed ones.
+				if ctx.Err() == nil {
+					slog.WarnContext(ctx, "Failed to extend idempotency key lock", "error", result.Error)
+				}
+			case result.RowsAffected == 0:
+				slog.ErrorContext(ctx, "Idempotency key lock was lost while its request ran", "key_id", record.ID)
+				return
+			default:
+				record.LockedUntil = lockedUntil
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			cancel()
+			<-done
+		})
+	}
+}
+
+// lockExpiry is kept to the database's microsecond precision, so holders
+// can compare locked_until with the value they wrote.
+func lockExpiry(now time.Time) time.Time {
+	return now.Add(idempotencyLockTimeout).Truncate(time.Microsecond)
+}
+
+// Complete stores the response so retries replay it.
+func (s *IdempotencyService) Complete(ctx context.Context, record *models.IdempotencyKey, status int, headers map[string]string, body []byte) error {
+	encodedHeaders, err := json.Marshal(headers)
+	if err != nil {
+		return err
+	}
+	return s.db.WithContext(ctx).Model(record).Updates(map[string]interface{}{
+		"status":           models.IdempotencyCompleted,
+		"response_status":  status,
+		"response_headers": string(encodedHeaders),
+		"response_body":    body,
+	}).Error
+}
+
+// Release frees the key after a failed request so it can be retried.
+func (s *IdempotencyService) Release(ctx context.Context, record *models.IdempotencyKey) error {
+	return s.db.WithContext(ctx).
+		Where("id = ? AND status = ?", record.ID, models.IdempotencyProcessing).
+		Delete(&models.IdempotencyKey{}).Error
+}
+
+// Headers decodes the stored response headers.
+func (s *IdempotencyService) Headers(record *models.IdempotencyKey) map[string]string {
+	headers := map[string]string{}
+	if record.ResponseHeaders != "" {
+		_ = json.Unmarshal([]byte(record.ResponseHeaders), &headers)
+	}
+	return headers
+}
+
+func (s *IdempotencyService) Prune(ctx context.Context) (int64, error) {
+	result := s.db.WithContext(ctx).Where("expires_at <= ?", time.Now()).Delete(&models.IdempotencyKey{})
+	return result.RowsAffected, result.Error
+}
+
+func (s *IdempotencyService) RegisterJobs(w *jobs.Worker) error {
+	jobs.Handle(w, JobPruneIdempotencyKeys, func(ctx context.Context, _ struct{}) error {
+		pruned, err := s.Prune(ctx)
+		if err == nil && pruned > 0 {
+			slog.InfoContext(ctx, "Pruned expired idempotency keys", "count", pruned)
+		}
+		return err
+	})
+	return w.Cron(JobPruneIdempotencyKeys, "@hourly", JobPruneIdempotencyKeys, struct{}{})
+}
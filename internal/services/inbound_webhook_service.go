@@ -0,0 +1,197 @@
+// internal/services/inbound_webhook_service.go
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"time"
+
+	"finbro-backend-go/internal/db"
+	"finbro-backend-go/internal/db/models"
+	"finbro-backend-go/internal/jobs"
+	"finbro-backend-go/internal/webhooks"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	JobProcessInboundWebhook = "webhooks.process_inbound"
+
+	inboundWebhookMaxAttempts = 8
+)
+
+var ErrInvalidWebhookPayload = errors.New("invalid webhook payload")
+
+// InboundEventHandler processes one normalized inbound event.
+type InboundEventHandler func(ctx context.Context, event *models.WebhookEvent) error
+
+// InboundWebhookService stores verified provider webhooks and processes them
+// on the job queue, so the HTTP response never waits for the work.
+type InboundWebhookService struct {
+	db       *db.DB
+	queue    *jobs.Queue
+	registry *webhooks.Registry
+	handlers map[string]InboundEventHandler
+}
+
+func NewInboundWebhookService(db *db.DB, queue *jobs.Queue, registry *webhooks.Registry, bankLinkService *BankLinkService) *InboundWebhookService {
+	s := &InboundWebhookService{
+		db:       db,
+		queue:    queue,
+		registry: registry,
+		handlers: make(map[string]InboundEventHandler),
+	}
+
+	s.handlers[webhooks.EventTransactionsAvailable] = func(ctx context.Context, event *models.WebhookEvent) error {
+		item, err := bankLinkService.GetItemByExternalID(event.ItemID)
+		if err != nil {
+			return err
+		}
+		_, err = bankLinkService.SyncItem(ctx, item)
+		return err
+	}
+	s.handlers[webhooks.EventBalanceUpdated] = func(ctx context.Context, event *models.WebhookEvent) error {
+		item, err := bankLinkService.GetItemByExternalID(event.ItemID)
+		if err != nil {
+			return err
+		}
+		return bankLinkService.RefreshAccounts(ctx, item)
+	}
+	s.handlers[webhooks.EventLoginRequired] = func(ctx context.Context, event *models.WebhookEvent) error {
+		item, err := bankLinkService.GetItemByExternalID(event.ItemID)
+		if err != nil {
+			return err
+		}
+		return bankLinkService.SetItemStatus(item, models.BankItemLoginRequired, "Institution requires the user to log in again")
+	}
+	s.handlers[webhooks.EventLoginRepaired] = func(ctx context.Context, event *models.WebhookEvent) error {
+		item, err := bankLinkService.GetItemByExternalID(event.ItemID)
+		if err != nil {
+			return err
+		}
+		return bankLinkService.SetItemStatus(item, models.BankItemActive, "")
+	}
+
+	return s
+}
+
+// Receive verifies and stores an inbound webhook. It reports duplicate when
+// the provider already delivered an event with the same ID.
+func (s *InboundWebhookService) Receive(ctx context.Context, providerName string, header http.Header, body []byte) (duplicate bool, err error) {
+	provider, err := s.registry.Get(providerName)
+	if err != nil {
+		return false, err
+	}
+	if err := provider.Verifier.Verify(ctx, header, body); err != nil {
+		return false, err
+	}
+
+	parsed, err := provider.Parse(header, body)
+	if err != nil {
+		return false, fmt.Errorf("%w: %v", ErrInvalidWebhookPayload, err)
+	}
+
+	event := &models.WebhookEvent{
+		Provider:      provider.Name,
+		EventID:       parsed.ID,
+		EventType:     parsed.Type,
+		ItemID:        parsed.ItemID,
+		Payload:       string(body),
+		Status:        models.WebhookEventPending,
+		NextAttemptAt: time.Now(),
+	}
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(event)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			duplicate = true
+			return nil
+		}
+
+		_, err := s.queue.Enqueue(tx, JobProcessInboundWebhook,
+			processInboundWebhookArgs{EventID: event.ID},
+			jobs.MaxAttempts(inboundWebhookMaxAttempts))
+		return err
+	})
+	return duplicate, err
+}
+
+// RegisterJobs installs the processor for stored events on w.
+func (s *InboundWebhookService) RegisterJobs(w *jobs.Worker) {
+	jobs.Handle(w, JobProcessInboundWebhook, s.process, jobs.WithBackoff(inboundBackoff))
+}
+
+type processInboundWebhookArgs struct {
+	EventID uint `json:"event_id"`
+}
+
+func (s *InboundWebhookService) process(ctx context.Context, args processInboundWebhookArgs) error {
+	var event models.WebhookEvent
+	if err := s.db.WithContext(ctx).First(&event, args.EventID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return jobs.Permanent(err)
+		}
+		return err
+	}
+	if event.Status == models.WebhookEventProcessed {
+		return nil
+	}
+
+	handlerErr := s.dispatch(ctx, &event)
+
+	now := time.Now()
+	event.Attempts++
+	updates := map[string]interface{}{"attempts": event.Attempts}
+
+	switch {
+	case handlerErr == nil:
+		updates["status"] = models.WebhookEventProcessed
+		updates["processed_at"] = now
+		updates["last_error"] = ""
+	case event.Attempts >= inboundWebhookMaxAttempts:
+		updates["status"] = models.WebhookEventFailed
+		updates["last_error"] = handlerErr.Error()
+		handlerErr = jobs.Permanent(handlerErr)
+	default:
+		updates["status"] = models.WebhookEventPending
+		updates["last_error"] = handlerErr.Error()
+		updates["next_attempt_at"] = now.Add(inboundBackoff(event.Attempts))
+	}
+
+	if err := s.db.Model(&event).Updates(updates).Error; err != nil {
+		slog.Error("Failed to update webhook event", "event_id", event.ID, "error", err)
+	}
+	return handlerErr
+}
+
+func inboundBackoff(attempts int) time.Duration {
+	return time.Duration(math.Pow(2, float64(attempts))) * 10 * time.Second
+}
+
+func (s *InboundWebhookService) dispatch(ctx context.Context, event *models.WebhookEvent) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("handler panic: %v", r)
+		}
+	}()
+
+	handler, ok := s.handlers[event.EventType]
+	if !ok {
+		// Events nobody subscribes to are kept for auditing only.
+		return nil
+	}
+
+	err = handler(ctx, event)
+	if errors.Is(err, ErrBankItemNotFound) {
+		// The item was unlinked; retrying will not help.
+		return nil
+	}
+	return err
+}
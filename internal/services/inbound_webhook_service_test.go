@@ -0,0 +1,90 @@
+// internal/services/inbound_webhook_service_test.go
+package services
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"finbro-backend-go/internal/db/dbtest"
+	"finbro-backend-go/internal/db/models"
+	"finbro-backend-go/internal/jobs"
+	"finbro-backend-go/internal/publicid"
+	"finbro-backend-go/internal/webhooks"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+type staticWebhookKeys struct {
+	key *ecdsa.PublicKey
+}
+
+func (k staticWebhookKeys) GetWebhookVerificationKey(ctx context.Context, keyID string) (*ecdsa.PublicKey, error) {
+	return k.key, nil
+}
+
+func TestReceiveEnqueuesEachPlaidNotification(t *testing.T) {
+	database := dbtest.Open(t)
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	registry := webhooks.NewRegistry()
+	registry.Register(webhooks.NewPlaidProvider(staticWebhookKeys{&key.PublicKey}))
+	svc := NewInboundWebhookService(database, jobs.NewQueue(database), registry, nil)
+
+	itemID := "item-" + publicid.New()
+	body := []byte(fmt.Sprintf(`{"webhook_type":"TRANSACTIONS","webhook_code":"SYNC_UPDATES_AVAILABLE","item_id":%q}`, itemID))
+	sum := sha256.Sum256(body)
+	sign := func(issuedAt time.Time) http.Header {
+		token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+			"iat":                 issuedAt.Unix(),
+			"request_body_sha256": hex.EncodeToString(sum[:]),
+		})
+		token.Header["kid"] = "test-key"
+		signed, err := token.SignedString(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		header := http.Header{}
+		header.Set("Plaid-Verification", signed)
+		return header
+	}
+
+	first := sign(time.Now().Add(-time.Minute))
+	for i, header := range []http.Header{first, sign(time.Now()), first} {
+		duplicate, err := svc.Receive(context.Background(), "plaid", header, body)
+		if err != nil {
+			t.Fatalf("Receive #%d: %v", i+1, err)
+		}
+		if want := i == 2; duplicate != want {
+			t.Errorf("Receive #%d: duplicate = %v, want %v", i+1, duplicate, want)
+		}
+	}
+
+	var events []models.WebhookEvent
+	if err := database.Where("provider = ? AND item_id = ?", "plaid", itemID).Find(&events).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("stored %d events, want 2", len(events))
+	}
+	for _, event := range events {
+		var count int64
+		if err := database.Model(&models.Job{}).
+			Where("type = ? AND payload = ?", JobProcessInboundWebhook, fmt.Sprintf(`{"event_id":%d}`, event.ID)).
+			Count(&count).Error; err != nil {
+			t.Fatal(err)
+		}
+		if count != 1 {
+			t.Errorf("event %d: %d sync jobs enqueued, want 1", event.ID, count)
+		}
+	}
+}
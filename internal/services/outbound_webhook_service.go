@@ -0,0 +1,412 @@
+// internal/services/outbound_webhook_service.go
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"time"
+
+	"finbro-backend-go/internal/db"
+	"finbro-backend-go/internal/db/models"
+	"finbro-backend-go/internal/jobs"
+	"finbro-backend-go/internal/tracing"
+	"finbro-backend-go/internal/webhooks"
+
+	"gorm.io/gorm"
+)
+
+// Outbound event types.
+const (
+	EventAccountCreated     = "account.created"
+	EventAccountUpdated     = "account.updated"
+	EventAccountDeleted     = "account.deleted"
+	EventTransactionCreated = "transaction.created"
+	EventTransactionUpdated = "transaction.updated"
+	EventTransactionDeleted = "transaction.deleted"
+	EventBudgetExceeded     = "budget.exceeded"
+)
+
+var OutboundEventTypes = []string{
+	EventAccountCreated, EventAccountUpdated, EventAccountDeleted,
+	EventTransactionCreated, EventTransactionUpdated, EventTransactionDeleted,
+	EventBudgetExceeded,
+}
+
+const (
+	JobDeliverWebhook = "webhooks.deliver"
+
+	outboundWebhookMaxAttempts = 10
+	outboundWebhookTimeout     = 10 * time.Second
+	outboundWebhookBaseBackoff = 30 * time.Second
+	outboundWebhookMaxBackoff  = 6 * time.Hour
+)
+
+var (
+	ErrSubscriptionNotFound = errors.New("webhook subscription not found")
+	ErrDeliveryNotFound     = errors.New("webhook delivery not found")
+	ErrInvalidSubscription  = errors.New("invalid webhook subscription")
+)
+
+// OutboundEvent is the JSON envelope POSTed to subscribers.
+type OutboundEvent struct {
+	ID        string      `json:"id"`
+	Type      string      `json:"type"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
+
+// OutboundWebhookService manages customer webhook subscriptions and delivers
+// events to them with retries. Deliveries are written in the caller's
+// database transaction (an outbox), so an event exists iff the mutation
+// that caused it was committed.
+type OutboundWebhookService struct {
+	db         *db.DB
+	queue      *jobs.Queue
+	httpClient *http.Client
+}
+
+// NewOutboundWebhookService delivers over a client that only connects to
+// public addresses and does not follow redirects; a 3xx counts as a failed
+// attempt.
+func NewOutboundWebhookService(db *db.DB, queue *jobs.Queue) *OutboundWebhookService {
+	return &OutboundWebhookService{
+		db:    db,
+		queue: queue,
+		httpClient: &http.Client{
+			Timeout:   outboundWebhookTimeout,
+			Transport: tracing.Transport(webhooks.NewEgressTransport(outboundWebhookTimeout)),
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+}
+
+// Publish records deliveries of an event for every active subscription of
+// the user that listens to it. Pass the open transaction of the mutation.
+func (s *OutboundWebhookService) Publish(tx *gorm.DB, userID uint, eventType string, data interface{}) error {
+	var subscriptions []models.WebhookSubscription
+	if err := tx.Where("user_id = ? AND is_active = ?", userID, true).
+		Find(&subscriptions).Error; err != nil {
+		return err
+	}
+
+	var deliveries []models.WebhookDelivery
+	event := OutboundEvent{
+		ID:        "evt_" + randomHex(12),
+		Type:      eventType,
+		CreatedAt: time.Now().UTC(),
+		Data:      data,
+	}
+	var payload []byte
+	for _, sub := range subscriptions {
+		if !subscribesTo(sub, eventType) {
+			continue
+		}
+		if payload == nil {
+			var err error
+			if payload, err = json.Marshal(event); err != nil {
+				return fmt.Errorf("failed to encode event: %w", err)
+			}
+		}
+		deliveries = append(deliveries, models.WebhookDelivery{
+			SubscriptionID: sub.ID,
+			UserID:         userID,
+			EventID:        event.ID,
+			EventType:      eventType,
+			Payload:        string(payload),
+			Status:         models.WebhookDeliveryPending,
+			NextAttemptAt:  time.Now(),
+		})
+	}
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	if err := tx.Create(&deliveries).Error; err != nil {
+		return err
+	}
+	for _, delivery := range deliveries {
+		if err := s.enqueue(tx, delivery.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *OutboundWebhookService) enqueue(tx *gorm.DB, deliveryID uint) error {
+	_, err := s.queue.Enqueue(tx, JobDeliverWebhook, deliverWebhookArgs{DeliveryID: deliveryID},
+		jobs.MaxAttempts(outboundWebhookMaxAttempts))
+	return err
+}
+
+func subscribesTo(sub models.WebhookSubscription, eventType string) bool {
+	for _, t := range sub.EventTypes {
+		if t == "*" || t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *OutboundWebhookService) GetSubscriptions(userID uint) ([]models.WebhookSubscription, error) {
+	var subscriptions []models.WebhookSubscription
+	err := s.db.Where("user_id = ?", userID).Order("id").Find(&subscriptions).Error
+	return subscriptions, err
+}
+
+func (s *OutboundWebhookService) GetSubscriptionByID(subscriptionID, userID uint) (*models.WebhookSubscription, error) {
+	var sub models.WebhookSubscription
+	err := s.db.Where("id = ? AND user_id = ?", subscriptionID, userID).First(&sub).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrSubscriptionNotFound
+	}
+	return &sub, err
+}
+
+// CreateSubscription generates the signing secret. It is only ever returned
+// from this call.
+func (s *OutboundWebhookService) CreateSubscription(sub *models.WebhookSubscription) error {
+	if err := validateSubscription(sub); err != nil {
+		return err
+	}
+	sub.Secret = "whsec_" + randomHex(24)
+	return s.db.Create(sub).Error
+}
+
+func (s *OutboundWebhookService) UpdateSubscription(sub *models.WebhookSubscription) error {
+	if err := validateSubscription(sub); err != nil {
+		return err
+	}
+	return s.db.Save(sub).Error
+}
+
+func (s *OutboundWebhookService) DeleteSubscription(subscriptionID, userID uint) error {
+	sub, err := s.GetSubscriptionByID(subscriptionID, userID)
+	if err != nil {
+		return err
+	}
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("subscription_id = ?", sub.ID).Delete(&models.WebhookDelivery{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(sub).Error
+	})
+}
+
+func (s *OutboundWebhookService) GetDeliveries(subscriptionID, userID uint, limit int) ([]models.WebhookDelivery, error) {
+	if _, err := s.GetSubscriptionByID(subscriptionID, userID); err != nil {
+		return nil, err
+	}
+	var deliveries []models.WebhookDelivery
+	err := s.db.Where("subscription_id = ?", subscriptionID).
+		Order("id DESC").
+		Limit(ClampPageSize(limit)).
+		Find(&deliveries).Error
+	return deliveries, err
+}
+
+// Redeliver queues a fresh delivery of a previously sent event. The old
+// delivery stays in the log untouched.
+func (s *OutboundWebhookService) Redeliver(subscriptionID, deliveryID, userID uint) (*models.WebhookDelivery, error) {
+	var original models.WebhookDelivery
+	err := s.db.Where("id = ? AND subscription_id = ? AND user_id = ?", deliveryID, subscriptionID, userID).
+		First(&original).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrDeliveryNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	delivery := &models.WebhookDelivery{
+		SubscriptionID: original.SubscriptionID,
+		UserID:         original.UserID,
+		EventID:        original.EventID,
+		EventType:      original.EventType,
+		Payload:        original.Payload,
+		Status:         models.WebhookDeliveryPending,
+		NextAttemptAt:  time.Now(),
+	}
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(delivery).Error; err != nil {
+			return err
+		}
+		return s.enqueue(tx, delivery.ID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return delivery, nil
+}
+
+func validateSubscription(sub *models.WebhookSubscription) error {
+	if err := checkEndpointURL(sub.URL); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidSubscription, err)
+	}
+	if len(sub.EventTypes) == 0 {
+		return fmt.Errorf("%w: at least one event type is required", ErrInvalidSubscription)
+	}
+
+	known := map[string]bool{"*": true}
+	for _, t := range OutboundEventTypes {
+		known[t] = true
+	}
+	for _, t := range sub.EventTypes {
+		if !known[t] {
+			return fmt.Errorf("%w: unknown event type %q", ErrInvalidSubscription, t)
+		}
+	}
+	return nil
+}
+
+// checkEndpointURL requires an absolute https URL and turns away hosts that
+// are plainly internal. Hostnames are checked again, after resolution, each
+// time a delivery connects.
+func checkEndpointURL(rawURL string) error {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Scheme != "https" || parsed.Hostname() == "" {
+		return errors.New("url must be an absolute https URL")
+	}
+	host := strings.ToLower(strings.TrimSuffix(parsed.Hostname(), "."))
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return errors.New("url must not point at a local address")
+	}
+	if addr, err := netip.ParseAddr(host); err == nil && !webhooks.IsPublicAddr(addr) {
+		return errors.New("url must not point at a private or local address")
+	}
+	return nil
+}
+
+// RegisterJobs installs the delivery handler on w.
+func (s *OutboundWebhookService) RegisterJobs(w *jobs.Worker) {
+	jobs.Handle(w, JobDeliverWebhook, s.deliverJob,
+		jobs.WithTimeout(2*outboundWebhookTimeout),
+		jobs.WithBackoff(outboundBackoff))
+}
+
+type deliverWebhookArgs struct {
+	DeliveryID uint `json:"delivery_id"`
+}
+
+// deliverJob makes one delivery attempt. The delivery row keeps its own
+// attempt count so the log reads the same however the job was retried.
+func (s *OutboundWebhookService) deliverJob(ctx context.Context, args deliverWebhookArgs) error {
+	var delivery models.WebhookDelivery
+	if err := s.db.WithContext(ctx).First(&delivery, args.DeliveryID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// The subscription was deleted along with its deliveries.
+			return nil
+		}
+		return err
+	}
+	if delivery.Status != models.WebhookDeliveryPending {
+		return nil
+	}
+
+	err := s.deliver(ctx, &delivery)
+	if err != nil && delivery.Status == models.WebhookDeliveryFailed {
+		return jobs.Permanent(err)
+	}
+	return err
+}
+
+func (s *OutboundWebhookService) deliver(ctx context.Context, delivery *models.WebhookDelivery) error {
+	var sub models.WebhookSubscription
+	if err := s.db.First(&sub, delivery.SubscriptionID).Error; err != nil {
+		return s.recordAttempt(delivery, 0, 0, fmt.Errorf("subscription unavailable: %w", err))
+	}
+	// Subscriptions saved before https was required are not delivered to.
+	if err := checkEndpointURL(sub.URL); err != nil {
+		return s.recordAttempt(delivery, 0, 0, err)
+	}
+
+	body := []byte(delivery.Payload)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.URL, bytes.NewReader(body))
+	if err != nil {
+		return s.recordAttempt(delivery, 0, 0, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Finbro-Webhooks/1.0")
+	req.Header.Set("X-Finbro-Event", delivery.EventType)
+	req.Header.Set("X-Finbro-Event-ID", delivery.EventID)
+	req.Header.Set("X-Finbro-Delivery", fmt.Sprint(delivery.ID))
+	req.Header.Set(webhooks.SignatureHeader, webhooks.Sign(sub.Secret, time.Now().Unix(), body))
+
+	started := time.Now()
+	resp, err := s.httpClient.Do(req)
+	duration := time.Since(started)
+	if err != nil {
+		return s.recordAttempt(delivery, 0, duration, err)
+	}
+	defer resp.Body.Close()
+
+	// Only the status is recorded: the delivery log is readable by the
+	// subscriber, so a stored body would echo back whatever the URL reached.
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		err = fmt.Errorf("endpoint responded with status %d", resp.StatusCode)
+	}
+	return s.recordAttempt(delivery, resp.StatusCode, duration, err)
+}
+
+// recordAttempt logs the outcome on the delivery and passes deliveryErr
+// through for the job queue.
+func (s *OutboundWebhookService) recordAttempt(delivery *models.WebhookDelivery, status int, duration time.Duration, deliveryErr error) error {
+	delivery.Attempts++
+	updates := map[string]interface{}{
+		"attempts":        delivery.Attempts,
+		"response_status": status,
+		"duration_ms":     duration.Milliseconds(),
+	}
+
+	now := time.Now()
+	switch {
+	case deliveryErr == nil:
+		updates["status"] = models.WebhookDeliverySucceeded
+		updates["delivered_at"] = now
+		updates["last_error"] = ""
+	case delivery.Attempts >= outboundWebhookMaxAttempts:
+		delivery.Status = models.WebhookDeliveryFailed
+		updates["status"] = models.WebhookDeliveryFailed
+		updates["last_error"] = deliveryErr.Error()
+	default:
+		updates["last_error"] = deliveryErr.Error()
+		updates["next_attempt_at"] = now.Add(outboundBackoff(delivery.Attempts))
+	}
+
+	if err := s.db.Model(delivery).Updates(updates).Error; err != nil {
+		slog.Error("Failed to record webhook delivery", "delivery_id", delivery.ID, "error", err)
+	}
+	return deliveryErr
+}
+
+// outboundBackoff doubles the wait after every failed attempt: 30s, 1m,
+// 2m, ... capped at six hours.
+func outboundBackoff(attempts int) time.Duration {
+	backoff := outboundWebhookBaseBackoff
+	for i := 1; i < attempts && backoff < outboundWebhookMaxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > outboundWebhookMaxBackoff {
+		backoff = outboundWebhookMaxBackoff
+	}
+	return backoff
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,26 @@
+// internal/services/outbound_webhook_service_test.go
+package services
+
+import "testing"
+
+func TestCheckEndpointURL(t *testing.T) {
+	tests := map[string]bool{
+		"https://hooks.example.com/finbro":         true,
+		"https://hooks.example.com:8443/x?y=1":     true,
+		"http://hooks.example.com/finbro":          false,
+		"ftp://hooks.example.com/":                 false,
+		"/relative":                                false,
+		"https://localhost/hook":                   false,
+		"https://api.localhost./hook":              false,
+		"https://127.0.0.1/hook":                   false,
+		"https://[::1]/hook":                       false,
+		"https://10.0.0.8/hook":                    false,
+		"https://169.254.169.254/latest/meta-data": false,
+		"https://93.184.216.34/hook":               true,
+	}
+	for input, ok := range tests {
+		if err := checkEndpointURL(input); (err == nil) != ok {
+			t.Errorf("checkEndpointURL(%q) = %v, want ok=%v", input, err, ok)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+// internal/services/pagination.go
+package services
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+)
+
+const (
+	DefaultPageSize = 50
+	MaxPageSize     = 100
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// pageCursor is the decoded form of the opaque keyset cursor handed to
+// clients. Value holds the sort column of the boundary row and ID breaks ties.
+type pageCursor struct {
+	Sort     string `json:"s"`
+	Value    string `json:"v"`
+	ID       uint   `json:"id"`
+	Backward bool   `json:"b,omitempty"`
+}
+
+func encodeCursor(cur pageCursor) string {
+	data, _ := json.Marshal(cur)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(token string) (*pageCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var cur pageCursor
+	if err := json.Unmarshal(data, &cur); err != nil || cur.ID == 0 {
+		return nil, ErrInvalidCursor
+	}
+	return &cur, nil
+}
+
+// ClampPageSize applies the default page size and the upper bound.
+func ClampPageSize(limit int) int {
+	if limit <= 0 {
+		return DefaultPageSize
+	}
+	if limit > MaxPageSize {
+		return MaxPageSize
+	}
+	return limit
+}
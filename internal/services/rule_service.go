@@ -0,0 +1,448 @@
+// internal/services/rule_service.go
+package services
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"finbro-backend-go/internal/db"
+	"finbro-backend-go/internal/db/models"
+	"finbro-backend-go/internal/publicid"
+
+	"gorm.io/gorm"
+)
+
+const (
+	maxRuleRegexLength = 256
+	maxDryRunMatches   = 100
+)
+
+var (
+	ErrRuleNotFound = errors.New("rule not found")
+	ErrInvalidRule  = errors.New("invalid rule")
+)
+
+type RuleService struct {
+	db              *db.DB
+	categoryService *CategoryService
+	categorizer     *CategorizerService
+	budgets         *BudgetService
+	webhooks        *OutboundWebhookService
+}
+
+func NewRuleService(
+	db *db.DB,
+	categoryService *CategoryService,
+	categorizer *CategorizerService,
+	budgets *BudgetService,
+	webhooks *OutboundWebhookService,
+) *RuleService {
+	return &RuleService{
+		db:              db,
+		categoryService: categoryService,
+		categorizer:     categorizer,
+		budgets:         budgets,
+		webhooks:        webhooks,
+	}
+}
+
+// RuleChange describes what a rule would do to a single transaction.
+type RuleChange struct {
+	TransactionID  string   `json:"transaction_id"`
+	Description    string   `json:"description"`
+	NewDescription string   `json:"new_description,omitempty"`
+	Category       string   `json:"category"`
+	NewCategoryID  *uint    `json:"new_category_id,omitempty"`
+	NewCategory    string   `json:"new_category,omitempty"`
+	AddTags        []string `json:"add_tags,omitempty"`
+}
+
+type DryRunResult struct {
+	MatchCount int          `json:"match_count"`
+	Changes    []RuleChange `json:"changes"`
+}
+
+// compiledRule is a rule with its regex compiled and its category resolved
+// so it can be evaluated against many transactions.
+type compiledRule struct {
+	rule     models.Rule
+	regex    *regexp.Regexp
+	category *models.Category
+	account  *models.Account
+}
+
+// RuleSet is a user's active rules, compiled once so that a request or sync
+// page creating many transactions doesn't reload them for each one.
+type RuleSet struct {
+	rules []*compiledRule
+}
+
+func (s *RuleService) GetRules(userID uint) ([]models.Rule, error) {
+	var rules []models.Rule
+	err := s.db.Where("user_id = ?", userID).
+		Order("priority ASC, id ASC").
+		Find(&rules).Error
+	return rules, err
+}
+
+func (s *RuleService) GetRuleByID(ruleID, userID uint) (*models.Rule, error) {
+	var rule models.Rule
+	err := s.db.Where("id = ? AND user_id = ?", ruleID, userID).First(&rule).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrRuleNotFound
+	}
+	return &rule, err
+}
+
+func (s *RuleService) CreateRule(rule *models.Rule) error {
+	if err := s.validate(rule); err != nil {
+		return err
+	}
+	return s.db.Create(rule).Error
+}
+
+func (s *RuleService) UpdateRule(rule *models.Rule) error {
+	if err := s.validate(rule); err != nil {
+		return err
+	}
+	return s.db.Save(rule).Error
+}
+
+// validate also stores the account condition as the account's public ID,
+// whichever reference the client sent.
+func (s *RuleService) validate(rule *models.Rule) error {
+	compiled, err := s.compile(s.db.DB, *rule)
+	if err != nil {
+		return err
+	}
+
+	if compiled.account != nil {
+		ref := publicid.Ref(compiled.account.PublicID)
+		rule.Conditions.AccountID = &ref
+	}
+	return nil
+}
+
+func (s *RuleService) DeleteRule(ruleID, userID uint) error {
+	result := s.db.Where("id = ? AND user_id = ?", ruleID, userID).Delete(&models.Rule{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrRuleNotFound
+	}
+	return nil
+}
+
+// LoadRules compiles the user's active rules on tx.
+func (s *RuleService) LoadRules(tx *gorm.DB, userID uint) (*RuleSet, error) {
+	var rules []models.Rule
+	if err := tx.Where("user_id = ? AND is_active = ?", userID, true).
+		Order("priority ASC, id ASC").
+		Find(&rules).Error; err != nil {
+		return nil, err
+	}
+
+	set := &RuleSet{}
+	for _, rule := range rules {
+		compiled, err := s.compile(tx, rule)
+		if errors.Is(err, ErrInvalidRule) {
+			// A rule that no longer compiles (e.g. its category was deleted)
+			// is skipped rather than blocking transaction creation.
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		set.rules = append(set.rules, compiled)
+	}
+	return set, nil
+}
+
+// Apply runs the rules against a transaction of the same user that is
+// about to be created or imported. Later rules see the edits of earlier ones.
+func (r *RuleSet) Apply(tx *gorm.DB, transaction *models.Transaction) error {
+	var tagNames []string
+	for _, compiled := range r.rules {
+		if !compiled.matches(transaction) {
+			continue
+		}
+
+		change := compiled.change(transaction)
+		applyChange(transaction, change)
+		tagNames = append(tagNames, change.AddTags...)
+
+		if compiled.rule.StopProcessing {
+			break
+		}
+	}
+
+	if len(tagNames) == 0 {
+		return nil
+	}
+	tags, err := EnsureTags(tx, transaction.UserID, tagNames)
+	if err != nil {
+		return err
+	}
+	transaction.Tags = MergeTags(transaction.Tags, tags)
+	return nil
+}
+
+// DryRun reports which existing transactions a rule would change without
+// modifying anything.
+func (s *RuleService) DryRun(ruleID, userID uint) (*DryRunResult, error) {
+	rule, err := s.GetRuleByID(ruleID, userID)
+	if err != nil {
+		return nil, err
+	}
+	compiled, err := s.compile(s.db.DB, *rule)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &DryRunResult{Changes: []RuleChange{}}
+	err = s.eachMatch(s.db.DB, compiled, func(transaction *models.Transaction) error {
+		result.MatchCount++
+		if len(result.Changes) < maxDryRunMatches {
+			result.Changes = append(result.Changes, compiled.change(transaction))
+		}
+		return nil
+	})
+	return result, err
+}
+
+// ApplyRetroactively applies a rule to every matching transaction in the
+// user's history and returns how many were changed. It runs in one database
+// transaction, so either every match is changed or none is. Each row is
+// written with its version checked, moves its budget spending and
+// publishes transaction.updated, like an edit through the API. Rows the
+// rule's actions leave as they are are not written. A row edited
+// concurrently fails the whole run with db.ErrVersionConflict.
+func (s *RuleService) ApplyRetroactively(ruleID, userID uint) (int, error) {
+	rule, err := s.GetRuleByID(ruleID, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	updated := 0
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		compiled, err := s.compile(tx, *rule)
+		if err != nil {
+			return err
+		}
+		tags, err := EnsureTags(tx, userID, rule.Actions.AddTags)
+		if err != nil {
+			return err
+		}
+
+		return s.eachMatch(tx, compiled, func(transaction *models.Transaction) error {
+			previous := *transaction
+			change := compiled.change(transaction)
+			applyChange(transaction, change)
+
+			var columns []string
+			if transaction.Description != previous.Description {
+				columns = append(columns, "description", "merchant")
+			}
+			if !sameCategory(transaction, &previous) {
+				columns = append(columns, "category_id", "category")
+			}
+			if len(tags) > 0 {
+				// Tags live in a join table; touching the row moves its version.
+				added, err := addTransactionTags(tx, []uint{transaction.ID}, tags)
+				if err != nil {
+					return err
+				}
+				if added > 0 {
+					columns = append(columns, "updated_at")
+				}
+			}
+			if len(columns) == 0 {
+				return nil
+			}
+
+			if err := db.UpdateVersioned(tx, transaction, &transaction.Version, columns...); err != nil {
+				return err
+			}
+			if err := s.budgets.UpdateSpending(tx, &previous, transaction); err != nil {
+				return err
+			}
+			if err := s.webhooks.Publish(tx, userID, EventTransactionUpdated, transaction); err != nil {
+				return err
+			}
+			updated++
+			return nil
+		})
+	})
+	if err != nil {
+		return 0, err
+	}
+	if updated > 0 && rule.Actions.SetCategoryID != nil {
+		s.categorizer.Invalidate(userID)
+	}
+	return updated, nil
+}
+
+// eachMatch walks the user's transactions that match the rule. Simple
+// conditions are pushed down to SQL; the regex is evaluated in Go.
+func (s *RuleService) eachMatch(tx *gorm.DB, compiled *compiledRule, fn func(transaction *models.Transaction) error) error {
+	cond := compiled.rule.Conditions
+	query := tx.Where("user_id = ?", compiled.rule.UserID)
+
+	if cond.DescriptionContains != "" {
+		query = query.Where("description ILIKE ?", "%"+escapeLike(cond.DescriptionContains)+"%")
+	}
+	if compiled.account != nil {
+		query = query.Where("account_id = ?", compiled.account.ID)
+	}
+	if cond.Type != "" {
+		query = query.Where("type = ?", cond.Type)
+	}
+
+	var batch []models.Transaction
+	return query.FindInBatches(&batch, 500, func(_ *gorm.DB, _ int) error {
+		for i := range batch {
+			if !compiled.matches(&batch[i]) {
+				continue
+			}
+			if err := fn(&batch[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}).Error
+}
+
+// compile validates a rule and resolves its references on tx.
+func (s *RuleService) compile(tx *gorm.DB, rule models.Rule) (*compiledRule, error) {
+	cond := rule.Conditions
+	actions := rule.Actions
+
+	if strings.TrimSpace(rule.Name) == "" {
+		return nil, fmt.Errorf("%w: name is required", ErrInvalidRule)
+	}
+	if cond.DescriptionContains == "" && cond.DescriptionRegex == "" && cond.MinAmount == nil &&
+		cond.MaxAmount == nil && cond.AccountID == nil && cond.Type == "" {
+		return nil, fmt.Errorf("%w: at least one condition is required", ErrInvalidRule)
+	}
+	if actions.SetCategoryID == nil && len(actions.AddTags) == 0 && actions.RenameDescription == "" {
+		return nil, fmt.Errorf("%w: at least one action is required", ErrInvalidRule)
+	}
+	if cond.Type != "" && cond.Type != "debit" && cond.Type != "credit" {
+		return nil, fmt.Errorf("%w: type must be debit or credit", ErrInvalidRule)
+	}
+	if cond.MinAmount != nil && cond.MaxAmount != nil && *cond.MinAmount > *cond.MaxAmount {
+		return nil, fmt.Errorf("%w: min_amount is greater than max_amount", ErrInvalidRule)
+	}
+
+	compiled := &compiledRule{rule: rule}
+	if cond.DescriptionRegex != "" {
+		if len(cond.DescriptionRegex) > maxRuleRegexLength {
+			return nil, fmt.Errorf("%w: description_regex is too long", ErrInvalidRule)
+		}
+		regex, err := regexp.Compile(cond.DescriptionRegex)
+		if err != nil {
+			return nil, fmt.Errorf("%w: description_regex: %v", ErrInvalidRule, err)
+		}
+		compiled.regex = regex
+	}
+	if cond.AccountID != nil {
+		account, err := ruleAccount(tx, *cond.AccountID, rule.UserID)
+		if err != nil {
+			return nil, err
+		}
+		compiled.account = account
+	}
+	if actions.SetCategoryID != nil {
+		category, err := s.categoryService.GetCategoryByID(tx, *actions.SetCategoryID, rule.UserID)
+		if errors.Is(err, ErrCategoryNotFound) {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidRule, err)
+		}
+		if err != nil {
+			return nil, err
+		}
+		compiled.category = category
+	}
+
+	return compiled, nil
+}
+
+func ruleAccount(tx *gorm.DB, ref publicid.Ref, userID uint) (*models.Account, error) {
+	scope, err := publicid.Scope(ref.String())
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid account_id", ErrInvalidRule)
+	}
+
+	var account models.Account
+	err = tx.Scopes(scope).Where("user_id = ?", userID).First(&account).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("%w: account not found", ErrInvalidRule)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+func (r *compiledRule) matches(t *models.Transaction) bool {
+	cond := r.rule.Conditions
+
+	if cond.DescriptionContains != "" &&
+		!strings.Contains(strings.ToLower(t.Description), strings.ToLower(cond.DescriptionContains)) {
+		return false
+	}
+	if r.regex != nil && !r.regex.MatchString(t.Description) {
+		return false
+	}
+	if cond.MinAmount != nil && t.Amount < *cond.MinAmount {
+		return false
+	}
+	if cond.MaxAmount != nil && t.Amount > *cond.MaxAmount {
+		return false
+	}
+	if r.account != nil && t.AccountID != r.account.ID {
+		return false
+	}
+	if cond.Type != "" && !strings.EqualFold(t.Type, cond.Type) {
+		return false
+	}
+	return true
+}
+
+func (r *compiledRule) change(t *models.Transaction) RuleChange {
+	change := RuleChange{
+		TransactionID:  t.PublicID,
+		Description:    t.Description,
+		NewDescription: r.rule.Actions.RenameDescription,
+		Category:       t.Category,
+		AddTags:        r.rule.Actions.AddTags,
+	}
+	if r.category != nil {
+		change.NewCategoryID = &r.category.ID
+		change.NewCategory = r.category.Name
+	}
+	return change
+}
+
+func applyChange(t *models.Transaction, change RuleChange) {
+	if change.NewDescription != "" {
+		t.Description = change.NewDescription
+	}
+	if change.NewCategoryID != nil {
+		t.CategoryID = change.NewCategoryID
+		t.Category = change.NewCategory
+	}
+}
+
+func sameCategory(a, b *models.Transaction) bool {
+	if a.CategoryID == nil || b.CategoryID == nil {
+		return a.CategoryID == b.CategoryID && a.Category == b.Category
+	}
+	return *a.CategoryID == *b.CategoryID && a.Category == b.Category
+}
+
+func escapeLike(input string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(input)
+}
@@ -0,0 +1,82 @@
+// internal/services/rule_service_test.go
+package services
+
+import (
+	"testing"
+
+	"finbro-backend-go/internal/db"
+	"finbro-backend-go/internal/db/dbtest"
+	"finbro-backend-go/internal/db/models"
+	"finbro-backend-go/internal/jobs"
+)
+
+func TestApplyRetroactivelySkipsUnchangedRows(t *testing.T) {
+	database := dbtest.Open(t)
+	categories := NewCategoryService(database)
+	categorizer := NewCategorizerService(database, 0.9)
+	webhooks := NewOutboundWebhookService(database, jobs.NewQueue(database))
+	svc := NewRuleService(database, categories, categorizer, NewBudgetService(database, webhooks), webhooks)
+
+	user := dbtest.CreateUser(t, database)
+	account := dbtest.CreateAccount(t, database, user.ID)
+	coffee, err := categories.ResolveByName(database.DB, user.ID, "Coffee Shops")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	done := dbtest.CreateTransaction(t, database, account, "Blue Bottle Coffee", 4.75)
+	done.CategoryID = &coffee.ID
+	done.Category = coffee.Name
+	if err := database.Save(done).Error; err != nil {
+		t.Fatal(err)
+	}
+	pending := dbtest.CreateTransaction(t, database, account, "Corner coffee cart", 3)
+	done = reloadTransaction(t, database, done.ID)
+
+	rule := &models.Rule{
+		UserID:     user.ID,
+		Name:       "Coffee",
+		IsActive:   true,
+		Conditions: models.RuleConditions{DescriptionContains: "coffee"},
+		Actions:    models.RuleActions{SetCategoryID: &coffee.ID},
+	}
+	if err := svc.CreateRule(rule); err != nil {
+		t.Fatalf("CreateRule: %v", err)
+	}
+
+	updated, err := svc.ApplyRetroactively(rule.ID, user.ID)
+	if err != nil {
+		t.Fatalf("ApplyRetroactively: %v", err)
+	}
+	if updated != 1 {
+		t.Errorf("first run updated %d transactions, want only the uncategorized one", updated)
+	}
+	if got := reloadTransaction(t, database, done.ID); got.Version != done.Version {
+		t.Errorf("already categorized row was rewritten: version %d -> %d", done.Version, got.Version)
+	}
+	categorized := reloadTransaction(t, database, pending.ID)
+	if categorized.CategoryID == nil || *categorized.CategoryID != coffee.ID {
+		t.Fatalf("uncategorized row: got category %v", categorized.CategoryID)
+	}
+
+	// Adding a tag counts as a change only the first time.
+	rule.Actions.AddTags = []string{"caffeine"}
+	if err := svc.UpdateRule(rule); err != nil {
+		t.Fatalf("UpdateRule: %v", err)
+	}
+	if updated, err := svc.ApplyRetroactively(rule.ID, user.ID); err != nil || updated != 2 {
+		t.Fatalf("tagging run: updated %d, err %v; want 2", updated, err)
+	}
+	if updated, err := svc.ApplyRetroactively(rule.ID, user.ID); err != nil || updated != 0 {
+		t.Errorf("repeat run: updated %d, err %v; want 0", updated, err)
+	}
+}
+
+func reloadTransaction(t *testing.T, database *db.DB, id uint) *models.Transaction {
+	t.Helper()
+	var transaction models.Transaction
+	if err := database.First(&transaction, id).Error; err != nil {
+		t.Fatalf("transaction #%d: %v", id, err)
+	}
+	return &transaction
+}
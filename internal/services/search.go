@@ -0,0 +1,108 @@
+// internal/services/search.go
+package services
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+
+	"finbro-backend-go/internal/db/models"
+	"finbro-backend-go/internal/utils"
+)
+
+const (
+	searchConfig    = "english"
+	headlineOptions = "StartSel=<mark>, StopSel=</mark>, MaxWords=20, MinWords=5, MaxFragments=1"
+)
+
+var ErrEmptySearch = errors.New("search query must contain at least one word")
+
+type SearchOptions struct {
+	AccountID uint
+	Limit     int
+}
+
+type TransactionSearchResult struct {
+	models.Transaction
+	Rank      float64 `json:"rank"`
+	Highlight string  `json:"highlight"`
+}
+
+// buildSearchQuery turns free text into a to_tsquery expression where every
+// term must match as a prefix, e.g. "amzn marc" becomes "amazon:* & marc:*".
+func buildSearchQuery(input string) string {
+	terms := strings.FieldsFunc(strings.ToLower(input), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	parts := make([]string, 0, len(terms))
+	for _, term := range terms {
+		parts = append(parts, utils.NormalizeSearchTerm(term)+":*")
+	}
+	return strings.Join(parts, " & ")
+}
+
+// SearchTransactions runs a ranked full-text search over the user's
+// transactions and returns the best matches with a highlighted snippet.
+func (s *TransactionService) SearchTransactions(userID uint, input string, opts SearchOptions) ([]TransactionSearchResult, error) {
+	tsQuery := buildSearchQuery(input)
+	if tsQuery == "" {
+		return nil, ErrEmptySearch
+	}
+
+	query := s.db.Table("transactions").
+		Select(
+			"transactions.id, ts_rank(transactions.search_vector, query) AS rank, "+
+				"ts_headline(?, coalesce(transactions.description, ''), query, ?) AS highlight",
+			searchConfig, headlineOptions,
+		).
+		Joins("CROSS JOIN to_tsquery(?::regconfig, ?) AS query", searchConfig, tsQuery).
+		Where("transactions.user_id = ?", userID).
+		Where("transactions.search_vector @@ query")
+
+	if opts.AccountID > 0 {
+		query = query.Where("transactions.account_id = ?", opts.AccountID)
+	}
+
+	var hits []struct {
+		ID        uint
+		Rank      float64
+		Highlight string
+	}
+	err := query.Order("rank DESC, transactions.transaction_date DESC").
+		Limit(ClampPageSize(opts.Limit)).
+		Scan(&hits).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(hits) == 0 {
+		return []TransactionSearchResult{}, nil
+	}
+
+	ids := make([]uint, len(hits))
+	for i, hit := range hits {
+		ids[i] = hit.ID
+	}
+
+	var transactions []models.Transaction
+	if err := s.db.Preload("Account").Preload("Tags").Where("id IN ?", ids).Find(&transactions).Error; err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]models.Transaction, len(transactions))
+	for _, t := range transactions {
+		byID[t.ID] = t
+	}
+
+	results := make([]TransactionSearchResult, 0, len(hits))
+	for _, hit := range hits {
+		if t, ok := byID[hit.ID]; ok {
+			results = append(results, TransactionSearchResult{
+				Transaction: t,
+				Rank:        hit.Rank,
+				Highlight:   hit.Highlight,
+			})
+		}
+	}
+
+	return results, nil
+}
@@ -0,0 +1,225 @@
+// internal/services/tag_service.go
+package services
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"finbro-backend-go/internal/db"
+	"finbro-backend-go/internal/db/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrTagNotFound  = errors.New("tag not found")
+	ErrDuplicateTag = errors.New("a tag with this name already exists")
+	ErrInvalidTag   = errors.New("tag name must not be empty")
+)
+
+type TagService struct {
+	db *db.DB
+}
+
+func NewTagService(db *db.DB) *TagService {
+	return &TagService{db: db}
+}
+
+// TagReport summarizes the transactions carrying one tag.
+type TagReport struct {
+	TagID        uint    `json:"tag_id"`
+	Tag          string  `json:"tag"`
+	Transactions int64   `json:"transactions"`
+	Spending     float64 `json:"spending"`
+	Income       float64 `json:"income"`
+}
+
+// NormalizeTagName is how tag names are stored: trimmed and lower case, so
+// "Vacation-2026" and "vacation-2026 " are the same tag.
+func NormalizeTagName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+func (s *TagService) GetTags(userID uint) ([]models.Tag, error) {
+	var tags []models.Tag
+	err := s.db.Where("user_id = ?", userID).Order("name ASC").Find(&tags).Error
+	return tags, err
+}
+
+func (s *TagService) GetTagByID(tagID, userID uint) (*models.Tag, error) {
+	var tag models.Tag
+	err := s.db.Where("id = ? AND user_id = ?", tagID, userID).First(&tag).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrTagNotFound
+	}
+	return &tag, err
+}
+
+func (s *TagService) CreateTag(tag *models.Tag) error {
+	if err := s.validate(tag); err != nil {
+		return err
+	}
+	return s.db.Create(tag).Error
+}
+
+func (s *TagService) UpdateTag(tag *models.Tag) error {
+	if err := s.validate(tag); err != nil {
+		return err
+	}
+	return s.db.Save(tag).Error
+}
+
+func (s *TagService) validate(tag *models.Tag) error {
+	tag.Name = NormalizeTagName(tag.Name)
+	if tag.Name == "" {
+		return ErrInvalidTag
+	}
+
+	var count int64
+	if err := s.db.Model(&models.Tag{}).
+		Where("user_id = ? AND name = ? AND id <> ?", tag.UserID, tag.Name, tag.ID).
+		Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrDuplicateTag
+	}
+	return nil
+}
+
+// DeleteTag removes a tag and takes it off every transaction carrying it.
+func (s *TagService) DeleteTag(tagID, userID uint) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(
+			"DELETE FROM transaction_tags WHERE tag_id IN (SELECT id FROM tags WHERE id = ? AND user_id = ?)",
+			tagID, userID,
+		).Error; err != nil {
+			return err
+		}
+		result := tx.Where("id = ? AND user_id = ?", tagID, userID).Delete(&models.Tag{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrTagNotFound
+		}
+		return nil
+	})
+}
+
+// TagTransactions adds and removes tags by name on a set of the user's
+// transactions, creating tags that do not exist yet. Adding a tag a
+// transaction already has is a no-op. Every transaction is touched so its
+// version moves even when the tag set ends up unchanged.
+func (s *TagService) TagTransactions(tx *gorm.DB, userID uint, transactionIDs []uint, add, remove []string) error {
+	tags, err := EnsureTags(tx, userID, add)
+	if err != nil {
+		return err
+	}
+	if _, err := addTransactionTags(tx, transactionIDs, tags); err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(remove))
+	for _, name := range remove {
+		if name = NormalizeTagName(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	if len(names) > 0 {
+		if err := tx.Exec(
+			"DELETE FROM transaction_tags WHERE transaction_id IN ? AND tag_id IN (SELECT id FROM tags WHERE user_id = ? AND name IN ?)",
+			transactionIDs, userID, names,
+		).Error; err != nil {
+			return err
+		}
+	}
+
+	return tx.Model(&models.Transaction{}).
+		Where("id IN ? AND user_id = ?", transactionIDs, userID).
+		Update("updated_at", time.Now()).Error
+}
+
+// Report totals spending and income per tag between start and end; a zero
+// time leaves that end open. A transaction with several tags counts under
+// each of them. Tags without transactions in the range are left out.
+func (s *TagService) Report(userID uint, start, end time.Time) ([]TagReport, error) {
+	query := s.db.Model(&models.Tag{}).
+		Select("tags.id AS tag_id, tags.name AS tag, COUNT(transactions.id) AS transactions, "+
+			"COALESCE(SUM(CASE WHEN transactions.type = 'debit' THEN transactions.amount END), 0) AS spending, "+
+			"COALESCE(SUM(CASE WHEN transactions.type = 'credit' THEN transactions.amount END), 0) AS income").
+		Joins("JOIN transaction_tags ON transaction_tags.tag_id = tags.id").
+		Joins("JOIN transactions ON transactions.id = transaction_tags.transaction_id").
+		Where("tags.user_id = ?", userID).
+		Group("tags.id, tags.name").
+		Order("spending DESC, tags.name ASC")
+
+	if !start.IsZero() {
+		query = query.Where("transactions.transaction_date >= ?", start)
+	}
+	if !end.IsZero() {
+		query = query.Where("transactions.transaction_date <= ?", end)
+	}
+
+	reports := []TagReport{}
+	err := query.Scan(&reports).Error
+	return reports, err
+}
+
+// addTransactionTags puts every tag on every transaction, skipping pairs
+// that already exist, and returns how many pairs it added. It does not
+// touch the transactions themselves.
+func addTransactionTags(tx *gorm.DB, transactionIDs []uint, tags []models.Tag) (int64, error) {
+	if len(transactionIDs) == 0 || len(tags) == 0 {
+		return 0, nil
+	}
+	rows := make([]map[string]interface{}, 0, len(transactionIDs)*len(tags))
+	for _, transactionID := range transactionIDs {
+		for _, tag := range tags {
+			rows = append(rows, map[string]interface{}{"transaction_id": transactionID, "tag_id": tag.ID})
+		}
+	}
+	result := tx.Table("transaction_tags").
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&rows)
+	return result.RowsAffected, result.Error
+}
+
+// EnsureTags returns the user's tags with the given names, creating any that
+// do not exist yet.
+func EnsureTags(tx *gorm.DB, userID uint, names []string) ([]models.Tag, error) {
+	seen := make(map[string]bool)
+	tags := make([]models.Tag, 0, len(names))
+	for _, name := range names {
+		name = NormalizeTagName(name)
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		tag := models.Tag{UserID: userID, Name: name}
+		if err := tx.Where("user_id = ? AND name = ?", userID, name).
+			FirstOrCreate(&tag).Error; err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+	return tags, nil
+}
+
+// MergeTags appends the tags in added that existing does not have yet.
+func MergeTags(existing, added []models.Tag) []models.Tag {
+	seen := make(map[uint]bool, len(existing))
+	for _, tag := range existing {
+		seen[tag.ID] = true
+	}
+	for _, tag := range added {
+		if !seen[tag.ID] {
+			existing = append(existing, tag)
+			seen[tag.ID] = true
+		}
+	}
+	return existing
+}
@@ -0,0 +1,93 @@
+// internal/services/tag_service_test.go
+package services
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"finbro-backend-go/internal/db"
+	"finbro-backend-go/internal/db/dbtest"
+	"finbro-backend-go/internal/db/models"
+)
+
+func TestDeleteTagUntagsTransactions(t *testing.T) {
+	database := dbtest.Open(t)
+	svc := NewTagService(database)
+	user := dbtest.CreateUser(t, database)
+	account := dbtest.CreateAccount(t, database, user.ID)
+	transaction := dbtest.CreateTransaction(t, database, account, "Farmers market", 18)
+
+	if err := svc.TagTransactions(database.DB, user.ID, []uint{transaction.ID}, []string{"Groceries", "weekly"}, nil); err != nil {
+		t.Fatalf("TagTransactions: %v", err)
+	}
+	groceries := findTag(t, database, user.ID, "groceries")
+
+	other := dbtest.CreateUser(t, database)
+	if err := svc.DeleteTag(groceries.ID, other.ID); !errors.Is(err, ErrTagNotFound) {
+		t.Fatalf("DeleteTag as another user: got %v, want ErrTagNotFound", err)
+	}
+	if got := transactionTagNames(t, database, transaction.ID); !slices.Equal(got, []string{"groceries", "weekly"}) {
+		t.Fatalf("another user's delete changed the tags: %v", got)
+	}
+
+	if err := svc.DeleteTag(groceries.ID, user.ID); err != nil {
+		t.Fatalf("DeleteTag: %v", err)
+	}
+	if got := transactionTagNames(t, database, transaction.ID); !slices.Equal(got, []string{"weekly"}) {
+		t.Errorf("tags after DeleteTag: got %v, want [weekly]", got)
+	}
+	if _, err := svc.GetTagByID(groceries.ID, user.ID); !errors.Is(err, ErrTagNotFound) {
+		t.Errorf("GetTagByID after DeleteTag: got %v, want ErrTagNotFound", err)
+	}
+	if err := svc.DeleteTag(groceries.ID, user.ID); !errors.Is(err, ErrTagNotFound) {
+		t.Errorf("second DeleteTag: got %v, want ErrTagNotFound", err)
+	}
+}
+
+func TestDeleteTaggedTransaction(t *testing.T) {
+	database := dbtest.Open(t)
+	svc := NewTagService(database)
+	user := dbtest.CreateUser(t, database)
+	account := dbtest.CreateAccount(t, database, user.ID)
+	transaction := dbtest.CreateTransaction(t, database, account, "Hotel", 240)
+	kept := dbtest.CreateTransaction(t, database, account, "Flight", 380)
+
+	ids := []uint{transaction.ID, kept.ID}
+	if err := svc.TagTransactions(database.DB, user.ID, ids, []string{"vacation"}, nil); err != nil {
+		t.Fatalf("TagTransactions: %v", err)
+	}
+
+	if err := database.Delete(transaction).Error; err != nil {
+		t.Fatalf("deleting a tagged transaction: %v", err)
+	}
+	if got := transactionTagNames(t, database, transaction.ID); len(got) != 0 {
+		t.Errorf("deleted transaction still has tags %v", got)
+	}
+	if got := transactionTagNames(t, database, kept.ID); !slices.Equal(got, []string{"vacation"}) {
+		t.Errorf("other transaction's tags: got %v, want [vacation]", got)
+	}
+	findTag(t, database, user.ID, "vacation")
+}
+
+func findTag(t *testing.T, database *db.DB, userID uint, name string) models.Tag {
+	t.Helper()
+	var tag models.Tag
+	if err := database.Where("user_id = ? AND name = ?", userID, name).First(&tag).Error; err != nil {
+		t.Fatalf("tag %q: %v", name, err)
+	}
+	return tag
+}
+
+func transactionTagNames(t *testing.T, database *db.DB, transactionID uint) []string {
+	t.Helper()
+	var names []string
+	if err := database.Table("transaction_tags").
+		Joins("JOIN tags ON tags.id = transaction_tags.tag_id").
+		Where("transaction_tags.transaction_id = ?", transactionID).
+		Order("tags.name").
+		Pluck("tags.name", &names).Error; err != nil {
+		t.Fatal(err)
+	}
+	return names
+}
@@ -0,0 +1,79 @@
+// internal/services/thumbnail.go
+package services
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	_ "image/gif" // register decoders for image.Decode
+	"image/jpeg"
+	_ "image/png"
+)
+
+const (
+	thumbnailSize    = 256
+	thumbnailQuality = 80
+	// maxThumbnailPixels keeps a small file that declares huge dimensions
+	// from being decoded into gigabytes of memory.
+	maxThumbnailPixels = 40_000_000
+)
+
+// makeThumbnail scales a JPEG, PNG or GIF image to fit in a
+// thumbnailSize square, flattened onto white, and encodes it as JPEG. It
+// returns nil without an error for formats it cannot decode and for images
+// too large to decode safely; those attachments simply have no thumbnail.
+func makeThumbnail(data []byte) ([]byte, error) {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil || cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width*cfg.Height > maxThumbnailPixels {
+		return nil, nil
+	}
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	bounds := src.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	dstWidth, dstHeight := width, height
+	if width > thumbnailSize || height > thumbnailSize {
+		if width >= height {
+			dstWidth, dstHeight = thumbnailSize, max(1, height*thumbnailSize/width)
+		} else {
+			dstWidth, dstHeight = max(1, width*thumbnailSize/height), thumbnailSize
+		}
+	}
+
+	// Average every source pixel covered by each destination pixel, which
+	// keeps receipts legible better than sampling one pixel would.
+	dst := image.NewRGBA(image.Rect(0, 0, dstWidth, dstHeight))
+	for y := 0; y < dstHeight; y++ {
+		y0, y1 := bounds.Min.Y+y*height/dstHeight, bounds.Min.Y+(y+1)*height/dstHeight
+		for x := 0; x < dstWidth; x++ {
+			x0, x1 := bounds.Min.X+x*width/dstWidth, bounds.Min.X+(x+1)*width/dstWidth
+			var r, g, b, n uint64
+			for sy := y0; sy < max(y1, y0+1); sy++ {
+				for sx := x0; sx < max(x1, x0+1); sx++ {
+					pr, pg, pb, pa := src.At(sx, sy).RGBA()
+					// Colors are alpha-premultiplied, so adding the missing
+					// alpha composites the pixel over white.
+					r += uint64(pr + 0xffff - pa)
+					g += uint64(pg + 0xffff - pa)
+					b += uint64(pb + 0xffff - pa)
+					n++
+				}
+			}
+			dst.SetRGBA(x, y, color.RGBA{
+				R: uint8(r / n >> 8),
+				G: uint8(g / n >> 8),
+				B: uint8(b / n >> 8),
+				A: 0xff,
+			})
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, dst, &jpeg.Options{Quality: thumbnailQuality}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -2,9 +2,16 @@
 package services
 
 import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
 	"finbro-backend-go/internal/db"
 	"finbro-backend-go/internal/db/models"
-	"time"
+
+	"gorm.io/gorm"
 )
 
 type TransactionService struct {
@@ -15,18 +22,45 @@ func NewTransactionService(db *db.DB) *TransactionService {
 	return &TransactionService{db: db}
 }
 
+const (
+	SortByDate   = "transaction_date"
+	SortByAmount = "amount"
+)
+
+var ErrInvalidSort = errors.New("invalid sort field")
+
 type TransactionFilter struct {
-	UserID    uint
-	AccountID uint
-	Category  string
-	StartDate time.Time
-	EndDate   time.Time
-	Limit     int
-	Offset    int
+	UserID       uint
+	AccountID    uint
+	Category     string
+	Categories   []string
+	CategoryIDs  []uint
+	Tags         []string
+	TagIDs       []uint
+	Type         string
+	Search       string
+	StartDate    time.Time
+	EndDate      time.Time
+	MinAmount    *float64
+	MaxAmount    *float64
+	SortBy       string
+	Ascending    bool
+	Cursor       string
+	Limit        int
+	IncludeTotal bool
 }
 
-func (s *TransactionService) GetTransactions(filter TransactionFilter) ([]models.Transaction, error) {
-	query := s.db.Where("user_id = ?", filter.UserID)
+type TransactionPage struct {
+	Transactions []models.Transaction `json:"data"`
+	NextCursor   string               `json:"next_cursor,omitempty"`
+	PrevCursor   string               `json:"prev_cursor,omitempty"`
+	Total        *int64               `json:"total,omitempty"`
+}
+
+// applyFilter adds every WHERE clause of the filter except the cursor, so the
+// same scope can be reused for the page query and the total count.
+func (s *TransactionService) applyFilter(query *gorm.DB, filter TransactionFilter) *gorm.DB {
+	query = query.Where("user_id = ?", filter.UserID)
 
 	if filter.AccountID > 0 {
 		query = query.Where("account_id = ?", filter.AccountID)
@@ -34,20 +68,208 @@ func (s *TransactionService) GetTransactions(filter TransactionFilter) ([]models
 	if filter.Category != "" {
 		query = query.Where("category = ?", filter.Category)
 	}
+	if len(filter.Categories) > 0 {
+		query = query.Where("category IN ?", filter.Categories)
+	}
+	if len(filter.CategoryIDs) > 0 {
+		// Filtering by a parent category includes its children.
+		query = query.Where(
+			"(category_id IN ? OR category_id IN (SELECT id FROM categories WHERE parent_id IN ?))",
+			filter.CategoryIDs, filter.CategoryIDs,
+		)
+	}
+	if len(filter.Tags) > 0 {
+		// Tag filters match transactions carrying any of the tags.
+		query = query.Where(
+			"id IN (SELECT transaction_tags.transaction_id FROM transaction_tags "+
+				"JOIN tags ON tags.id = transaction_tags.tag_id WHERE tags.user_id = ? AND tags.name IN ?)",
+			filter.UserID, filter.Tags,
+		)
+	}
+	if len(filter.TagIDs) > 0 {
+		query = query.Where("id IN (SELECT transaction_id FROM transaction_tags WHERE tag_id IN ?)", filter.TagIDs)
+	}
+	if filter.Type != "" {
+		query = query.Where("type = ?", filter.Type)
+	}
+	if tsQuery := buildSearchQuery(filter.Search); tsQuery != "" {
+		query = query.Where("search_vector @@ to_tsquery(?::regconfig, ?)", searchConfig, tsQuery)
+	}
 	if !filter.StartDate.IsZero() {
 		query = query.Where("transaction_date >= ?", filter.StartDate)
 	}
 	if !filter.EndDate.IsZero() {
 		query = query.Where("transaction_date <= ?", filter.EndDate)
 	}
+	if filter.MinAmount != nil {
+		query = query.Where("amount >= ?", *filter.MinAmount)
+	}
+	if filter.MaxAmount != nil {
+		query = query.Where("amount <= ?", *filter.MaxAmount)
+	}
+
+	return query
+}
+
+// GetTransactions returns one page of transactions using keyset pagination
+// over (sort column, id). Cursors are opaque to clients and remember the
+// direction they were issued for.
+func (s *TransactionService) GetTransactions(filter TransactionFilter) (*TransactionPage, error) {
+	if filter.SortBy == "" {
+		filter.SortBy = SortByDate
+	}
+	if filter.SortBy != SortByDate && filter.SortBy != SortByAmount {
+		return nil, ErrInvalidSort
+	}
+	filter.Limit = ClampPageSize(filter.Limit)
+
+	var cursor *pageCursor
+	if filter.Cursor != "" {
+		cur, err := decodeCursor(filter.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		if cur.Sort != cursorSortKey(filter) {
+			return nil, ErrInvalidCursor
+		}
+		cursor = cur
+	}
+
+	backward := cursor != nil && cursor.Backward
+	// Walking backwards means reading in the opposite order and flipping the
+	// rows afterwards.
+	ascending := filter.Ascending != backward
+	direction := "DESC"
+	comparator := "<"
+	if ascending {
+		direction = "ASC"
+		comparator = ">"
+	}
+
+	query := s.applyFilter(s.db.Model(&models.Transaction{}), filter)
+	if cursor != nil {
+		value, err := parseCursorValue(filter.SortBy, cursor.Value)
+		if err != nil {
+			return nil, err
+		}
+		query = query.Where(
+			fmt.Sprintf("(%s, id) %s (?, ?)", filter.SortBy, comparator),
+			value, cursor.ID,
+		)
+	}
 
 	var transactions []models.Transaction
-	err := query.Preload("Account").
-		Order("transaction_date DESC").
-		Limit(filter.Limit).Offset(filter.Offset).
+	err := query.Preload("Account").Preload("Tags").Preload("Splits", orderSplits).
+		Order(fmt.Sprintf("%s %s, id %s", filter.SortBy, direction, direction)).
+		Limit(filter.Limit + 1).
 		Find(&transactions).Error
+	if err != nil {
+		return nil, err
+	}
+
+	hasMore := len(transactions) > filter.Limit
+	if hasMore {
+		transactions = transactions[:filter.Limit]
+	}
+	if backward {
+		for i, j := 0, len(transactions)-1; i < j; i, j = i+1, j-1 {
+			transactions[i], transactions[j] = transactions[j], transactions[i]
+		}
+	}
+
+	page := &TransactionPage{Transactions: transactions}
+	if len(transactions) > 0 {
+		first := transactions[0]
+		last := transactions[len(transactions)-1]
+
+		if (!backward && hasMore) || backward {
+			page.NextCursor = encodeCursor(newCursor(filter, last, false))
+		}
+		if (backward && hasMore) || (!backward && cursor != nil) {
+			page.PrevCursor = encodeCursor(newCursor(filter, first, true))
+		}
+	}
+
+	if filter.IncludeTotal {
+		var total int64
+		if err := s.applyFilter(s.db.Model(&models.Transaction{}), filter).Count(&total).Error; err != nil {
+			return nil, err
+		}
+		page.Total = &total
+	}
 
-	return transactions, err
+	return page, nil
+}
+
+// exportBatchSize is how many transactions ExportTransactions loads at once.
+const exportBatchSize = 500
+
+// ExportTransactions walks every transaction matching the filter in date
+// order, with tags and splits loaded, handing them to fn a batch at a time. Sort,
+// cursor and limit are ignored.
+func (s *TransactionService) ExportTransactions(filter TransactionFilter, fn func([]models.Transaction) error) error {
+	var last *models.Transaction
+	for {
+		query := s.applyFilter(s.db.Model(&models.Transaction{}), filter)
+		if last != nil {
+			query = query.Where("(transaction_date, id) > (?, ?)", last.TransactionDate, last.ID)
+		}
+
+		var batch []models.Transaction
+		err := query.Preload("Tags").Preload("Splits", orderSplits).
+			Order("transaction_date ASC, id ASC").
+			Limit(exportBatchSize).
+			Find(&batch).Error
+		if err != nil {
+			return err
+		}
+		if len(batch) > 0 {
+			if err := fn(batch); err != nil {
+				return err
+			}
+		}
+		if len(batch) < exportBatchSize {
+			return nil
+		}
+		last = &batch[len(batch)-1]
+	}
+}
+
+// cursorSortKey ties a cursor to the ordering it was issued for, so it
+// cannot be replayed against a different sort.
+func cursorSortKey(filter TransactionFilter) string {
+	if filter.Ascending {
+		return filter.SortBy + ":asc"
+	}
+	return filter.SortBy + ":desc"
+}
+
+func newCursor(filter TransactionFilter, t models.Transaction, backward bool) pageCursor {
+	cur := pageCursor{Sort: cursorSortKey(filter), ID: t.ID, Backward: backward}
+	switch filter.SortBy {
+	case SortByAmount:
+		cur.Value = strconv.FormatFloat(t.Amount, 'f', -1, 64)
+	default:
+		cur.Value = t.TransactionDate.UTC().Format(time.RFC3339Nano)
+	}
+	return cur
+}
+
+func parseCursorValue(sortBy, value string) (interface{}, error) {
+	switch sortBy {
+	case SortByAmount:
+		amount, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, ErrInvalidCursor
+		}
+		return amount, nil
+	default:
+		date, err := time.Parse(time.RFC3339Nano, value)
+		if err != nil {
+			return nil, ErrInvalidCursor
+		}
+		return date, nil
+	}
 }
 
 func (s *TransactionService) CreateTransaction(transaction *models.Transaction) error {
@@ -58,14 +280,7 @@ func (s *TransactionService) CreateTransaction(transaction *models.Transaction)
 		return err
 	}
 
-	// Update account balance
-	balanceChange := transaction.Amount
-	if transaction.Type == "debit" {
-		balanceChange = -balanceChange
-	}
-
-	if err := tx.Model(&models.Account{}).Where("id = ?", transaction.AccountID).
-		Update("balance", models.Account{}.Balance+balanceChange).Error; err != nil {
+	if err := AdjustBalance(tx, transaction.AccountID, BalanceEffect(transaction.Amount, transaction.Type)); err != nil {
 		tx.Rollback()
 		return err
 	}
@@ -73,6 +288,63 @@ func (s *TransactionService) CreateTransaction(transaction *models.Transaction)
 	return tx.Commit().Error
 }
 
+// BalanceEffect is how much a transaction moves its account's balance:
+// debits take the amount out, credits add it.
+func BalanceEffect(amount float64, transactionType string) float64 {
+	if transactionType == "debit" {
+		return -amount
+	}
+	return amount
+}
+
+// AdjustBalance adds delta to an account's balance in a single statement, so
+// concurrent writers cannot overwrite each other's changes.
+func AdjustBalance(tx *gorm.DB, accountID uint, delta float64) error {
+	if delta == 0 {
+		return nil
+	}
+	return tx.Model(&models.Account{}).Where("id = ?", accountID).
+		Update("balance", gorm.Expr("balance + ?", delta)).Error
+}
+
+// BalanceChanges accumulates balance effects per account so a batch of
+// writes can update each affected account once.
+type BalanceChanges map[uint]float64
+
+// Remove takes a transaction's effect off its account.
+func (b BalanceChanges) Remove(t *models.Transaction) {
+	b[t.AccountID] -= BalanceEffect(t.Amount, t.Type)
+}
+
+// Add applies a transaction's effect to its account.
+func (b BalanceChanges) Add(t *models.Transaction) {
+	b[t.AccountID] += BalanceEffect(t.Amount, t.Type)
+}
+
+// Merge folds other into b.
+func (b BalanceChanges) Merge(other BalanceChanges) {
+	for accountID, delta := range other {
+		b[accountID] += delta
+	}
+}
+
+// Apply adjusts every account in ID order, so concurrent batches lock
+// accounts in the same order and cannot deadlock each other.
+func (b BalanceChanges) Apply(tx *gorm.DB) error {
+	accountIDs := make([]uint, 0, len(b))
+	for accountID := range b {
+		accountIDs = append(accountIDs, accountID)
+	}
+	sort.Slice(accountIDs, func(i, j int) bool { return accountIDs[i] < accountIDs[j] })
+
+	for _, accountID := range accountIDs {
+		if err := AdjustBalance(tx, accountID, b[accountID]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (s *TransactionService) GetTransactionByID(transactionID, userID uint) (*models.Transaction, error) {
 	var transaction models.Transaction
 	err := s.db.Preload("Account").
@@ -90,6 +362,22 @@ func (s *TransactionService) DeleteTransaction(transactionID, userID uint) error
 		Delete(&models.Transaction{}).Error
 }
 
+// orderSplits keeps splits in the order they were entered.
+func orderSplits(db *gorm.DB) *gorm.DB {
+	return db.Order("transaction_splits.id")
+}
+
+// Reports count a split transaction once per split. Joining splitLinesJoin
+// yields one row per split, or a single row with NULL split columns for a
+// transaction without splits, and the line expressions pick the amount and
+// category that row should be reported under.
+const (
+	splitLinesJoin   = "LEFT JOIN transaction_splits ON transaction_splits.transaction_id = transactions.id"
+	lineAmount       = "COALESCE(transaction_splits.amount, transactions.amount)"
+	lineCategoryID   = "CASE WHEN transaction_splits.id IS NULL THEN transactions.category_id ELSE transaction_splits.category_id END"
+	lineCategoryName = "CASE WHEN transaction_splits.id IS NULL THEN transactions.category ELSE transaction_splits.category END"
+)
+
 func (s *TransactionService) GetCategoryStats(userID uint, startDate, endDate time.Time) (map[string]float64, error) {
 	var results []struct {
 		Category string
@@ -97,15 +385,17 @@ func (s *TransactionService) GetCategoryStats(userID uint, startDate, endDate ti
 	}
 
 	query := s.db.Model(&models.Transaction{}).
-		Select("category, SUM(amount) as total").
-		Where("user_id = ?", userID).
-		Group("category")
+		Select("COALESCE(categories.name, 'Uncategorized') AS category, SUM("+lineAmount+") AS total").
+		Joins(splitLinesJoin).
+		Joins("LEFT JOIN categories ON categories.id = "+lineCategoryID).
+		Where("transactions.user_id = ?", userID).
+		Group("COALESCE(categories.name, 'Uncategorized')")
 
 	if !startDate.IsZero() {
-		query = query.Where("transaction_date >= ?", startDate)
+		query = query.Where("transactions.transaction_date >= ?", startDate)
 	}
 	if !endDate.IsZero() {
-		query = query.Where("transaction_date <= ?", endDate)
+		query = query.Where("transactions.transaction_date <= ?", endDate)
 	}
 
 	err := query.Find(&results).Error
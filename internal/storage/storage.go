@@ -0,0 +1,47 @@
+// internal/storage/storage.go
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"finbro-backend-go/internal/config"
+)
+
+// ErrNotFound is returned by Get when no blob is stored under the key.
+var ErrNotFound = errors.New("blob not found")
+
+// BlobStore keeps opaque blobs under slash-separated keys such as
+// "attachments/42/01J9Z...". Keys are chosen by the caller and never taken
+// from user input.
+type BlobStore interface {
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+	// Get returns the blob's contents; the caller closes the reader.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes a blob. Deleting a key that does not exist is not an
+	// error, so deletes can be retried.
+	Delete(ctx context.Context, key string) error
+}
+
+// New builds the blob store selected in config.
+func New(cfg *config.Config) (BlobStore, error) {
+	switch cfg.Attachments.Store {
+	case "local":
+		return NewLocalStore(cfg.Attachments.LocalDir)
+	case "s3":
+		s3 := cfg.Attachments.S3
+		return NewS3Store(S3Config{
+			Endpoint:        s3.Endpoint,
+			Region:          s3.Region,
+			Bucket:          s3.Bucket,
+			AccessKeyID:     s3.AccessKeyID,
+			SecretAccessKey: s3.SecretAccessKey,
+			PathStyle:       s3.PathStyle,
+		}, 30*time.Second)
+	default:
+		return nil, fmt.Errorf("unknown attachment store %q", cfg.Attachments.Store)
+	}
+}
@@ -0,0 +1,81 @@
+// internal/utils/merchant.go
+package utils
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	// Processor prefixes that card networks prepend to the merchant name.
+	merchantPrefixRegex = regexp.MustCompile(`^(pos|sq|tst|sp|pp|paypal|ach|debit|purchase|checkcard)\b\s*\*?\s*`)
+	merchantNoiseRegex  = regexp.MustCompile(`#\s*\S*|\b\d[\d-]*\b|\s+(inc|llc|ltd|co|corp)\b`)
+	nonWordRegex        = regexp.MustCompile(`[^a-z0-9&' ]+`)
+
+	// merchantAliases maps abbreviations seen on statements to a canonical
+	// merchant name. Keys are matched against the start of the cleaned name.
+	merchantAliases = []struct {
+		prefix string
+		name   string
+	}{
+		{"amzn", "amazon"},
+		{"amazon mktp", "amazon"},
+		{"amazon com", "amazon"},
+		{"amazon prime", "amazon prime"},
+		{"wal mart", "walmart"},
+		{"wm supercenter", "walmart"},
+		{"sbux", "starbucks"},
+		{"uber trip", "uber"},
+		{"uber eats", "uber eats"},
+		{"lyft ride", "lyft"},
+		{"netflix com", "netflix"},
+		{"spotify usa", "spotify"},
+		{"apple com bill", "apple"},
+		{"google", "google"},
+		{"mcdonald's", "mcdonalds"},
+	}
+)
+
+// NormalizeMerchant derives a canonical merchant name from a raw statement
+// description, e.g. "AMZN Mktp US*2K3LT" becomes "Amazon".
+func NormalizeMerchant(description string) string {
+	name := strings.ToLower(strings.TrimSpace(description))
+	if name == "" {
+		return ""
+	}
+
+	name = merchantPrefixRegex.ReplaceAllString(name, "")
+	name = strings.NewReplacer(".", " ", "*", " ", "-", " ").Replace(name)
+	name = merchantNoiseRegex.ReplaceAllString(name, " ")
+	name = nonWordRegex.ReplaceAllString(name, " ")
+	name = strings.Join(strings.Fields(name), " ")
+
+	for _, alias := range merchantAliases {
+		if strings.HasPrefix(name, alias.prefix) {
+			name = alias.name
+			break
+		}
+	}
+
+	return titleCase(name)
+}
+
+// NormalizeSearchTerm maps a single search term through the merchant alias
+// table so that searching "amzn" also finds "Amazon".
+func NormalizeSearchTerm(term string) string {
+	term = strings.ToLower(term)
+	for _, alias := range merchantAliases {
+		if term == alias.prefix && !strings.Contains(alias.name, " ") {
+			return alias.name
+		}
+	}
+	return term
+}
+
+func titleCase(input string) string {
+	words := strings.Fields(input)
+	for i, word := range words {
+		words[i] = strings.ToUpper(word[:1]) + word[1:]
+	}
+	return strings.Join(words, " ")
+}
@@ -8,6 +8,8 @@ import (
 
 var (
 	emailRegex      = regexp.MustCompile(`^[^\s@]+@[^\s@]+\.[^\s@]+$`)
+	slugRegex       = regexp.MustCompile(`[^\p{L}\p{M}\p{N}]+`)
+	colorRegex      = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
 	validCurrencies = map[string]bool{
 		"USD": true, "EUR": true, "GBP": true, "INR": true,
 		"CAD": true, "AUD": true, "JPY": true, "CNY": true,
@@ -60,3 +62,17 @@ func IsValidName(name string) bool {
 	name = strings.TrimSpace(name)
 	return len(name) >= 1 && len(name) <= 50
 }
+
+// Color validation (hex colors such as #4CAF50)
+func IsValidColor(color string) bool {
+	return colorRegex.MatchString(color)
+}
+
+// Slugify lowercases a display name and joins its words with dashes, e.g.
+// "Food & Dining" becomes "food-and-dining". Letters outside ASCII are kept,
+// so "Café" becomes "café" and "食品" stays "食品".
+func Slugify(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	name = strings.ReplaceAll(name, "&", " and ")
+	return strings.Trim(slugRegex.ReplaceAllString(name, "-"), "-")
+}
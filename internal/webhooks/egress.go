@@ -0,0 +1,72 @@
+// internal/webhooks/egress.go
+package webhooks
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+)
+
+// ErrForbiddenAddress is returned when an outbound webhook would connect to
+// an address inside our own network.
+var ErrForbiddenAddress = errors.New("webhook endpoint resolves to a non-public address")
+
+// nonPublicPrefixes are ranges that the netip predicates do not cover:
+// "this network" and carrier-grade NAT, both reachable inside cloud VPCs.
+var nonPublicPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+}
+
+// IsPublicAddr reports whether addr is an address subscriber endpoints may
+// live at: not loopback, private, link-local (which includes the cloud
+// metadata service at 169.254.169.254), multicast or unspecified.
+func IsPublicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsValid() || addr.IsLoopback() || addr.IsPrivate() || addr.IsUnspecified() ||
+		addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() || addr.IsInterfaceLocalMulticast() ||
+		addr.IsMulticast() {
+		return false
+	}
+	for _, prefix := range nonPublicPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// publicOnlyControl runs after DNS resolution for every connection attempt,
+// so a hostname that resolves, or later rebinds, to an internal address is
+// refused before a byte is sent.
+func publicOnlyControl(network, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrForbiddenAddress, address)
+	}
+	if !IsPublicAddr(addrPort.Addr()) {
+		return fmt.Errorf("%w: %s", ErrForbiddenAddress, addrPort.Addr())
+	}
+	return nil
+}
+
+// NewEgressTransport returns the transport for calls to customer endpoints.
+// It only dials public addresses and ignores proxy settings, which would
+// otherwise bypass that check.
+func NewEgressTransport(timeout time.Duration) *http.Transport {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: publicOnlyControl,
+	}
+	return &http.Transport{
+		DialContext:         dialer.DialContext,
+		ForceAttemptHTTP2:   true,
+		MaxIdleConns:        100,
+		IdleConnTimeout:     90 * time.Second,
+		TLSHandshakeTimeout: timeout,
+	}
+}
@@ -0,0 +1,49 @@
+// internal/webhooks/egress_test.go
+package webhooks
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+)
+
+func TestIsPublicAddr(t *testing.T) {
+	tests := map[string]bool{
+		"93.184.216.34":          true,
+		"2606:4700:4700::1111":   true,
+		"127.0.0.1":              false,
+		"::1":                    false,
+		"10.1.2.3":               false,
+		"172.16.0.1":             false,
+		"192.168.1.10":           false,
+		"169.254.169.254":        false,
+		"fe80::1":                false,
+		"fd00:ec2::254":          false,
+		"0.0.0.0":                false,
+		"100.64.0.1":             false,
+		"224.0.0.1":              false,
+		"::ffff:127.0.0.1":       false,
+		"::ffff:169.254.169.254": false,
+	}
+	for input, want := range tests {
+		if got := IsPublicAddr(netip.MustParseAddr(input)); got != want {
+			t.Errorf("IsPublicAddr(%s) = %v, want %v", input, got, want)
+		}
+	}
+}
+
+func TestEgressTransportRefusesLoopback(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("request reached the loopback server")
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: NewEgressTransport(time.Second)}
+	_, err := client.Post(server.URL, "application/json", nil)
+	if !errors.Is(err, ErrForbiddenAddress) {
+		t.Fatalf("got %v, want ErrForbiddenAddress", err)
+	}
+}
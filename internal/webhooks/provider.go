@@ -0,0 +1,143 @@
+// internal/webhooks/provider.go
+package webhooks
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Normalized event types that inbound handlers dispatch on. Providers map
+// their own codes onto these.
+const (
+	EventTransactionsAvailable = "transactions.available"
+	EventLoginRequired         = "item.login_required"
+	EventLoginRepaired         = "item.login_repaired"
+	EventBalanceUpdated        = "balance.updated"
+)
+
+var ErrUnknownProvider = errors.New("unknown webhook provider")
+
+// Event is the provider independent part of an inbound webhook.
+type Event struct {
+	ID     string
+	Type   string
+	ItemID string
+}
+
+// Provider knows how to authenticate and interpret one sender's webhooks.
+type Provider struct {
+	Name     string
+	Verifier Verifier
+	Parse    func(header http.Header, body []byte) (*Event, error)
+}
+
+type Registry struct {
+	providers map[string]*Provider
+}
+
+func NewRegistry() *Registry {
+	return &Registry{providers: make(map[string]*Provider)}
+}
+
+func (r *Registry) Register(provider *Provider) {
+	r.providers[provider.Name] = provider
+}
+
+func (r *Registry) Get(name string) (*Provider, error) {
+	provider, ok := r.providers[name]
+	if !ok {
+		return nil, ErrUnknownProvider
+	}
+	return provider, nil
+}
+
+const plaidVerificationHeader = "Plaid-Verification"
+
+// NewPlaidProvider verifies Plaid's signed JWT and maps webhook_type and
+// webhook_code to normalized event types. Plaid does not send an event ID,
+// and every SYNC_UPDATES_AVAILABLE for an item has the same body, so the
+// hash of the verification JWT, which Plaid signs afresh for each
+// notification, is used for deduplication instead. A request replayed with
+// the same JWT is still caught; one Plaid re-signs only costs an extra sync,
+// which resumes from the stored cursor.
+func NewPlaidProvider(keys KeyFetcher) *Provider {
+	return &Provider{
+		Name:     "plaid",
+		Verifier: NewJWTVerifier(keys, plaidVerificationHeader),
+		Parse:    parsePlaidEvent,
+	}
+}
+
+func parsePlaidEvent(header http.Header, body []byte) (*Event, error) {
+	var payload struct {
+		WebhookType string `json:"webhook_type"`
+		WebhookCode string `json:"webhook_code"`
+		ItemID      string `json:"item_id"`
+		Error       *struct {
+			ErrorCode string `json:"error_code"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, fmt.Errorf("invalid payload: %w", err)
+	}
+
+	sum := sha256.Sum256([]byte(header.Get(plaidVerificationHeader)))
+	event := &Event{
+		ID:     hex.EncodeToString(sum[:]),
+		Type:   payload.WebhookType + "." + payload.WebhookCode,
+		ItemID: payload.ItemID,
+	}
+
+	switch {
+	case payload.WebhookType == "TRANSACTIONS":
+		event.Type = EventTransactionsAvailable
+	case payload.WebhookType == "ITEM" && payload.WebhookCode == "ERROR" &&
+		payload.Error != nil && payload.Error.ErrorCode == "ITEM_LOGIN_REQUIRED":
+		event.Type = EventLoginRequired
+	case payload.WebhookType == "ITEM" && payload.WebhookCode == "PENDING_EXPIRATION":
+		event.Type = EventLoginRequired
+	case payload.WebhookType == "ITEM" && payload.WebhookCode == "LOGIN_REPAIRED":
+		event.Type = EventLoginRepaired
+	case payload.WebhookType == "HOLDINGS" || payload.WebhookCode == "BALANCE_UPDATE":
+		event.Type = EventBalanceUpdated
+	}
+	return event, nil
+}
+
+// NewHMACProvider accepts webhooks from senders that sign the body with a
+// shared secret and send normalized payloads of the form
+// {"id": "...", "type": "transactions.available", "item_id": "..."}.
+func NewHMACProvider(name, secret string) *Provider {
+	return &Provider{
+		Name:     name,
+		Verifier: NewHMACVerifier(secret, "X-Webhook-Signature"),
+		Parse:    parseGenericEvent,
+	}
+}
+
+func parseGenericEvent(header http.Header, body []byte) (*Event, error) {
+	var payload struct {
+		ID     string `json:"id"`
+		Type   string `json:"type"`
+		ItemID string `json:"item_id"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, fmt.Errorf("invalid payload: %w", err)
+	}
+
+	event := &Event{ID: payload.ID, Type: payload.Type, ItemID: payload.ItemID}
+	if event.ID == "" {
+		event.ID = header.Get("X-Event-ID")
+	}
+	if event.ID == "" {
+		return nil, errors.New("event id is required")
+	}
+	if event.Type == "" {
+		return nil, errors.New("event type is required")
+	}
+	return event, nil
+}
@@ -0,0 +1,77 @@
+// internal/webhooks/provider_test.go
+package webhooks
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+type staticKeys struct {
+	key *ecdsa.PublicKey
+}
+
+func (k staticKeys) GetWebhookVerificationKey(ctx context.Context, keyID string) (*ecdsa.PublicKey, error) {
+	return k.key, nil
+}
+
+func signPlaidJWT(t *testing.T, key *ecdsa.PrivateKey, body []byte, issuedAt time.Time) string {
+	t.Helper()
+	sum := sha256.Sum256(body)
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+		"iat":                 issuedAt.Unix(),
+		"request_body_sha256": hex.EncodeToString(sum[:]),
+	})
+	token.Header["kid"] = "test-key"
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+// Plaid sends the same body for every update on an item; each notification
+// must still be a distinct event, while a retry of one request is not.
+func TestPlaidEventIDsFollowTheVerificationToken(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	provider := NewPlaidProvider(staticKeys{&key.PublicKey})
+	body := []byte(`{"webhook_type":"TRANSACTIONS","webhook_code":"SYNC_UPDATES_AVAILABLE","item_id":"item-1"}`)
+
+	receive := func(token string) *Event {
+		t.Helper()
+		header := http.Header{}
+		header.Set("Plaid-Verification", token)
+		if err := provider.Verifier.Verify(context.Background(), header, body); err != nil {
+			t.Fatalf("Verify: %v", err)
+		}
+		event, err := provider.Parse(header, body)
+		if err != nil {
+			t.Fatalf("Parse: %v", err)
+		}
+		if event.Type != EventTransactionsAvailable || event.ItemID != "item-1" {
+			t.Fatalf("Parse: got %+v", event)
+		}
+		return event
+	}
+
+	firstToken := signPlaidJWT(t, key, body, time.Now().Add(-time.Minute))
+	first := receive(firstToken)
+	second := receive(signPlaidJWT(t, key, body, time.Now()))
+	if first.ID == second.ID {
+		t.Error("two notifications with the same body got the same event ID")
+	}
+	if retry := receive(firstToken); retry.ID != first.ID {
+		t.Error("a retried notification got a new event ID")
+	}
+}
@@ -0,0 +1,136 @@
+// internal/webhooks/verifier.go
+package webhooks
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+var ErrInvalidSignature = errors.New("invalid webhook signature")
+
+// Verifier checks that a webhook request was sent by the provider.
+type Verifier interface {
+	Verify(ctx context.Context, header http.Header, body []byte) error
+}
+
+// HMACVerifier expects the hex HMAC-SHA256 of the raw body in a header,
+// optionally prefixed with "sha256=".
+type HMACVerifier struct {
+	secret []byte
+	header string
+}
+
+func NewHMACVerifier(secret, header string) *HMACVerifier {
+	if header == "" {
+		header = "X-Webhook-Signature"
+	}
+	return &HMACVerifier{secret: []byte(secret), header: header}
+}
+
+func (v *HMACVerifier) Verify(ctx context.Context, header http.Header, body []byte) error {
+	signature := strings.TrimPrefix(header.Get(v.header), "sha256=")
+	given, err := hex.DecodeString(signature)
+	if err != nil || len(given) == 0 {
+		return ErrInvalidSignature
+	}
+
+	mac := hmac.New(sha256.New, v.secret)
+	mac.Write(body)
+	if !hmac.Equal(given, mac.Sum(nil)) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// KeyFetcher returns the public key a provider signs webhooks with.
+type KeyFetcher interface {
+	GetWebhookVerificationKey(ctx context.Context, keyID string) (*ecdsa.PublicKey, error)
+}
+
+// JWTVerifier implements Plaid style verification: the Plaid-Verification
+// header carries an ES256 JWT whose request_body_sha256 claim must match the
+// body and whose iat must be recent. Keys are cached by key ID.
+type JWTVerifier struct {
+	keys     KeyFetcher
+	header   string
+	maxAge   time.Duration
+	mu       sync.Mutex
+	keyCache map[string]*ecdsa.PublicKey
+}
+
+func NewJWTVerifier(keys KeyFetcher, header string) *JWTVerifier {
+	if header == "" {
+		header = "Plaid-Verification"
+	}
+	return &JWTVerifier{
+		keys:     keys,
+		header:   header,
+		maxAge:   5 * time.Minute,
+		keyCache: make(map[string]*ecdsa.PublicKey),
+	}
+}
+
+func (v *JWTVerifier) Verify(ctx context.Context, header http.Header, body []byte) error {
+	tokenString := header.Get(v.header)
+	if tokenString == "" {
+		return ErrInvalidSignature
+	}
+
+	claims := jwt.MapClaims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		if token.Method != jwt.SigningMethodES256 {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		keyID, _ := token.Header["kid"].(string)
+		if keyID == "" {
+			return nil, errors.New("missing kid")
+		}
+		return v.key(ctx, keyID)
+	})
+	if err != nil || !token.Valid {
+		return ErrInvalidSignature
+	}
+
+	issuedAt, ok := claims["iat"].(float64)
+	if !ok || time.Since(time.Unix(int64(issuedAt), 0)) > v.maxAge {
+		return ErrInvalidSignature
+	}
+
+	expected, _ := claims["request_body_sha256"].(string)
+	sum := sha256.Sum256(body)
+	if subtle.ConstantTimeCompare([]byte(expected), []byte(hex.EncodeToString(sum[:]))) != 1 {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+func (v *JWTVerifier) key(ctx context.Context, keyID string) (*ecdsa.PublicKey, error) {
+	v.mu.Lock()
+	key, ok := v.keyCache[keyID]
+	v.mu.Unlock()
+	if ok {
+		return key, nil
+	}
+
+	key, err := v.keys.GetWebhookVerificationKey(ctx, keyID)
+	if err != nil {
+		return nil, err
+	}
+
+	v.mu.Lock()
+	v.keyCache[keyID] = key
+	v.mu.Unlock()
+	return key, nil
+}